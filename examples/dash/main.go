@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	ffmpego "m4urici0gm/ffmpego/pkg"
+)
+
+func main() {
+	// Package two video renditions and one audio track as DASH with HLS playlists (CMAF)
+
+	options := ffmpego.NewFfmpegOptions(
+		ffmpego.WithInput("in.mp4"),
+		ffmpego.WithOverwrite())
+
+	filterGraph := ffmpego.NewComplexFilterBuilder().
+		Add(ffmpego.WithSplit("0:v", 2, "s720", "s480")).
+		Add(ffmpego.WithScale("s720", "v720", 1280, 720)).
+		Add(ffmpego.WithScale("s480", "v480", 854, 480)).
+		Build()
+
+	dash := ffmpego.NewDASHOutput("dash/manifest.mpd").
+		Map("[v720]", "[v480]", "0:a").
+		WithFlag(ffmpego.VideoCodecH264).
+		WithFlag(ffmpego.AudioCodecAAC).
+		WithFlag(ffmpego.CRFGoodQuality).
+		AdaptationSet(0, "v").
+		AdaptationSet(1, "a").
+		SegmentDuration(4 * time.Second).
+		HLSPlaylist(true).
+		Build()
+
+	cmd := ffmpego.New("").
+		WithOptions(options).
+		WithFilterGraph(filterGraph).
+		Output(dash)
+
+	ctx := context.Background()
+	if err := ffmpego.NewRunner(cmd).Run(ctx); err != nil {
+		log.Fatalf("error when trying to run ffmpeg. %v", err)
+	}
+
+	mpd, err := ffmpego.ReadMPD("dash/manifest.mpd")
+	if err != nil {
+		log.Fatalf("error when reading manifest. %v", err)
+	}
+
+	err = mpd.Verify(
+		ffmpego.ExpectedRepresentation{ContentType: "video", Width: 1280, Height: 720},
+		ffmpego.ExpectedRepresentation{ContentType: "video", Width: 854, Height: 480},
+		ffmpego.ExpectedRepresentation{ContentType: "audio"},
+	)
+	if err != nil {
+		log.Fatalf("manifest verification failed. %v", err)
+	}
+}
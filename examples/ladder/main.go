@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	ffmpego "m4urici0gm/ffmpego/pkg"
+)
+
+func main() {
+	// Generate an ABR ladder from the probed source instead of hand-building split/scale outputs
+
+	ctx := context.Background()
+	probe, err := ffmpego.Probe(ctx, "in.mp4")
+	if err != nil {
+		log.Fatalf("error when trying to probe input. %v", err)
+	}
+
+	policy := ffmpego.DefaultLadderPolicy()
+	policy.Overwrite = true
+
+	cmd, rungs, err := ffmpego.GenerateLadder(probe, policy)
+	if err != nil {
+		log.Fatalf("error when generating ladder. %v", err)
+	}
+
+	for _, rung := range rungs {
+		log.Printf("%s: %dx%d @ %s fps, %d bps -> %s", rung.Label, rung.Width, rung.Height, rung.FrameRate, rung.Bitrate, rung.File)
+	}
+
+	if err := ffmpego.NewRunner(cmd).Run(ctx); err != nil {
+		log.Fatalf("error when trying to run ffmpeg. %v", err)
+	}
+}
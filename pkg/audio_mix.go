@@ -0,0 +1,502 @@
+package ffmpego
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ChannelLayouts maps ffmpeg's named channel layouts (ffmpeg -layouts) to their channels.
+var ChannelLayouts = map[string][]string{
+	"mono":           {"FC"},
+	"stereo":         {"FL", "FR"},
+	"2.1":            {"FL", "FR", "LFE"},
+	"3.0":            {"FL", "FR", "FC"},
+	"3.0(back)":      {"FL", "FR", "BC"},
+	"4.0":            {"FL", "FR", "FC", "BC"},
+	"quad":           {"FL", "FR", "BL", "BR"},
+	"quad(side)":     {"FL", "FR", "SL", "SR"},
+	"3.1":            {"FL", "FR", "FC", "LFE"},
+	"5.0":            {"FL", "FR", "FC", "BL", "BR"},
+	"5.0(side)":      {"FL", "FR", "FC", "SL", "SR"},
+	"4.1":            {"FL", "FR", "FC", "LFE", "BC"},
+	"5.1":            {"FL", "FR", "FC", "LFE", "BL", "BR"},
+	"5.1(side)":      {"FL", "FR", "FC", "LFE", "SL", "SR"},
+	"6.0":            {"FL", "FR", "FC", "BC", "SL", "SR"},
+	"6.0(front)":     {"FL", "FR", "FLC", "FRC", "SL", "SR"},
+	"hexagonal":      {"FL", "FR", "FC", "BL", "BR", "BC"},
+	"6.1":            {"FL", "FR", "FC", "LFE", "BC", "SL", "SR"},
+	"6.1(back)":      {"FL", "FR", "FC", "LFE", "BL", "BR", "BC"},
+	"6.1(front)":     {"FL", "FR", "LFE", "FLC", "FRC", "SL", "SR"},
+	"7.0":            {"FL", "FR", "FC", "BL", "BR", "SL", "SR"},
+	"7.0(front)":     {"FL", "FR", "FC", "FLC", "FRC", "SL", "SR"},
+	"7.1":            {"FL", "FR", "FC", "LFE", "BL", "BR", "SL", "SR"},
+	"7.1(wide)":      {"FL", "FR", "FC", "LFE", "BL", "BR", "FLC", "FRC"},
+	"7.1(wide-side)": {"FL", "FR", "FC", "LFE", "FLC", "FRC", "SL", "SR"},
+	"octagonal":      {"FL", "FR", "FC", "BL", "BR", "BC", "SL", "SR"},
+	"downmix":        {"DL", "DR"},
+}
+
+// channelNames are the channel names accepted in custom layouts such as "FL+FR+LFE".
+var channelNames = map[string]bool{
+	"FL": true, "FR": true, "FC": true, "LFE": true, "BL": true, "BR": true, "FLC": true, "FRC": true,
+	"BC": true, "SL": true, "SR": true, "TC": true, "TFL": true, "TFC": true, "TFR": true, "TBL": true,
+	"TBC": true, "TBR": true, "DL": true, "DR": true, "WL": true, "WR": true, "SDL": true, "SDR": true,
+	"LFE2": true,
+}
+
+// ParseChannelLayout returns the channels of a layout: a named layout ("stereo", "5.1(side)"),
+// a "+" separated list of channel names ("FL+FR+LFE") or an unnamed channel count ("6c"),
+// whose channels are returned as "c0".."c5".
+func ParseChannelLayout(layout string) ([]string, error) {
+	if channels, ok := ChannelLayouts[layout]; ok {
+		return channels, nil
+	}
+	if count, ok := strings.CutSuffix(layout, "c"); ok {
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 1 || n > 64 {
+			return nil, fmt.Errorf("invalid channel count layout %q", layout)
+		}
+		channels := make([]string, n)
+		for i := range channels {
+			channels[i] = fmt.Sprintf("c%d", i)
+		}
+		return channels, nil
+	}
+
+	channels := strings.Split(layout, "+")
+	seen := make(map[string]bool, len(channels))
+	for _, ch := range channels {
+		if !channelNames[ch] {
+			return nil, fmt.Errorf("unknown channel layout %q", layout)
+		}
+		if seen[ch] {
+			return nil, fmt.Errorf("channel layout %q repeats %s", layout, ch)
+		}
+		seen[ch] = true
+	}
+	return channels, nil
+}
+
+// isLayoutChannel reports whether channel is a name in channels or a "cN" index into them.
+func isLayoutChannel(channel string, channels []string) bool {
+	if idx, ok := strings.CutPrefix(channel, "c"); ok {
+		if n, err := strconv.Atoi(idx); err == nil {
+			return n >= 0 && n < len(channels)
+		}
+	}
+	for _, ch := range channels {
+		if ch == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// isInputChannel reports whether channel names an input channel of pan: a known name or "cN".
+func isInputChannel(channel string) bool {
+	if idx, ok := strings.CutPrefix(channel, "c"); ok {
+		if n, err := strconv.Atoi(idx); err == nil {
+			return n >= 0 && n < 64
+		}
+	}
+	return channelNames[channel]
+}
+
+// checkLabels validates that no label of a filter is empty.
+func checkLabels(filter string, labels ...string) error {
+	for _, label := range labels {
+		if strings.TrimSpace(label) == "" {
+			return fmt.Errorf("%s: labels cannot be empty", filter)
+		}
+	}
+	return nil
+}
+
+// trimLabels trims the whitespace around every label.
+func trimLabels(labels []string) []string {
+	out := make([]string, len(labels))
+	for i, l := range labels {
+		out[i] = strings.TrimSpace(l)
+	}
+	return out
+}
+
+// PanTerm is one input channel of a pan expression, scaled by Gain.
+type PanTerm struct {
+	Gain    float64
+	Channel string // input channel name ("FC") or index ("c2")
+}
+
+// PanChannel defines one output channel as a sum of input channels.
+// Renormalize scales the gains so they add up to 1 ("FL<..." instead of "FL=..."), avoiding clipping.
+type PanChannel struct {
+	Channel     string
+	Terms       []PanTerm
+	Renormalize bool
+}
+
+func (c PanChannel) String() string {
+	var b strings.Builder
+	b.WriteString(c.Channel)
+	if c.Renormalize {
+		b.WriteString("<")
+	} else {
+		b.WriteString("=")
+	}
+	for i, term := range c.Terms {
+		gain := term.Gain
+		if i > 0 {
+			if gain < 0 {
+				b.WriteString("-")
+				gain = -gain
+			} else {
+				b.WriteString("+")
+			}
+		}
+		if gain != 1 {
+			b.WriteString(formatFloat(gain) + "*")
+		}
+		b.WriteString(term.Channel)
+	}
+	return b.String()
+}
+
+// PanFilter renders: "[input]pan=stereo|FL=FL+0.707*FC|FR=FR+0.707*FC[output]"
+type PanFilter struct {
+	Input    string
+	Output   string
+	Layout   string
+	Channels []PanChannel
+}
+
+func (f PanFilter) Validate() error {
+	if err := checkLabels("pan", f.Input, f.Output); err != nil {
+		return err
+	}
+	layout, err := ParseChannelLayout(f.Layout)
+	if err != nil {
+		return fmt.Errorf("pan: %w", err)
+	}
+	if len(f.Channels) == 0 {
+		return fmt.Errorf("pan: at least one output channel is required")
+	}
+	for _, ch := range f.Channels {
+		if !isLayoutChannel(ch.Channel, layout) {
+			return fmt.Errorf("pan: channel %q is not part of layout %s", ch.Channel, f.Layout)
+		}
+		if len(ch.Terms) == 0 {
+			return fmt.Errorf("pan: channel %s has no input channels", ch.Channel)
+		}
+		for _, term := range ch.Terms {
+			if !isInputChannel(term.Channel) {
+				return fmt.Errorf("pan: unknown input channel %q for %s", term.Channel, ch.Channel)
+			}
+			if math.IsNaN(term.Gain) || math.IsInf(term.Gain, 0) {
+				return fmt.Errorf("pan: invalid gain for %s in %s", term.Channel, ch.Channel)
+			}
+		}
+	}
+	return nil
+}
+
+func (f PanFilter) Parse() string {
+	parts := []string{f.Layout}
+	for _, ch := range f.Channels {
+		parts = append(parts, ch.String())
+	}
+	return fmt.Sprintf("[%s]pan=%s[%s]", f.Input, strings.Join(parts, "|"), f.Output)
+}
+
+// DownmixCoefficients are the gains applied when folding surround channels into stereo.
+// Front left/right keep unity gain; a back center channel is split between both sides.
+type DownmixCoefficients struct {
+	Center      float64
+	Surround    float64
+	LFE         float64 // 0 drops the LFE channel
+	Renormalize bool
+}
+
+// DownmixITU is the ITU-R BS.775 downmix: center and surrounds at -3dB, LFE dropped.
+var DownmixITU = DownmixCoefficients{Center: 0.707, Surround: 0.707}
+
+// DownmixWithLFE keeps the LFE channel at -6dB and renormalizes to avoid clipping.
+var DownmixWithLFE = DownmixCoefficients{Center: 0.707, Surround: 0.707, LFE: 0.5, Renormalize: true}
+
+// StereoDownmix returns a pan filter folding a surround layout ("5.1", "5.1(side)", "7.1", ...)
+// into stereo with the given coefficients:
+//
+//	[0:a]pan=stereo|FL=FL+0.707*FC+0.707*BL|FR=FR+0.707*FC+0.707*BR[stereo]
+func StereoDownmix(input string, output string, source string, coeffs DownmixCoefficients) (FilterFn, error) {
+	channels, err := ParseChannelLayout(source)
+	if err != nil {
+		return nil, fmt.Errorf("downmix: %w", err)
+	}
+
+	left := PanChannel{Channel: "FL", Renormalize: coeffs.Renormalize}
+	right := PanChannel{Channel: "FR", Renormalize: coeffs.Renormalize}
+	both := func(gain float64, ch string) {
+		if gain != 0 {
+			left.Terms = append(left.Terms, PanTerm{Gain: gain, Channel: ch})
+			right.Terms = append(right.Terms, PanTerm{Gain: gain, Channel: ch})
+		}
+	}
+	for _, ch := range channels {
+		switch ch {
+		case "FL", "FLC", "WL", "DL":
+			left.Terms = append(left.Terms, PanTerm{Gain: 1, Channel: ch})
+		case "FR", "FRC", "WR", "DR":
+			right.Terms = append(right.Terms, PanTerm{Gain: 1, Channel: ch})
+		case "BL", "SL", "SDL":
+			if coeffs.Surround != 0 {
+				left.Terms = append(left.Terms, PanTerm{Gain: coeffs.Surround, Channel: ch})
+			}
+		case "BR", "SR", "SDR":
+			if coeffs.Surround != 0 {
+				right.Terms = append(right.Terms, PanTerm{Gain: coeffs.Surround, Channel: ch})
+			}
+		case "FC":
+			both(coeffs.Center, ch)
+		case "BC":
+			both(math.Round(coeffs.Surround*math.Sqrt2/2*1000)/1000, ch)
+		case "LFE", "LFE2":
+			both(coeffs.LFE, ch)
+		default:
+			return nil, fmt.Errorf("downmix: channel %s of %s has no stereo mapping", ch, source)
+		}
+	}
+	if len(left.Terms) == 0 || len(right.Terms) == 0 {
+		return nil, fmt.Errorf("downmix: layout %s has no left and right channels", source)
+	}
+
+	return withFilter(PanFilter{
+		Input:    strings.TrimSpace(input),
+		Output:   strings.TrimSpace(output),
+		Layout:   "stereo",
+		Channels: []PanChannel{left, right},
+	}), nil
+}
+
+// AMergeFilter renders: "[a0][a1]amerge=inputs=2[output]"
+// The output carries the channels of every input, in input order.
+type AMergeFilter struct {
+	Inputs []string
+	Output string
+}
+
+func (f AMergeFilter) Validate() error {
+	if len(f.Inputs) < 2 {
+		return fmt.Errorf("amerge: at least 2 inputs are required, got %d", len(f.Inputs))
+	}
+	return checkLabels("amerge", append(append([]string{}, f.Inputs...), f.Output)...)
+}
+
+func (f AMergeFilter) Parse() string {
+	return fmt.Sprintf("%samerge=inputs=%d[%s]", padLabels(f.Inputs), len(f.Inputs), f.Output)
+}
+
+// AMixFilter renders: "[a0][a1]amix=inputs=2:duration=first:weights=1 0.3:normalize=0[output]"
+// NoNormalize keeps the input levels instead of dividing by the number of inputs (ffmpeg 4.4+).
+type AMixFilter struct {
+	Inputs      []string
+	Output      string
+	Duration    string // longest (default), shortest or first
+	Weights     []float64
+	NoNormalize bool
+}
+
+func (f AMixFilter) Validate() error {
+	if len(f.Inputs) < 2 {
+		return fmt.Errorf("amix: at least 2 inputs are required, got %d", len(f.Inputs))
+	}
+	if err := checkLabels("amix", append(append([]string{}, f.Inputs...), f.Output)...); err != nil {
+		return err
+	}
+	if err := oneOf("amix duration", f.Duration, []string{"longest", "shortest", "first"}); err != nil {
+		return err
+	}
+	if len(f.Weights) > 0 && len(f.Weights) != len(f.Inputs) {
+		return fmt.Errorf("amix: expected %d weights, got %d", len(f.Inputs), len(f.Weights))
+	}
+	for _, w := range f.Weights {
+		if math.IsNaN(w) || math.IsInf(w, 0) {
+			return fmt.Errorf("amix: invalid weight %v", w)
+		}
+	}
+	return nil
+}
+
+func (f AMixFilter) Parse() string {
+	opts := []string{fmt.Sprintf("inputs=%d", len(f.Inputs))}
+	if f.Duration != "" {
+		opts = append(opts, "duration="+f.Duration)
+	}
+	if len(f.Weights) > 0 {
+		weights := make([]string, len(f.Weights))
+		for i, w := range f.Weights {
+			weights[i] = formatFloat(w)
+		}
+		opts = append(opts, "weights="+strings.Join(weights, " "))
+	}
+	if f.NoNormalize {
+		opts = append(opts, "normalize=0")
+	}
+	return fmt.Sprintf("%samix=%s[%s]", padLabels(f.Inputs), strings.Join(opts, ":"), f.Output)
+}
+
+// ChannelSplitFilter renders: "[input]channelsplit=channel_layout=5.1:channels=FL+FR[fl][fr]"
+// Without Channels every channel of the layout gets an output, in layout order.
+type ChannelSplitFilter struct {
+	Input    string
+	Layout   string
+	Channels []string
+	Outputs  []string
+}
+
+func (f ChannelSplitFilter) Validate() error {
+	if err := checkLabels("channelsplit", append([]string{f.Input}, f.Outputs...)...); err != nil {
+		return err
+	}
+	layout, err := ParseChannelLayout(f.Layout)
+	if err != nil {
+		return fmt.Errorf("channelsplit: %w", err)
+	}
+	channels := layout
+	if len(f.Channels) > 0 {
+		channels = f.Channels
+		for _, ch := range f.Channels {
+			if !isLayoutChannel(ch, layout) {
+				return fmt.Errorf("channelsplit: channel %q is not part of layout %s", ch, f.Layout)
+			}
+		}
+	}
+	if len(f.Outputs) != len(channels) {
+		return fmt.Errorf("channelsplit: expected %d outputs, got %d", len(channels), len(f.Outputs))
+	}
+	return nil
+}
+
+func (f ChannelSplitFilter) Parse() string {
+	opts := "channel_layout=" + f.Layout
+	if len(f.Channels) > 0 {
+		opts += ":channels=" + strings.Join(f.Channels, "+")
+	}
+	return fmt.Sprintf("[%s]channelsplit=%s%s", f.Input, opts, padLabels(f.Outputs))
+}
+
+// JoinMapping routes channel From (a name or index) of input Input to output channel To.
+type JoinMapping struct {
+	Input int
+	From  string
+	To    string
+}
+
+func (m JoinMapping) String() string {
+	return fmt.Sprintf("%d.%s-%s", m.Input, m.From, m.To)
+}
+
+// JoinFilter renders: "[a0][a1]join=inputs=2:channel_layout=stereo:map=0.0-FL|1.0-FR[output]"
+// Without Map ffmpeg assigns input channels to the layout in order.
+type JoinFilter struct {
+	Inputs []string
+	Output string
+	Layout string
+	Map    []JoinMapping
+}
+
+func (f JoinFilter) Validate() error {
+	if len(f.Inputs) < 1 {
+		return fmt.Errorf("join: at least 1 input is required")
+	}
+	if err := checkLabels("join", append(append([]string{}, f.Inputs...), f.Output)...); err != nil {
+		return err
+	}
+	layout, err := ParseChannelLayout(f.Layout)
+	if err != nil {
+		return fmt.Errorf("join: %w", err)
+	}
+	for _, m := range f.Map {
+		if m.Input < 0 || m.Input >= len(f.Inputs) {
+			return fmt.Errorf("join: mapping %s refers to missing input %d", m, m.Input)
+		}
+		if _, err := strconv.Atoi(m.From); err != nil && !isInputChannel(m.From) {
+			return fmt.Errorf("join: mapping %s has unknown input channel %q", m, m.From)
+		}
+		if !isLayoutChannel(m.To, layout) {
+			return fmt.Errorf("join: mapping %s targets channel %q outside layout %s", m, m.To, f.Layout)
+		}
+	}
+	return nil
+}
+
+func (f JoinFilter) Parse() string {
+	opts := fmt.Sprintf("inputs=%d:channel_layout=%s", len(f.Inputs), f.Layout)
+	if len(f.Map) > 0 {
+		maps := make([]string, len(f.Map))
+		for i, m := range f.Map {
+			maps[i] = m.String()
+		}
+		opts += ":map=" + strings.Join(maps, "|")
+	}
+	return fmt.Sprintf("%sjoin=%s[%s]", padLabels(f.Inputs), opts, f.Output)
+}
+
+// WithPan adds a labeled pan filter chain.
+// Renders: "[input]pan=layout|FL=...|FR=...[output]"
+func WithPan(input string, output string, layout string, channels ...PanChannel) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(PanFilter{
+			Input:    strings.TrimSpace(input),
+			Output:   strings.TrimSpace(output),
+			Layout:   strings.TrimSpace(layout),
+			Channels: channels,
+		})
+	}
+}
+
+// WithAMerge adds an amerge filter combining the channels of every input into output.
+// Renders: "[a0][a1]amerge=inputs=2[output]"
+func WithAMerge(output string, inputs ...string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(AMergeFilter{Inputs: trimLabels(inputs), Output: strings.TrimSpace(output)})
+	}
+}
+
+// WithAMix adds an amix filter mixing inputs into output, optionally weighted per input.
+// Renders: "[a0][a1]amix=inputs=2:duration=first:weights=1 0.3[output]"
+func WithAMix(output string, duration string, weights []float64, inputs ...string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(AMixFilter{
+			Inputs:   trimLabels(inputs),
+			Output:   strings.TrimSpace(output),
+			Duration: duration,
+			Weights:  weights,
+		})
+	}
+}
+
+// WithChannelSplit adds a channelsplit filter with one output per channel of layout.
+// Renders: "[input]channelsplit=channel_layout=layout[out0][out1]..."
+func WithChannelSplit(input string, layout string, outputs ...string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(ChannelSplitFilter{
+			Input:   strings.TrimSpace(input),
+			Layout:  strings.TrimSpace(layout),
+			Outputs: trimLabels(outputs),
+		})
+	}
+}
+
+// WithJoin adds a join filter building a layout from channels of several inputs.
+// Renders: "[a0][a1]join=inputs=2:channel_layout=layout:map=0.0-FL|1.0-FR[output]"
+func WithJoin(output string, layout string, mapping []JoinMapping, inputs ...string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(JoinFilter{
+			Inputs: trimLabels(inputs),
+			Output: strings.TrimSpace(output),
+			Layout: strings.TrimSpace(layout),
+			Map:    mapping,
+		})
+	}
+}
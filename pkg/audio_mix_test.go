@@ -0,0 +1,162 @@
+package ffmpego
+
+import "testing"
+
+func TestParseChannelLayout(t *testing.T) {
+	tests := map[string]int{"stereo": 2, "5.1(side)": 6, "7.1": 8, "FL+FR+LFE": 3, "6c": 6}
+	for layout, want := range tests {
+		channels, err := ParseChannelLayout(layout)
+		if err != nil {
+			t.Errorf("ParseChannelLayout(%q) error: %v", layout, err)
+			continue
+		}
+		if len(channels) != want {
+			t.Errorf("ParseChannelLayout(%q) = %v, want %d channels", layout, channels, want)
+		}
+	}
+
+	for _, layout := range []string{"", "5.1 side", "FL+FL", "FL+XX", "0c", "surround"} {
+		if _, err := ParseChannelLayout(layout); err == nil {
+			t.Errorf("ParseChannelLayout(%q): expected error, got nil", layout)
+		}
+	}
+}
+
+func TestStereoDownmix_ITU(t *testing.T) {
+	fn, err := StereoDownmix("0:a", "st", "5.1", DownmixITU)
+	if err != nil {
+		t.Fatalf("StereoDownmix() error: %v", err)
+	}
+	graph, err := NewComplexFilterBuilder().Add(fn).Build().BuildAndValidate()
+	if err != nil {
+		t.Fatalf("BuildAndValidate() error: %v", err)
+	}
+	want := "[0:a]pan=stereo|FL=FL+0.707*FC+0.707*BL|FR=FR+0.707*FC+0.707*BR[st]"
+	if graph != want {
+		t.Fatalf("graph mismatch:\n got: %s\nwant: %s", graph, want)
+	}
+}
+
+func TestPanFilter_ValidateParse(t *testing.T) {
+	f := PanFilter{Input: "5:a", Output: "mono", Layout: "mono",
+		Channels: []PanChannel{{Channel: "FC", Terms: []PanTerm{{1, "FL"}, {-0.5, "FR"}}, Renormalize: true}}}
+	if err := f.Validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	got := f.Parse()
+	want := "[5:a]pan=mono|FC<FL-0.5*FR[mono]"
+	if got != want {
+		t.Fatalf("parse mismatch: got %q want %q", got, want)
+	}
+}
+
+func TestWithAMix(t *testing.T) {
+	graph, err := NewComplexFilterBuilder().Add(WithAMix("mix", "first", []float64{1, 0.3}, "st", "1:a")).Build().BuildAndValidate()
+	if err != nil {
+		t.Fatalf("BuildAndValidate() error: %v", err)
+	}
+	want := "[st][1:a]amix=inputs=2:duration=first:weights=1 0.3[mix]"
+	if graph != want {
+		t.Fatalf("graph mismatch:\n got: %s\nwant: %s", graph, want)
+	}
+}
+
+func TestAMergeFilter_ValidateParse(t *testing.T) {
+	f := AMergeFilter{Inputs: []string{"2:a", "3:a"}, Output: "merged"}
+	if err := f.Validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	got := f.Parse()
+	want := "[2:a][3:a]amerge=inputs=2[merged]"
+	if got != want {
+		t.Fatalf("parse mismatch: got %q want %q", got, want)
+	}
+}
+
+func TestChannelSplitFilter_ValidateParse(t *testing.T) {
+	f := ChannelSplitFilter{Input: "4:a", Layout: "stereo", Outputs: []string{"l", "r"}}
+	if err := f.Validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	got := f.Parse()
+	want := "[4:a]channelsplit=channel_layout=stereo[l][r]"
+	if got != want {
+		t.Fatalf("parse mismatch: got %q want %q", got, want)
+	}
+}
+
+func TestWithJoin(t *testing.T) {
+	graph, err := NewComplexFilterBuilder().
+		Add(WithJoin("joined", "stereo", []JoinMapping{{0, "0", "FL"}, {1, "FR", "FR"}}, "l", "r")).
+		Build().
+		BuildAndValidate()
+	if err != nil {
+		t.Fatalf("BuildAndValidate() error: %v", err)
+	}
+	want := "[l][r]join=inputs=2:channel_layout=stereo:map=0.0-FL|1.FR-FR[joined]"
+	if graph != want {
+		t.Fatalf("graph mismatch:\n got: %s\nwant: %s", graph, want)
+	}
+}
+
+func TestPanFilter_InvalidChannels(t *testing.T) {
+	cases := []PanFilter{
+		{Input: "a", Output: "b", Layout: "5.1 side", Channels: []PanChannel{{Channel: "FL", Terms: []PanTerm{{1, "FL"}}}}},
+		{Input: "a", Output: "b", Layout: "stereo", Channels: []PanChannel{{Channel: "FC", Terms: []PanTerm{{1, "FL"}}}}},
+		{Input: "a", Output: "b", Layout: "stereo", Channels: []PanChannel{{Channel: "FL"}}},
+	}
+	for i, f := range cases {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("case %d: expected error for pan channels, got nil", i)
+		}
+	}
+}
+
+func TestAMergeFilter_InvalidInputs(t *testing.T) {
+	f := AMergeFilter{Inputs: []string{"a"}, Output: "b"}
+	if err := f.Validate(); err == nil {
+		t.Fatalf("expected error for a single input, got nil")
+	}
+}
+
+func TestAMixFilter_InvalidOptions(t *testing.T) {
+	cases := []AMixFilter{
+		{Inputs: []string{"a", "b"}, Output: "c", Duration: "all"},
+		{Inputs: []string{"a", "b"}, Output: "c", Weights: []float64{1}},
+	}
+	for i, f := range cases {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("case %d: expected error for amix options, got nil", i)
+		}
+	}
+}
+
+func TestChannelSplitFilter_InvalidOutputs(t *testing.T) {
+	cases := []ChannelSplitFilter{
+		{Input: "a", Layout: "5.1", Outputs: []string{"l", "r"}},
+		{Input: "a", Layout: "stereo", Channels: []string{"FC"}, Outputs: []string{"c"}},
+	}
+	for i, f := range cases {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("case %d: expected error for channelsplit outputs, got nil", i)
+		}
+	}
+}
+
+func TestJoinFilter_InvalidMap(t *testing.T) {
+	cases := []JoinFilter{
+		{Inputs: []string{"a"}, Output: "b", Layout: "stereo", Map: []JoinMapping{{1, "0", "FL"}}},
+		{Inputs: []string{"a"}, Output: "b", Layout: "stereo", Map: []JoinMapping{{0, "0", "LFE"}}},
+	}
+	for i, f := range cases {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("case %d: expected error for join map, got nil", i)
+		}
+	}
+}
+
+func TestChannelsFlag_Validate(t *testing.T) {
+	if err := ChannelsFlag(24).Validate(); err != nil {
+		t.Fatalf("ChannelsFlag(24) error: %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package ffmpego
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Capabilities describes what the ffmpeg binary was built with.
+type Capabilities struct {
+	Filters map[string]bool
+	Version FFmpegVersion
+}
+
+// FFmpegVersion is the release of the ffmpeg binary. Development builds ("N-112233-g...")
+// have no release number and are treated as newer than every release.
+type FFmpegVersion struct {
+	Major int
+	Minor int
+	Dev   bool
+	Raw   string
+}
+
+// AtLeast reports whether the version is major.minor or newer. An undetected version
+// (zero value) is assumed to be recent.
+func (v FFmpegVersion) AtLeast(major, minor int) bool {
+	if v.Dev || v.Raw == "" {
+		return true
+	}
+	return v.Major > major || (v.Major == major && v.Minor >= minor)
+}
+
+func (v FFmpegVersion) String() string {
+	if v.Raw == "" {
+		return "unknown"
+	}
+	return v.Raw
+}
+
+// HasFilter reports whether the binary provides the named filter (e.g. "libvmaf", "zscale").
+func (c *Capabilities) HasFilter(name string) bool {
+	return c != nil && c.Filters[name]
+}
+
+// DetectCapabilities queries the ffmpeg binary from PATH.
+func DetectCapabilities(ctx context.Context) (*Capabilities, error) {
+	return detectCapabilities(ctx, &NativeCommandHandler{})
+}
+
+func detectCapabilities(ctx context.Context, runner CommandRunner) (*Capabilities, error) {
+	cmd := runner.CommandContext(ctx, "ffmpeg", "-hide_banner", "-filters")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w\nOutput: %s", err, stderr.String())
+	}
+
+	version, err := detectVersion(ctx, runner)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Capabilities{Filters: ParseFilterList(output), Version: version}, nil
+}
+
+// DetectVersion queries the version of the ffmpeg binary from PATH.
+func DetectVersion(ctx context.Context) (FFmpegVersion, error) {
+	return detectVersion(ctx, &NativeCommandHandler{})
+}
+
+func detectVersion(ctx context.Context, runner CommandRunner) (FFmpegVersion, error) {
+	cmd := runner.CommandContext(ctx, "ffmpeg", "-hide_banner", "-version")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return FFmpegVersion{}, fmt.Errorf("ffmpeg failed: %w\nOutput: %s", err, stderr.String())
+	}
+
+	return ParseFFmpegVersion(output)
+}
+
+// ParseFFmpegVersion parses the first line of `ffmpeg -version`, e.g.
+// "ffmpeg version 6.1.1-3ubuntu5 Copyright ...", "ffmpeg version n5.0" or a git build
+// "ffmpeg version N-112233-gabcdef".
+func ParseFFmpegVersion(output []byte) (FFmpegVersion, error) {
+	line, _, _ := strings.Cut(string(output), "\n")
+	fields := strings.Fields(line)
+	if len(fields) < 3 || fields[0] != "ffmpeg" || fields[1] != "version" {
+		return FFmpegVersion{}, fmt.Errorf("unrecognized ffmpeg version line %q", line)
+	}
+
+	raw := fields[2]
+	version := FFmpegVersion{Raw: raw}
+	if strings.HasPrefix(raw, "N-") || strings.HasPrefix(raw, "git-") {
+		version.Dev = true
+		return version, nil
+	}
+
+	number := strings.TrimPrefix(raw, "n")
+	if end := strings.IndexFunc(number, func(r rune) bool { return (r < '0' || r > '9') && r != '.' }); end >= 0 {
+		number = number[:end]
+	}
+	parts := strings.Split(number, ".")
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return FFmpegVersion{}, fmt.Errorf("unrecognized ffmpeg version %q", raw)
+	}
+	version.Major = major
+	if len(parts) > 1 {
+		version.Minor, _ = strconv.Atoi(parts[1])
+	}
+	return version, nil
+}
+
+// ParseFilterList parses `ffmpeg -filters` output, where each filter is listed as
+// " TSC name  inputs->outputs  description" after a legend ending with "  ------".
+func ParseFilterList(output []byte) map[string]bool {
+	filters := make(map[string]bool)
+	listing := false
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if !listing {
+			listing = strings.HasPrefix(fields[0], "---")
+			continue
+		}
+		if len(fields) >= 3 && strings.Contains(fields[2], "->") {
+			filters[fields[1]] = true
+		}
+	}
+	return filters
+}
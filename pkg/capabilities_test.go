@@ -0,0 +1,66 @@
+package ffmpego
+
+import "testing"
+
+func TestParseFilterList(t *testing.T) {
+	output := `Filters:
+  T.. = Timeline support
+  .S. = Slice threading
+  ..C = Command support
+  A = Audio input/output
+  V = Video input/output
+  N = Dynamic number and/or type of input/output
+  | = Source or sink filter
+ ------
+ ... abench            A->A       Benchmark part of a filtergraph.
+ TSC psnr              VV->V      Calculate the PSNR between two video streams.
+ ... libvmaf           VV->V      Calculate the VMAF between two video streams.
+ ... nullsink          V->|       Do absolutely nothing with the input video.
+`
+	filters := ParseFilterList([]byte(output))
+	for _, name := range []string{"abench", "psnr", "libvmaf", "nullsink"} {
+		if !filters[name] {
+			t.Fatalf("expected filter %q in %v", name, filters)
+		}
+	}
+	if filters["A"] || filters["Filters:"] || len(filters) != 4 {
+		t.Fatalf("legend lines must not be parsed as filters: %v", filters)
+	}
+
+	caps := &Capabilities{Filters: filters}
+	if !caps.HasFilter("libvmaf") || caps.HasFilter("zscale") {
+		t.Fatalf("HasFilter mismatch")
+	}
+}
+
+func TestParseFFmpegVersion(t *testing.T) {
+	tests := []struct {
+		line  string
+		major int
+		minor int
+		dev   bool
+	}{
+		{"ffmpeg version 6.1.1-3ubuntu5 Copyright (c) 2000-2023 the FFmpeg developers\nbuilt with gcc", 6, 1, false},
+		{"ffmpeg version n5.0 Copyright (c) 2000-2022", 5, 0, false},
+		{"ffmpeg version 4.4.2-0ubuntu0.22.04.1 Copyright", 4, 4, false},
+		{"ffmpeg version N-112233-gabcdef Copyright", 0, 0, true},
+	}
+	for _, tt := range tests {
+		v, err := ParseFFmpegVersion([]byte(tt.line))
+		if err != nil {
+			t.Fatalf("ParseFFmpegVersion(%q) error: %v", tt.line, err)
+		}
+		if v.Major != tt.major || v.Minor != tt.minor || v.Dev != tt.dev {
+			t.Errorf("ParseFFmpegVersion(%q) = %+v", tt.line, v)
+		}
+	}
+
+	if _, err := ParseFFmpegVersion([]byte("ffprobe version 6.0")); err == nil {
+		t.Fatalf("expected error for a non ffmpeg version line, got nil")
+	}
+
+	old := FFmpegVersion{Major: 5, Minor: 0, Raw: "5.0"}
+	if old.AtLeast(5, 1) || !old.AtLeast(4, 4) || !(FFmpegVersion{}).AtLeast(7, 0) {
+		t.Fatalf("AtLeast mismatch")
+	}
+}
@@ -0,0 +1,500 @@
+package ffmpego
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Enumerated encoder settings used to validate the option sets below.
+var (
+	x26xPresets  = []string{"ultrafast", "superfast", "veryfast", "faster", "fast", "medium", "slow", "slower", "veryslow", "placebo"}
+	x264Tunes    = []string{"film", "animation", "grain", "stillimage", "fastdecode", "zerolatency", "psnr", "ssim"}
+	x264Profiles = []string{"baseline", "main", "high", "high10", "high422", "high444p"}
+	x265Tunes    = []string{"psnr", "ssim", "grain", "zerolatency", "fastdecode", "animation"}
+	x265Profiles = []string{"main", "main10", "mainstillpicture", "main12", "main422-10", "main422-12",
+		"main444-8", "main444-10", "main444-12"}
+	vp9Deadlines  = []string{"good", "best", "realtime"}
+	aomUsages     = []string{"good", "realtime", "allintra"}
+	svtAV1Tunes   = map[string]int{"vq": 0, "psnr": 1, "ssim": 2}
+	av1Profiles   = []string{"main", "high", "professional"}
+	h264Levels    = []string{"1", "1b", "1.1", "1.2", "1.3", "2", "2.1", "2.2", "3", "3.1", "3.2", "4", "4.1", "4.2", "5", "5.1", "5.2", "6", "6.1", "6.2"}
+	hevcLevels    = []string{"1", "2", "2.1", "3", "3.1", "4", "4.1", "5", "5.1", "5.2", "6", "6.1", "6.2"}
+	encoderParams = map[string]bool{"-x264-params": true, "-x265-params": true, "-svtav1-params": true, "-aom-params": true}
+)
+
+// oneOf returns an error unless value is empty or listed in allowed
+func oneOf(what, value string, allowed []string) error {
+	if value == "" {
+		return nil
+	}
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown %s %q, expected one of %s", what, value, strings.Join(allowed, ", "))
+}
+
+// encoderParamsValue renders params as a sorted "key=value:key=value" list
+func encoderParamsValue(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+params[k])
+	}
+	return strings.Join(pairs, ":")
+}
+
+// validateEncoderParams rejects keys and values that would break the ':' separated list
+func validateEncoderParams(params map[string]string) error {
+	for k, v := range params {
+		if k == "" || strings.ContainsAny(k, ":=") || strings.Contains(v, ":") {
+			return fmt.Errorf("invalid encoder parameter %q=%q", k, v)
+		}
+	}
+	return nil
+}
+
+// requireEncoder binds an option set to the output's video codec
+func requireEncoder(options string, encoder string, allowed ...string) error {
+	for _, a := range allowed {
+		if encoder == a {
+			return nil
+		}
+	}
+	if encoder == "" {
+		return fmt.Errorf("%s require -c:v %s", options, allowed[0])
+	}
+	return fmt.Errorf("%s cannot be used with video codec %s", options, encoder)
+}
+
+// mergeEncoderParams joins repeated -x264-params style options, which ffmpeg would
+// otherwise override with the last one, into the first occurrence.
+func mergeEncoderParams(args []string) []string {
+	first := map[string]int{}
+	merged := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if encoderParams[args[i]] && i+1 < len(args) {
+			if at, ok := first[args[i]]; ok {
+				merged[at] += ":" + args[i+1]
+				i++
+				continue
+			}
+			first[args[i]] = len(merged) + 1
+		}
+		merged = append(merged, args[i])
+	}
+	return merged
+}
+
+// X264Options are libx264 settings. Zero values keep the preset defaults; BFrames and
+// Refs cannot be forced to 0 here, use Profile "baseline" or Tune "zerolatency".
+type X264Options struct {
+	Preset    string
+	Tune      string
+	Profile   string
+	Level     string // e.g. "4.1"
+	Keyint    int    // -g
+	MinKeyint int    // -keyint_min
+	BFrames   int    // -bf
+	Refs      int    // -refs
+	Params    map[string]string
+}
+
+func (o X264Options) forEncoder(encoder string) (OutputFlagParser, error) {
+	if err := requireEncoder("x264 options", encoder, "libx264", "libx264rgb"); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Validate validates the enumerated settings and numeric ranges
+func (o X264Options) Validate() error {
+	for _, err := range []error{
+		oneOf("x264 preset", o.Preset, x26xPresets),
+		oneOf("x264 tune", o.Tune, x264Tunes),
+		oneOf("x264 profile", o.Profile, x264Profiles),
+		oneOf("H.264 level", o.Level, h264Levels),
+		validateEncoderParams(o.Params),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	if o.Keyint < 0 || o.MinKeyint < 0 || o.BFrames < 0 || o.Refs < 0 {
+		return fmt.Errorf("x264 keyint, min keyint, bframes and refs must be non-negative")
+	}
+	if o.MinKeyint > 0 && o.Keyint > 0 && o.MinKeyint > o.Keyint {
+		return fmt.Errorf("x264 min keyint %d exceeds keyint %d", o.MinKeyint, o.Keyint)
+	}
+	if o.BFrames > 16 || o.Refs > 16 {
+		return fmt.Errorf("x264 bframes and refs must be at most 16")
+	}
+	if o.Profile == "baseline" && o.BFrames > 0 {
+		return fmt.Errorf("x264 baseline profile does not support B-frames")
+	}
+	return nil
+}
+
+// Parse returns native libx264 flags followed by -x264-params
+func (o X264Options) Parse() []string {
+	var args []string
+	add := func(flag, value string) {
+		if value != "" && value != "0" {
+			args = append(args, flag, value)
+		}
+	}
+	add("-preset", o.Preset)
+	add("-tune", o.Tune)
+	add("-profile:v", o.Profile)
+	add("-level:v", o.Level)
+	add("-g", strconv.Itoa(o.Keyint))
+	add("-keyint_min", strconv.Itoa(o.MinKeyint))
+	add("-bf", strconv.Itoa(o.BFrames))
+	add("-refs", strconv.Itoa(o.Refs))
+	if len(o.Params) > 0 {
+		args = append(args, "-x264-params", encoderParamsValue(o.Params))
+	}
+	return args
+}
+
+// X265Options are libx265 settings. Preset, tune and profile are native flags; the
+// level, GOP and reference settings go through -x265-params.
+type X265Options struct {
+	Preset    string
+	Tune      string
+	Profile   string
+	Level     string // e.g. "5.1"
+	HighTier  bool
+	Keyint    int
+	MinKeyint int
+	BFrames   int
+	Refs      int // 1..16
+	Params    map[string]string
+}
+
+func (o X265Options) forEncoder(encoder string) (OutputFlagParser, error) {
+	if err := requireEncoder("x265 options", encoder, "libx265"); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Validate validates the enumerated settings and numeric ranges
+func (o X265Options) Validate() error {
+	for _, err := range []error{
+		oneOf("x265 preset", o.Preset, x26xPresets),
+		oneOf("x265 tune", o.Tune, x265Tunes),
+		oneOf("x265 profile", o.Profile, x265Profiles),
+		oneOf("HEVC level", o.Level, hevcLevels),
+		validateEncoderParams(o.Params),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	if o.Keyint < 0 || o.MinKeyint < 0 || o.BFrames < 0 || o.Refs < 0 {
+		return fmt.Errorf("x265 keyint, min keyint, bframes and refs must be non-negative")
+	}
+	if o.MinKeyint > 0 && o.Keyint > 0 && o.MinKeyint > o.Keyint {
+		return fmt.Errorf("x265 min keyint %d exceeds keyint %d", o.MinKeyint, o.Keyint)
+	}
+	if o.BFrames > 16 || o.Refs > 16 {
+		return fmt.Errorf("x265 bframes and refs must be at most 16")
+	}
+	if o.HighTier && o.Level == "" {
+		return fmt.Errorf("x265 high tier requires a level")
+	}
+	return nil
+}
+
+// Parse returns native libx265 flags followed by -x265-params
+func (o X265Options) Parse() []string {
+	var args []string
+	add := func(flag, value string) {
+		if value != "" {
+			args = append(args, flag, value)
+		}
+	}
+	add("-preset", o.Preset)
+	add("-tune", o.Tune)
+	add("-profile:v", o.Profile)
+
+	params := map[string]string{}
+	for k, v := range o.Params {
+		params[k] = v
+	}
+	if o.Level != "" {
+		params["level-idc"] = o.Level
+	}
+	if o.HighTier {
+		params["high-tier"] = "1"
+	}
+	for key, value := range map[string]int{"keyint": o.Keyint, "min-keyint": o.MinKeyint, "bframes": o.BFrames, "ref": o.Refs} {
+		if value > 0 {
+			params[key] = strconv.Itoa(value)
+		}
+	}
+	if len(params) > 0 {
+		args = append(args, "-x265-params", encoderParamsValue(params))
+	}
+	return args
+}
+
+// VP9Options are libvpx-vp9 settings. VP9 has no presets: speed is Deadline plus CPUUsed.
+type VP9Options struct {
+	Deadline    string // good, best or realtime
+	CPUUsed     int    // -8..8, 0 keeps the encoder default
+	Profile     int    // 0..3; 2 and 3 are high bit depth
+	Keyint      int
+	RowMT       bool
+	TileColumns int // log2 of the tile column count, 0..6
+	LagInFrames int // 0..25
+	AutoAltRef  bool
+}
+
+func (o VP9Options) forEncoder(encoder string) (OutputFlagParser, error) {
+	if err := requireEncoder("vp9 options", encoder, "libvpx-vp9"); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Validate validates the enumerated settings and numeric ranges
+func (o VP9Options) Validate() error {
+	if err := oneOf("vp9 deadline", o.Deadline, vp9Deadlines); err != nil {
+		return err
+	}
+	if o.CPUUsed < -8 || o.CPUUsed > 8 {
+		return fmt.Errorf("vp9 cpu-used must be between -8 and 8, got %d", o.CPUUsed)
+	}
+	if o.Profile < 0 || o.Profile > 3 {
+		return fmt.Errorf("vp9 profile must be between 0 and 3, got %d", o.Profile)
+	}
+	if o.Keyint < 0 {
+		return fmt.Errorf("vp9 keyint must be non-negative, got %d", o.Keyint)
+	}
+	if o.TileColumns < 0 || o.TileColumns > 6 {
+		return fmt.Errorf("vp9 tile columns must be between 0 and 6, got %d", o.TileColumns)
+	}
+	if o.LagInFrames < 0 || o.LagInFrames > 25 {
+		return fmt.Errorf("vp9 lag in frames must be between 0 and 25, got %d", o.LagInFrames)
+	}
+	return nil
+}
+
+// Parse returns the libvpx-vp9 flags
+func (o VP9Options) Parse() []string {
+	var args []string
+	add := func(flag, value string) {
+		if value != "" && value != "0" {
+			args = append(args, flag, value)
+		}
+	}
+	add("-deadline", o.Deadline)
+	add("-cpu-used", strconv.Itoa(o.CPUUsed))
+	add("-profile:v", strconv.Itoa(o.Profile))
+	add("-g", strconv.Itoa(o.Keyint))
+	if o.RowMT {
+		args = append(args, "-row-mt", "1")
+	}
+	add("-tile-columns", strconv.Itoa(o.TileColumns))
+	add("-lag-in-frames", strconv.Itoa(o.LagInFrames))
+	if o.AutoAltRef {
+		args = append(args, "-auto-alt-ref", "1")
+	}
+	return args
+}
+
+// AV1Options are libaom-av1 settings.
+type AV1Options struct {
+	Usage       string // good, realtime or allintra
+	CPUUsed     int    // 0..9, 0 keeps the encoder default
+	Profile     string // main, high or professional
+	Keyint      int
+	RowMT       bool
+	TileColumns int // log2, 0..6
+	TileRows    int // log2, 0..6
+	LagInFrames int
+	Params      map[string]string // -aom-params
+}
+
+func (o AV1Options) forEncoder(encoder string) (OutputFlagParser, error) {
+	if err := requireEncoder("libaom av1 options", encoder, "libaom-av1"); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Validate validates the enumerated settings and numeric ranges
+func (o AV1Options) Validate() error {
+	for _, err := range []error{
+		oneOf("libaom usage", o.Usage, aomUsages),
+		oneOf("AV1 profile", o.Profile, av1Profiles),
+		validateEncoderParams(o.Params),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	if o.CPUUsed < 0 || o.CPUUsed > 9 {
+		return fmt.Errorf("libaom cpu-used must be between 0 and 9, got %d", o.CPUUsed)
+	}
+	if o.Keyint < 0 || o.LagInFrames < 0 {
+		return fmt.Errorf("libaom keyint and lag in frames must be non-negative")
+	}
+	if o.TileColumns < 0 || o.TileColumns > 6 || o.TileRows < 0 || o.TileRows > 6 {
+		return fmt.Errorf("libaom tile columns and rows must be between 0 and 6")
+	}
+	return nil
+}
+
+// Parse returns the libaom-av1 flags followed by -aom-params
+func (o AV1Options) Parse() []string {
+	var args []string
+	add := func(flag, value string) {
+		if value != "" && value != "0" {
+			args = append(args, flag, value)
+		}
+	}
+	add("-usage", o.Usage)
+	add("-cpu-used", strconv.Itoa(o.CPUUsed))
+	add("-profile:v", o.Profile)
+	add("-g", strconv.Itoa(o.Keyint))
+	if o.RowMT {
+		args = append(args, "-row-mt", "1")
+	}
+	add("-tile-columns", strconv.Itoa(o.TileColumns))
+	add("-tile-rows", strconv.Itoa(o.TileRows))
+	add("-lag-in-frames", strconv.Itoa(o.LagInFrames))
+	if len(o.Params) > 0 {
+		args = append(args, "-aom-params", encoderParamsValue(o.Params))
+	}
+	return args
+}
+
+// SVTAV1Options are libsvtav1 settings. Tune and film grain go through -svtav1-params.
+type SVTAV1Options struct {
+	Preset    int    // 1..13, 0 keeps the encoder default
+	Tune      string // vq, psnr or ssim
+	Profile   string // main, high or professional
+	Keyint    int
+	FilmGrain int // synthesis strength, 0..50
+	Params    map[string]string
+}
+
+func (o SVTAV1Options) forEncoder(encoder string) (OutputFlagParser, error) {
+	if err := requireEncoder("svt-av1 options", encoder, "libsvtav1"); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Validate validates the enumerated settings and numeric ranges
+func (o SVTAV1Options) Validate() error {
+	if o.Preset < 0 || o.Preset > 13 {
+		return fmt.Errorf("svt-av1 preset must be between 0 and 13, got %d", o.Preset)
+	}
+	if _, ok := svtAV1Tunes[o.Tune]; o.Tune != "" && !ok {
+		return fmt.Errorf("unknown svt-av1 tune %q, expected one of vq, psnr, ssim", o.Tune)
+	}
+	if err := oneOf("AV1 profile", o.Profile, av1Profiles); err != nil {
+		return err
+	}
+	if o.Keyint < 0 {
+		return fmt.Errorf("svt-av1 keyint must be non-negative, got %d", o.Keyint)
+	}
+	if o.FilmGrain < 0 || o.FilmGrain > 50 {
+		return fmt.Errorf("svt-av1 film grain must be between 0 and 50, got %d", o.FilmGrain)
+	}
+	return validateEncoderParams(o.Params)
+}
+
+// Parse returns the libsvtav1 flags followed by -svtav1-params
+func (o SVTAV1Options) Parse() []string {
+	var args []string
+	if o.Preset > 0 {
+		args = append(args, "-preset", strconv.Itoa(o.Preset))
+	}
+	if o.Profile != "" {
+		args = append(args, "-profile:v", o.Profile)
+	}
+	if o.Keyint > 0 {
+		args = append(args, "-g", strconv.Itoa(o.Keyint))
+	}
+
+	params := map[string]string{}
+	for k, v := range o.Params {
+		params[k] = v
+	}
+	if o.Tune != "" {
+		params["tune"] = strconv.Itoa(svtAV1Tunes[o.Tune])
+	}
+	if o.FilmGrain > 0 {
+		params["film-grain"] = strconv.Itoa(o.FilmGrain)
+	}
+	if len(params) > 0 {
+		args = append(args, "-svtav1-params", encoderParamsValue(params))
+	}
+	return args
+}
+
+// WithX264Options creates a new libx264 option set flag
+func WithX264Options(options X264Options) OutputFlagFn {
+	return func(descriptor *OutputDescriptor) {
+		descriptor.Add(options)
+	}
+}
+
+// WithX265Options creates a new libx265 option set flag
+func WithX265Options(options X265Options) OutputFlagFn {
+	return func(descriptor *OutputDescriptor) {
+		descriptor.Add(options)
+	}
+}
+
+// WithVP9Options creates a new libvpx-vp9 option set flag
+func WithVP9Options(options VP9Options) OutputFlagFn {
+	return func(descriptor *OutputDescriptor) {
+		descriptor.Add(options)
+	}
+}
+
+// WithAV1Options creates a new libaom-av1 option set flag
+func WithAV1Options(options AV1Options) OutputFlagFn {
+	return func(descriptor *OutputDescriptor) {
+		descriptor.Add(options)
+	}
+}
+
+// WithSVTAV1Options creates a new libsvtav1 option set flag
+func WithSVTAV1Options(options SVTAV1Options) OutputFlagFn {
+	return func(descriptor *OutputDescriptor) {
+		descriptor.Add(options)
+	}
+}
+
+// forEncoder checks a raw -preset against the encoder: x264/x265 names, SVT-AV1
+// numbers, and no presets at all for libvpx and libaom.
+func (f PresetFlag) forEncoder(encoder string) (OutputFlagParser, error) {
+	switch videoEncoderFamily(encoder) {
+	case familyX264, familyX265:
+		if err := oneOf(encoder+" preset", string(f), x26xPresets); err != nil {
+			return nil, err
+		}
+	case familySVTAV1:
+		if n, err := strconv.Atoi(string(f)); err != nil || n < -1 || n > 13 {
+			return nil, fmt.Errorf("libsvtav1 preset must be a number between -1 and 13, got %q", string(f))
+		}
+	case familyVPX, familyAOM:
+		return nil, fmt.Errorf("%s has no -preset, use -deadline/-usage and -cpu-used", encoder)
+	}
+	return f, nil
+}
@@ -0,0 +1,98 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCodecOptions_Parse(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  OutputFlagParser
+		want string
+	}{
+		{"x264", X264Options{Preset: "slow", Tune: "film", Profile: "high", Level: "4.1", Keyint: 48, MinKeyint: 48, BFrames: 3, Refs: 4, Params: map[string]string{"aq-mode": "3"}},
+			"-preset slow -tune film -profile:v high -level:v 4.1 -g 48 -keyint_min 48 -bf 3 -refs 4 -x264-params aq-mode=3"},
+		{"x265", X265Options{Preset: "medium", Profile: "main10", Level: "5.1", HighTier: true, Keyint: 48, BFrames: 4},
+			"-preset medium -profile:v main10 -x265-params bframes=4:high-tier=1:keyint=48:level-idc=5.1"},
+		{"vp9", VP9Options{Deadline: "good", CPUUsed: 2, Keyint: 120, RowMT: true, TileColumns: 2, AutoAltRef: true},
+			"-deadline good -cpu-used 2 -g 120 -row-mt 1 -tile-columns 2 -auto-alt-ref 1"},
+		{"aom", AV1Options{Usage: "good", CPUUsed: 6, RowMT: true, TileColumns: 1, TileRows: 1},
+			"-usage good -cpu-used 6 -row-mt 1 -tile-columns 1 -tile-rows 1"},
+		{"svt", SVTAV1Options{Preset: 8, Tune: "vq", Keyint: 240, FilmGrain: 8},
+			"-preset 8 -g 240 -svtav1-params film-grain=8:tune=0"},
+	}
+	for _, tt := range tests {
+		if err := tt.opt.Validate(); err != nil {
+			t.Errorf("%s: Validate() error: %v", tt.name, err)
+			continue
+		}
+		if got := strings.Join(tt.opt.Parse(), " "); got != tt.want {
+			t.Errorf("%s: Parse() =\n %q\nwant\n %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCodecOptions_Validate(t *testing.T) {
+	invalid := map[string]OutputFlagParser{
+		"x264 preset":     X264Options{Preset: "turbo"},
+		"x264 level":      X264Options{Level: "4.5"},
+		"x264 baseline b": X264Options{Profile: "baseline", BFrames: 2},
+		"x264 keyint":     X264Options{Keyint: 24, MinKeyint: 48},
+		"x264 params":     X264Options{Params: map[string]string{"a:b": "1"}},
+		"x265 profile":    X265Options{Profile: "high"},
+		"x265 tier":       X265Options{HighTier: true},
+		"vp9 deadline":    VP9Options{Deadline: "veryslow"},
+		"vp9 cpu-used":    VP9Options{CPUUsed: 9},
+		"aom usage":       AV1Options{Usage: "best"},
+		"svt tune":        SVTAV1Options{Tune: "film"},
+		"svt preset":      SVTAV1Options{Preset: 14},
+	}
+	for name, opt := range invalid {
+		if err := opt.Validate(); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
+
+func TestCodecOptions_EncoderBinding(t *testing.T) {
+	args, err := NewOutputBuilder().
+		WithFlag(VideoCodecH264).
+		WithFlag(WithX264Options(X264Options{Preset: "veryslow", Params: map[string]string{"aq-mode": "3"}})).
+		WithFlag(WithRateControl(ConstantBitrate("3M", "6M"))).
+		File("out.ts").
+		Build().
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	want := "-c:v libx264 -preset veryslow -x264-params aq-mode=3:nal-hrd=cbr -b:v 3M -minrate 3M -maxrate 3M -bufsize 6M out.ts"
+	if got := strings.Join(args, " "); got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+
+	mismatches := map[string][]OutputFlagFn{
+		"preset on vp9":    {VideoCodecVP9, PresetVeryslow},
+		"x264 on x265":     {VideoCodecH265, WithX264Options(X264Options{Preset: "fast"})},
+		"no codec":         {WithVP9Options(VP9Options{Deadline: "good"})},
+		"svt named preset": {WithVideoCodec("libsvtav1"), PresetFast},
+		"double cpu-used":  {VideoCodecAV1, WithAV1Options(AV1Options{CPUUsed: 4}), WithRateControl(RateControl{Mode: RateCRF, CRF: 30, CPUUsed: 6})},
+	}
+	for name, flags := range mismatches {
+		b := NewOutputBuilder()
+		for _, flag := range flags {
+			b.WithFlag(flag)
+		}
+		if _, err := b.File("out").Build().Build(); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+
+	// the stream codec form selects the encoder too
+	if _, err := NewOutputBuilder().
+		WithFlag(WithStreamCodec(VideoStream(0), "libx265")).
+		WithFlag(WithX265Options(X265Options{Preset: "slow"})).
+		File("out.mp4").Build().Build(); err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+}
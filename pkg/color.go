@@ -0,0 +1,602 @@
+package ffmpego
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Values accepted by -color_primaries, -color_trc, -colorspace and -color_range.
+var (
+	colorPrimaries = []string{"bt709", "bt470m", "bt470bg", "smpte170m", "smpte240m", "film", "bt2020",
+		"smpte428", "smpte431", "smpte432", "jedec-p22"}
+	colorTransfers = []string{"bt709", "gamma22", "gamma28", "smpte170m", "smpte240m", "linear", "log100",
+		"log316", "iec61966-2-4", "bt1361e", "iec61966-2-1", "bt2020-10", "bt2020-12", "smpte2084",
+		"smpte428", "arib-std-b67"}
+	colorSpaces = []string{"rgb", "bt709", "fcc", "bt470bg", "smpte170m", "smpte240m", "ycgco", "bt2020nc",
+		"bt2020c", "smpte2085", "chroma-derived-nc", "chroma-derived-c", "ictcp"}
+	colorRanges     = []string{"tv", "pc", "mpeg", "jpeg"}
+	tonemapAlgos    = []string{"none", "clip", "linear", "gamma", "reinhard", "hable", "mobius"}
+	colorspaceAlls  = []string{"bt470m", "bt470bg", "bt601-6-525", "bt601-6-625", "bt709", "smpte170m", "smpte240m", "bt2020"}
+	zscaleTransfers = []string{"bt709", "601", "linear", "2020_10", "2020_12", "smpte2084", "arib-std-b67", "iec61966-2-1"}
+	zscaleMatrices  = []string{"bt709", "fcc", "bt470bg", "170m", "2020_ncl", "2020_cl", "gbr"}
+	zscalePrimaries = []string{"bt709", "170m", "240m", "2020", "smpte432"}
+	zscaleRanges    = []string{"tv", "pc", "limited", "full"}
+
+	// the colorspace filter has its own option tables: gbr rather than rgb, and no PQ, HLG or ICtCp
+	colorspaceSpaces    = []string{"bt709", "fcc", "bt470bg", "smpte170m", "smpte240m", "ycgco", "gbr", "bt2020nc", "bt2020ncl"}
+	colorspaceTransfers = []string{"bt709", "bt470m", "bt470bg", "gamma22", "gamma28", "smpte170m", "smpte240m", "linear",
+		"srgb", "iec61966-2-1", "xvycc", "iec61966-2-4", "bt2020-10", "bt2020-12"}
+	colorspacePrimaries = []string{"bt709", "bt470m", "bt470bg", "smpte170m", "smpte240m", "smpte428", "film", "smpte431",
+		"smpte432", "bt2020", "jedec-p22", "ebu3213"}
+	colorspaceFormats = []string{"yuv420p", "yuv420p10", "yuv420p12", "yuv422p", "yuv422p10", "yuv422p12", "yuv444p",
+		"yuv444p10", "yuv444p12"}
+)
+
+// PixelFormatFlag represents an output pixel format option, e.g. "-pix_fmt yuv420p"
+type PixelFormatFlag string
+
+// Parse returns the pixel format flag arguments
+func (f PixelFormatFlag) Parse() []string {
+	return []string{"-pix_fmt", string(f)}
+}
+
+// Validate validates the pixel format flag
+func (f PixelFormatFlag) Validate() error {
+	if f == "" || strings.ContainsAny(string(f), " \t") {
+		return fmt.Errorf("pixel format must be a single format name, got %q", string(f))
+	}
+	return nil
+}
+
+// ColorPrimariesFlag represents a -color_primaries option
+type ColorPrimariesFlag string
+
+// Parse returns the color primaries flag arguments
+func (f ColorPrimariesFlag) Parse() []string {
+	return []string{"-color_primaries", string(f)}
+}
+
+// Validate validates the color primaries flag
+func (f ColorPrimariesFlag) Validate() error {
+	if f == "" {
+		return fmt.Errorf("color primaries cannot be empty")
+	}
+	return oneOf("color primaries", string(f), colorPrimaries)
+}
+
+// ColorTransferFlag represents a -color_trc option
+type ColorTransferFlag string
+
+// Parse returns the color transfer flag arguments
+func (f ColorTransferFlag) Parse() []string {
+	return []string{"-color_trc", string(f)}
+}
+
+// Validate validates the color transfer flag
+func (f ColorTransferFlag) Validate() error {
+	if f == "" {
+		return fmt.Errorf("color transfer cannot be empty")
+	}
+	return oneOf("color transfer", string(f), colorTransfers)
+}
+
+// ColorSpaceFlag represents a -colorspace (matrix coefficients) option
+type ColorSpaceFlag string
+
+// Parse returns the colorspace flag arguments
+func (f ColorSpaceFlag) Parse() []string {
+	return []string{"-colorspace", string(f)}
+}
+
+// Validate validates the colorspace flag
+func (f ColorSpaceFlag) Validate() error {
+	if f == "" {
+		return fmt.Errorf("colorspace cannot be empty")
+	}
+	return oneOf("colorspace", string(f), colorSpaces)
+}
+
+// ColorRangeFlag represents a -color_range option
+type ColorRangeFlag string
+
+// Parse returns the color range flag arguments
+func (f ColorRangeFlag) Parse() []string {
+	return []string{"-color_range", string(f)}
+}
+
+// Validate validates the color range flag
+func (f ColorRangeFlag) Validate() error {
+	if f == "" {
+		return fmt.Errorf("color range cannot be empty")
+	}
+	return oneOf("color range", string(f), colorRanges)
+}
+
+// WithPixelFormat creates a new pixel format flag, e.g. "yuv420p" for web playback
+func WithPixelFormat(format string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(PixelFormatFlag(format))
+	}
+}
+
+// WithColorPrimaries creates a new color primaries flag, e.g. "bt709"
+func WithColorPrimaries(primaries string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(ColorPrimariesFlag(primaries))
+	}
+}
+
+// WithColorTransfer creates a new color transfer flag, e.g. "smpte2084"
+func WithColorTransfer(transfer string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(ColorTransferFlag(transfer))
+	}
+}
+
+// WithColorSpace creates a new colorspace flag, e.g. "bt2020nc"
+func WithColorSpace(space string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(ColorSpaceFlag(space))
+	}
+}
+
+// WithColorRange creates a new color range flag, "tv" or "pc"
+func WithColorRange(colorRange string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(ColorRangeFlag(colorRange))
+	}
+}
+
+// ColorInfo is the color description of a video stream, using ffmpeg's names.
+type ColorInfo struct {
+	Primaries string
+	Transfer  string
+	Space     string
+	Range     string
+}
+
+// SDRColor is the BT.709 limited range signalling expected by web players.
+var SDRColor = ColorInfo{Primaries: "bt709", Transfer: "bt709", Space: "bt709", Range: "tv"}
+
+// ffprobe reports some values under other names than the -color_primaries, -color_trc and
+// -colorspace options accept.
+var (
+	probePrimaries = map[string]string{"ebu3213": "jedec-p22"}
+	probeTransfers = map[string]string{"bt470m": "gamma22", "bt470bg": "gamma28"}
+	probeSpaces    = map[string]string{"gbr": "rgb"}
+)
+
+// ColorInfoFrom reads the color description of a probed stream, translating ffprobe's
+// names to the option names and dropping "unknown" and other values without one.
+func ColorInfoFrom(stream ProbeStream) ColorInfo {
+	option := func(value string, names map[string]string, allowed []string) string {
+		if name, ok := names[value]; ok {
+			value = name
+		}
+		if oneOf("", value, allowed) != nil {
+			return ""
+		}
+		return value
+	}
+	return ColorInfo{
+		Primaries: option(stream.ColorPrimaries, probePrimaries, colorPrimaries),
+		Transfer:  option(stream.ColorTransfer, probeTransfers, colorTransfers),
+		Space:     option(stream.ColorSpace, probeSpaces, colorSpaces),
+		Range:     option(stream.ColorRange, nil, colorRanges),
+	}
+}
+
+// IsHDR reports whether the transfer is PQ (HDR10) or HLG.
+func (c ColorInfo) IsHDR() bool {
+	return c.Transfer == "smpte2084" || c.Transfer == "arib-std-b67"
+}
+
+// WithColorInfo adds a flag for every non-empty field of info.
+func WithColorInfo(info ColorInfo) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		if info.Primaries != "" {
+			options.Add(ColorPrimariesFlag(info.Primaries))
+		}
+		if info.Transfer != "" {
+			options.Add(ColorTransferFlag(info.Transfer))
+		}
+		if info.Space != "" {
+			options.Add(ColorSpaceFlag(info.Space))
+		}
+		if info.Range != "" {
+			options.Add(ColorRangeFlag(info.Range))
+		}
+	}
+}
+
+// MasteringDisplay is SMPTE ST 2086 mastering display metadata in the x265 units:
+// chromaticity in 0.00002 steps, luminance in 0.0001 cd/m².
+type MasteringDisplay struct {
+	RedX, RedY     int
+	GreenX, GreenY int
+	BlueX, BlueY   int
+	WhiteX, WhiteY int
+	MaxLuminance   int
+	MinLuminance   int
+}
+
+// String renders the x265 master-display value: "G(x,y)B(x,y)R(x,y)WP(x,y)L(max,min)".
+func (m MasteringDisplay) String() string {
+	return fmt.Sprintf("G(%d,%d)B(%d,%d)R(%d,%d)WP(%d,%d)L(%d,%d)",
+		m.GreenX, m.GreenY, m.BlueX, m.BlueY, m.RedX, m.RedY, m.WhiteX, m.WhiteY, m.MaxLuminance, m.MinLuminance)
+}
+
+// svtString renders the SVT-AV1 mastering-display value, which uses decimal values.
+func (m MasteringDisplay) svtString() string {
+	xy := func(x, y int) string {
+		return fmt.Sprintf("(%.4f,%.4f)", float64(x)/50000, float64(y)/50000)
+	}
+	return fmt.Sprintf("G%sB%sR%sWP%sL(%.4f,%.4f)", xy(m.GreenX, m.GreenY), xy(m.BlueX, m.BlueY), xy(m.RedX, m.RedY),
+		xy(m.WhiteX, m.WhiteY), float64(m.MaxLuminance)/10000, float64(m.MinLuminance)/10000)
+}
+
+// Validate validates chromaticity ranges and the luminance order
+func (m MasteringDisplay) Validate() error {
+	for _, v := range []int{m.RedX, m.RedY, m.GreenX, m.GreenY, m.BlueX, m.BlueY, m.WhiteX, m.WhiteY} {
+		if v < 0 || v > 50000 {
+			return fmt.Errorf("mastering display chromaticity must be between 0 and 50000, got %d", v)
+		}
+	}
+	if m.MinLuminance < 0 || m.MaxLuminance <= m.MinLuminance {
+		return fmt.Errorf("mastering display max luminance %d must exceed min luminance %d", m.MaxLuminance, m.MinLuminance)
+	}
+	return nil
+}
+
+// HDR10Metadata is the static HDR10 metadata written into the bitstream.
+type HDR10Metadata struct {
+	MasteringDisplay *MasteringDisplay
+	MaxCLL           int // maximum content light level, cd/m²
+	MaxFALL          int // maximum frame average light level, cd/m²
+}
+
+// HDR10MetadataFrom reads mastering display and content light level side data from a
+// probed stream. It returns false when the stream carries neither.
+func HDR10MetadataFrom(stream ProbeStream) (HDR10Metadata, bool) {
+	var meta HDR10Metadata
+	found := false
+	scaled := func(value string, scale float64) int {
+		num, den, err := parseRational(value)
+		if err != nil {
+			return 0
+		}
+		return int(math.Round(float64(num) / float64(den) * scale))
+	}
+
+	for _, sd := range stream.SideDataList {
+		switch sd.SideDataType {
+		case "Mastering display metadata":
+			if sd.RedX == "" || sd.MaxLuminance == "" {
+				continue
+			}
+			meta.MasteringDisplay = &MasteringDisplay{
+				RedX: scaled(sd.RedX, 50000), RedY: scaled(sd.RedY, 50000),
+				GreenX: scaled(sd.GreenX, 50000), GreenY: scaled(sd.GreenY, 50000),
+				BlueX: scaled(sd.BlueX, 50000), BlueY: scaled(sd.BlueY, 50000),
+				WhiteX: scaled(sd.WhitePointX, 50000), WhiteY: scaled(sd.WhitePointY, 50000),
+				MaxLuminance: scaled(sd.MaxLuminance, 10000), MinLuminance: scaled(sd.MinLuminance, 10000),
+			}
+			found = true
+		case "Content light level metadata":
+			meta.MaxCLL, meta.MaxFALL = sd.MaxContent, sd.MaxAverage
+			found = true
+		}
+	}
+	return meta, found
+}
+
+// HDR10Flag signals HDR10 static metadata through the encoder parameters of libx265
+// (master-display, max-cll) or libsvtav1 (mastering-display, content-light).
+type HDR10Flag struct {
+	Metadata HDR10Metadata
+	encoder  string
+}
+
+func (f HDR10Flag) forEncoder(encoder string) (OutputFlagParser, error) {
+	if err := requireEncoder("HDR10 metadata", encoder, "libx265", "libsvtav1"); err != nil {
+		return nil, err
+	}
+	f.encoder = encoder
+	return f, nil
+}
+
+// Parse returns the encoder params carrying the metadata
+func (f HDR10Flag) Parse() []string {
+	var params []string
+	if f.encoder == "libsvtav1" {
+		if f.Metadata.MasteringDisplay != nil {
+			params = append(params, "mastering-display="+f.Metadata.MasteringDisplay.svtString())
+		}
+		if f.Metadata.MaxCLL > 0 {
+			params = append(params, fmt.Sprintf("content-light=%d,%d", f.Metadata.MaxCLL, f.Metadata.MaxFALL))
+		}
+		return []string{"-svtav1-params", strings.Join(params, ":")}
+	}
+
+	params = append(params, "hdr10=1")
+	if f.Metadata.MasteringDisplay != nil {
+		params = append(params, "master-display="+f.Metadata.MasteringDisplay.String())
+	}
+	if f.Metadata.MaxCLL > 0 {
+		params = append(params, fmt.Sprintf("max-cll=%d,%d", f.Metadata.MaxCLL, f.Metadata.MaxFALL))
+	}
+	return []string{"-x265-params", strings.Join(params, ":")}
+}
+
+// Validate validates the metadata
+func (f HDR10Flag) Validate() error {
+	if f.Metadata.MasteringDisplay == nil && f.Metadata.MaxCLL == 0 {
+		return fmt.Errorf("HDR10 metadata needs a mastering display or content light level")
+	}
+	if f.Metadata.MasteringDisplay != nil {
+		if err := f.Metadata.MasteringDisplay.Validate(); err != nil {
+			return err
+		}
+	}
+	if f.Metadata.MaxCLL < 0 || f.Metadata.MaxFALL < 0 || f.Metadata.MaxFALL > f.Metadata.MaxCLL {
+		return fmt.Errorf("HDR10 MaxFALL %d must be between 0 and MaxCLL %d", f.Metadata.MaxFALL, f.Metadata.MaxCLL)
+	}
+	return nil
+}
+
+// WithHDR10 creates a new HDR10 static metadata flag for libx265 or libsvtav1 outputs
+func WithHDR10(metadata HDR10Metadata) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(HDR10Flag{Metadata: metadata})
+	}
+}
+
+// ColorPlan is the pixel format and color signalling of an output derived from its source.
+type ColorPlan struct {
+	PixelFormat string
+	Color       ColorInfo
+	HDR10       *HDR10Metadata
+	// Tonemap is set when an HDR source goes to an SDR output; add a tonemapping chain
+	// (see HDRToSDR) before encoding.
+	Tonemap bool
+}
+
+// PlanColor derives the output color settings from a probed video stream. HDR sources
+// keep their signalling and HDR10 metadata in 10-bit when keepHDR is set; every other
+// output gets yuv420p, with BT.709 signalling after tonemapping and the source's own
+// tags for SDR sources.
+func PlanColor(stream ProbeStream, keepHDR bool) ColorPlan {
+	info := ColorInfoFrom(stream)
+	if info.IsHDR() && keepHDR {
+		plan := ColorPlan{PixelFormat: "yuv420p10le", Color: info}
+		if plan.Color.Range == "" {
+			plan.Color.Range = "tv"
+		}
+		if meta, ok := HDR10MetadataFrom(stream); ok && info.Transfer == "smpte2084" {
+			plan.HDR10 = &meta
+		}
+		return plan
+	}
+	if info.IsHDR() {
+		return ColorPlan{PixelFormat: "yuv420p", Color: SDRColor, Tonemap: true}
+	}
+	return ColorPlan{PixelFormat: "yuv420p", Color: info}
+}
+
+// WithColorPlan adds the pixel format, color flags and HDR10 metadata of plan.
+func WithColorPlan(plan ColorPlan) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		if plan.PixelFormat != "" {
+			options.Add(PixelFormatFlag(plan.PixelFormat))
+		}
+		WithColorInfo(plan.Color)(options)
+		if plan.HDR10 != nil {
+			options.Add(HDR10Flag{Metadata: *plan.HDR10})
+		}
+	}
+}
+
+// ColorspaceFilter renders: "[input]colorspace=all=bt709:format=yuv420p[output]". The
+// colorspace filter converts between SDR matrices/primaries; it cannot tonemap HDR.
+type ColorspaceFilter struct {
+	Input     string
+	Output    string
+	All       string // bt709, bt601-6-625, bt2020, ...
+	Space     string // overrides of All; the filter's names, e.g. gbr rather than rgb
+	Transfer  string
+	Primaries string
+	Range     string
+	Format    string // output pixel format, yuv420p, yuv422p10, ...
+}
+
+func (f ColorspaceFilter) Validate() error {
+	if strings.TrimSpace(f.Input) == "" || strings.TrimSpace(f.Output) == "" {
+		return fmt.Errorf("colorspace: input and output labels cannot be empty")
+	}
+	if f.All == "" && f.Space == "" && f.Transfer == "" && f.Primaries == "" && f.Range == "" && f.Format == "" {
+		return fmt.Errorf("colorspace: at least one target property is required")
+	}
+	for _, err := range []error{
+		oneOf("colorspace preset", f.All, colorspaceAlls),
+		oneOf("colorspace space", f.Space, colorspaceSpaces),
+		oneOf("colorspace transfer", f.Transfer, colorspaceTransfers),
+		oneOf("colorspace primaries", f.Primaries, colorspacePrimaries),
+		oneOf("colorspace range", f.Range, colorRanges),
+		oneOf("colorspace format", f.Format, colorspaceFormats),
+	} {
+		if err != nil {
+			return fmt.Errorf("colorspace: %w", err)
+		}
+	}
+	return nil
+}
+
+func (f ColorspaceFilter) Parse() string {
+	var opts []string
+	add := func(key, value string) {
+		if value != "" {
+			opts = append(opts, key+"="+value)
+		}
+	}
+	add("all", f.All)
+	add("space", f.Space)
+	add("trc", f.Transfer)
+	add("primaries", f.Primaries)
+	add("range", f.Range)
+	add("format", f.Format)
+	return fmt.Sprintf("[%s]colorspace=%s[%s]", f.Input, strings.Join(opts, ":"), f.Output)
+}
+
+// ZScaleFilter renders: "[input]zscale=t=bt709:m=bt709:p=bt709:r=tv[output]", a
+// zimg based conversion that also handles PQ and HLG transfers.
+type ZScaleFilter struct {
+	Input     string
+	Output    string
+	Transfer  string // t: bt709, linear, smpte2084, arib-std-b67, ...
+	Matrix    string // m: bt709, 2020_ncl, ...
+	Primaries string // p: bt709, 2020, ...
+	Range     string // r: tv or pc
+	NPL       float64
+}
+
+func (f ZScaleFilter) Validate() error {
+	if strings.TrimSpace(f.Input) == "" || strings.TrimSpace(f.Output) == "" {
+		return fmt.Errorf("zscale: input and output labels cannot be empty")
+	}
+	if f.Transfer == "" && f.Matrix == "" && f.Primaries == "" && f.Range == "" {
+		return fmt.Errorf("zscale: at least one target property is required")
+	}
+	if f.NPL < 0 {
+		return fmt.Errorf("zscale: nominal peak luminance must be non-negative, got %v", f.NPL)
+	}
+	for _, err := range []error{
+		oneOf("zscale transfer", f.Transfer, zscaleTransfers),
+		oneOf("zscale matrix", f.Matrix, zscaleMatrices),
+		oneOf("zscale primaries", f.Primaries, zscalePrimaries),
+		oneOf("zscale range", f.Range, zscaleRanges),
+	} {
+		if err != nil {
+			return fmt.Errorf("zscale: %w", err)
+		}
+	}
+	return nil
+}
+
+func (f ZScaleFilter) Parse() string {
+	return fmt.Sprintf("[%s]%s[%s]", f.Input, f.args(), f.Output)
+}
+
+// args renders the unlabeled zscale filter
+func (f ZScaleFilter) args() string {
+	var opts []string
+	add := func(key, value string) {
+		if value != "" {
+			opts = append(opts, key+"="+value)
+		}
+	}
+	add("t", f.Transfer)
+	add("m", f.Matrix)
+	add("p", f.Primaries)
+	add("r", f.Range)
+	if f.NPL > 0 {
+		add("npl", formatFloat(f.NPL))
+	}
+	return "zscale=" + strings.Join(opts, ":")
+}
+
+// TonemapFilter renders the zscale/tonemap chain converting HDR (PQ or HLG) to SDR BT.709:
+//
+//	[in]zscale=t=linear:npl=100,format=gbrpf32le,zscale=p=bt709,tonemap=tonemap=hable:desat=0,
+//	zscale=t=bt709:m=bt709:r=tv,format=yuv420p[out]
+type TonemapFilter struct {
+	Input     string
+	Output    string
+	Algorithm string  // hable, mobius, reinhard, clip, linear, gamma or none
+	NPL       float64 // nominal peak luminance of the SDR target, 100 when zero
+	Desat     float64
+	Format    string // output pixel format, yuv420p when empty
+}
+
+func (f TonemapFilter) Validate() error {
+	if strings.TrimSpace(f.Input) == "" || strings.TrimSpace(f.Output) == "" {
+		return fmt.Errorf("tonemap: input and output labels cannot be empty")
+	}
+	if f.Algorithm == "" {
+		return fmt.Errorf("tonemap: algorithm cannot be empty")
+	}
+	if err := oneOf("tonemap algorithm", f.Algorithm, tonemapAlgos); err != nil {
+		return fmt.Errorf("tonemap: %w", err)
+	}
+	if f.NPL < 0 || f.Desat < 0 {
+		return fmt.Errorf("tonemap: npl and desat must be non-negative")
+	}
+	return nil
+}
+
+func (f TonemapFilter) Parse() string {
+	npl := f.NPL
+	if npl == 0 {
+		npl = 100
+	}
+	format := f.Format
+	if format == "" {
+		format = "yuv420p"
+	}
+
+	chain := []string{
+		ZScaleFilter{Transfer: "linear", NPL: npl}.args(),
+		"format=gbrpf32le",
+		ZScaleFilter{Primaries: "bt709"}.args(),
+		fmt.Sprintf("tonemap=tonemap=%s:desat=%s", f.Algorithm, formatFloat(f.Desat)),
+		ZScaleFilter{Transfer: "bt709", Matrix: "bt709", Range: "tv"}.args(),
+		"format=" + format,
+	}
+	return fmt.Sprintf("[%s]%s[%s]", f.Input, strings.Join(chain, ","), f.Output)
+}
+
+// WithColorspace adds a labeled colorspace chain converting to the all preset and pixel format, e.g. ("bt709", "yuv420p").
+func WithColorspace(input string, output string, all string, format string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(ColorspaceFilter{
+			Input:  strings.TrimSpace(input),
+			Output: strings.TrimSpace(output),
+			All:    all,
+			Format: format,
+		})
+	}
+}
+
+// WithZScale adds a labeled zscale chain converting to the given transfer, matrix, primaries and range.
+func WithZScale(input string, output string, transfer, matrix, primaries, colorRange string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(ZScaleFilter{
+			Input:     strings.TrimSpace(input),
+			Output:    strings.TrimSpace(output),
+			Transfer:  transfer,
+			Matrix:    matrix,
+			Primaries: primaries,
+			Range:     colorRange,
+		})
+	}
+}
+
+// WithTonemap adds a labeled HDR to SDR tonemapping chain using algorithm, e.g. "hable".
+func WithTonemap(input string, output string, algorithm string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(TonemapFilter{
+			Input:     strings.TrimSpace(input),
+			Output:    strings.TrimSpace(output),
+			Algorithm: algorithm,
+		})
+	}
+}
+
+// HDRToSDR returns the tonemapping chain for an HDR source when the ffmpeg build has the
+// zscale and tonemap filters (zscale needs --enable-libzimg), and an error otherwise.
+func HDRToSDR(caps *Capabilities, input string, output string, algorithm string) (FilterFn, error) {
+	if !caps.HasFilter("zscale") || !caps.HasFilter("tonemap") {
+		return nil, fmt.Errorf("tonemapping requires the zscale and tonemap filters, which this ffmpeg build lacks")
+	}
+	return WithTonemap(input, output, algorithm), nil
+}
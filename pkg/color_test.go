@@ -0,0 +1,182 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+)
+
+func hdr10Stream() ProbeStream {
+	return ProbeStream{
+		CodecType:      "video",
+		PixFmt:         "yuv420p10le",
+		ColorRange:     "tv",
+		ColorSpace:     "bt2020nc",
+		ColorTransfer:  "smpte2084",
+		ColorPrimaries: "bt2020",
+		SideDataList: []ProbeSideData{
+			{
+				SideDataType: "Mastering display metadata",
+				RedX:         "34000/50000", RedY: "16000/50000",
+				GreenX: "13250/50000", GreenY: "34500/50000",
+				BlueX: "7500/50000", BlueY: "3000/50000",
+				WhitePointX: "15635/50000", WhitePointY: "16450/50000",
+				MinLuminance: "50/10000", MaxLuminance: "10000000/10000",
+			},
+			{SideDataType: "Content light level metadata", MaxContent: 1000, MaxAverage: 400},
+		},
+	}
+}
+
+func TestPlanColor_KeepsHDR10(t *testing.T) {
+	plan := PlanColor(hdr10Stream(), true)
+	if plan.Tonemap || plan.HDR10 == nil {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+
+	args, err := NewOutputBuilder().
+		WithFlag(VideoCodecH265).
+		WithFlag(WithColorPlan(plan)).
+		File("out.mp4").
+		Build().
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	want := "-c:v libx265 -pix_fmt yuv420p10le -color_primaries bt2020 -color_trc smpte2084 -colorspace bt2020nc " +
+		"-color_range tv -x265-params hdr10=1:master-display=G(13250,34500)B(7500,3000)R(34000,16000)WP(15635,16450)L(10000000,50):" +
+		"max-cll=1000,400 out.mp4"
+	if got := strings.Join(args, " "); got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+
+	// HDR10 metadata cannot be carried by libx264
+	_, err = NewOutputBuilder().WithFlag(VideoCodecH264).WithFlag(WithColorPlan(plan)).File("out.mp4").Build().Build()
+	if err == nil {
+		t.Fatalf("expected error for HDR10 metadata on libx264, got nil")
+	}
+}
+
+func TestPlanColor_SDR(t *testing.T) {
+	plan := PlanColor(hdr10Stream(), false)
+	if !plan.Tonemap || plan.PixelFormat != "yuv420p" || plan.Color != SDRColor || plan.HDR10 != nil {
+		t.Fatalf("unexpected HDR to SDR plan: %+v", plan)
+	}
+
+	plan = PlanColor(ProbeStream{PixFmt: "yuvj420p", ColorRange: "pc", ColorSpace: "smpte170m", ColorTransfer: "unknown"}, false)
+	want := ColorPlan{PixelFormat: "yuv420p", Color: ColorInfo{Space: "smpte170m", Range: "pc"}}
+	if plan.PixelFormat != want.PixelFormat || plan.Color != want.Color || plan.Tonemap {
+		t.Fatalf("PlanColor() = %+v, want %+v", plan, want)
+	}
+}
+
+func TestColorInfoFrom_ProbeNames(t *testing.T) {
+	// PAL DV as ffprobe reports it
+	pal := ProbeStream{PixFmt: "yuv420p", ColorRange: "tv", ColorSpace: "bt470bg", ColorTransfer: "bt470bg", ColorPrimaries: "bt470bg"}
+	plan := PlanColor(pal, false)
+	want := ColorInfo{Primaries: "bt470bg", Transfer: "gamma28", Space: "bt470bg", Range: "tv"}
+	if plan.Color != want {
+		t.Fatalf("PlanColor() color = %+v, want %+v", plan.Color, want)
+	}
+	args, err := NewOutputBuilder().WithFlag(WithColorPlan(plan)).File("out.mp4").Build().Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if got := strings.Join(args, " "); !strings.Contains(got, "-color_trc gamma28") {
+		t.Fatalf("args %q missing translated transfer", got)
+	}
+
+	rgb := ColorInfoFrom(ProbeStream{ColorSpace: "gbr", ColorTransfer: "bt470m", ColorPrimaries: "ebu3213"})
+	if want := (ColorInfo{Primaries: "jedec-p22", Transfer: "gamma22", Space: "rgb"}); rgb != want {
+		t.Fatalf("ColorInfoFrom() = %+v, want %+v", rgb, want)
+	}
+	if got := ColorInfoFrom(ProbeStream{ColorSpace: "ycgco-re", ColorTransfer: "reserved"}); got != (ColorInfo{}) {
+		t.Fatalf("expected unmappable values to be dropped, got %+v", got)
+	}
+}
+
+func TestHDR10Flag_SVTAV1(t *testing.T) {
+	meta, ok := HDR10MetadataFrom(hdr10Stream())
+	if !ok {
+		t.Fatalf("expected HDR10 metadata")
+	}
+	args, err := NewOutputBuilder().
+		WithFlag(WithVideoCodec("libsvtav1")).
+		WithFlag(WithHDR10(meta)).
+		Build().
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	want := "-c:v libsvtav1 -svtav1-params mastering-display=G(0.2650,0.6900)B(0.1500,0.0600)R(0.6800,0.3200)" +
+		"WP(0.3127,0.3290)L(1000.0000,0.0050):content-light=1000,400"
+	if got := strings.Join(args, " "); got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestColorFlags_Validate(t *testing.T) {
+	invalid := []OutputFlagParser{
+		PixelFormatFlag(""),
+		ColorPrimariesFlag("srgb"),
+		ColorTransferFlag("pq"),
+		ColorSpaceFlag("bt2020"),
+		ColorRangeFlag("full"),
+		HDR10Flag{},
+		HDR10Flag{Metadata: HDR10Metadata{MaxCLL: 400, MaxFALL: 1000}},
+		HDR10Flag{Metadata: HDR10Metadata{MasteringDisplay: &MasteringDisplay{MaxLuminance: 1, MinLuminance: 50}}},
+	}
+	for _, flag := range invalid {
+		if err := flag.Validate(); err == nil {
+			t.Errorf("expected error for %#v, got nil", flag)
+		}
+	}
+}
+
+func TestColorFilters_Parse(t *testing.T) {
+	graph, err := NewComplexFilterBuilder().
+		Add(WithColorspace("0:v", "c601", "bt709", "yuv420p")).
+		Add(WithZScale("c601", "hlg", "arib-std-b67", "2020_ncl", "2020", "tv")).
+		Add(WithTonemap("1:v", "sdr", "hable")).
+		Build().
+		BuildAndValidate()
+	if err != nil {
+		t.Fatalf("BuildAndValidate() error: %v", err)
+	}
+	for _, want := range []string{
+		"[0:v]colorspace=all=bt709:format=yuv420p[c601]",
+		"[c601]zscale=t=arib-std-b67:m=2020_ncl:p=2020:r=tv[hlg]",
+		"[1:v]zscale=t=linear:npl=100,format=gbrpf32le,zscale=p=bt709,tonemap=tonemap=hable:desat=0,zscale=t=bt709:m=bt709:r=tv,format=yuv420p[sdr]",
+	} {
+		if !strings.Contains(graph, want) {
+			t.Errorf("graph %q missing %q", graph, want)
+		}
+	}
+
+	if err := (ColorspaceFilter{Input: "a", Output: "b"}).Validate(); err == nil {
+		t.Errorf("expected error for colorspace without target, got nil")
+	}
+	if err := (ColorspaceFilter{Input: "a", Output: "b", Space: "gbr", Transfer: "srgb", Primaries: "ebu3213"}).Validate(); err != nil {
+		t.Errorf("Validate() error for filter-only names: %v", err)
+	}
+	for _, f := range []ColorspaceFilter{
+		{Input: "a", Output: "b", Space: "rgb"},
+		{Input: "a", Output: "b", Space: "ictcp"},
+		{Input: "a", Output: "b", Transfer: "smpte2084"},
+		{Input: "a", Output: "b", Transfer: "arib-std-b67"},
+		{Input: "a", Output: "b", Format: "nv12"},
+	} {
+		if err := f.Validate(); err == nil {
+			t.Errorf("expected error for %#v, got nil", f)
+		}
+	}
+	if err := (TonemapFilter{Input: "a", Output: "b", Algorithm: "aces"}).Validate(); err == nil {
+		t.Errorf("expected error for unknown tonemap algorithm, got nil")
+	}
+
+	if _, err := HDRToSDR(&Capabilities{Filters: map[string]bool{"tonemap": true}}, "0:v", "sdr", "hable"); err == nil {
+		t.Errorf("expected error without zscale, got nil")
+	}
+	if _, err := HDRToSDR(&Capabilities{Filters: map[string]bool{"tonemap": true, "zscale": true}}, "0:v", "sdr", "hable"); err != nil {
+		t.Errorf("HDRToSDR() error: %v", err)
+	}
+}
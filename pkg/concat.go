@@ -0,0 +1,484 @@
+package ffmpego
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ConcatStrategy selects how Concat joins its clips.
+type ConcatStrategy int
+
+const (
+	// ConcatStrategyAuto uses the demuxer when every clip has matching streams, the filter otherwise.
+	ConcatStrategyAuto ConcatStrategy = iota
+	// ConcatStrategyDemuxer stream copies through the concat demuxer; clips must share codecs and parameters.
+	ConcatStrategyDemuxer
+	// ConcatStrategyFilter decodes, normalizes and re-encodes every clip through the concat filter.
+	ConcatStrategyFilter
+)
+
+func (s ConcatStrategy) String() string {
+	switch s {
+	case ConcatStrategyDemuxer:
+		return "demuxer"
+	case ConcatStrategyFilter:
+		return "filter"
+	default:
+		return "auto"
+	}
+}
+
+// ConcatClip is one input of a Concat. InPoint, OutPoint and Duration are optional;
+// with the demuxer they cut at the nearest preceding keyframe.
+type ConcatClip struct {
+	Path     string
+	InPoint  time.Duration
+	OutPoint time.Duration
+	Duration time.Duration
+}
+
+// Validate validates the clip path and trim points
+func (c ConcatClip) Validate() error {
+	if strings.TrimSpace(c.Path) == "" {
+		return fmt.Errorf("concat: clip path cannot be empty")
+	}
+	if strings.ContainsAny(c.Path, "\n\r") {
+		return fmt.Errorf("concat: clip path %q cannot contain line breaks", c.Path)
+	}
+	if c.InPoint < 0 || c.OutPoint < 0 || c.Duration < 0 {
+		return fmt.Errorf("concat: trim points of %q must be non-negative", c.Path)
+	}
+	if c.OutPoint > 0 && c.OutPoint <= c.InPoint {
+		return fmt.Errorf("concat: outpoint of %q must be after its inpoint", c.Path)
+	}
+	return nil
+}
+
+// length returns the trimmed clip length, or 0 when the clip plays to its end.
+func (c ConcatClip) length() time.Duration {
+	if c.OutPoint > 0 {
+		return c.OutPoint - c.InPoint
+	}
+	return c.Duration
+}
+
+// WriteConcatList writes a concat demuxer script with one quoted "file" line per clip
+// followed by its inpoint, outpoint and duration directives.
+func WriteConcatList(w io.Writer, clips []ConcatClip) error {
+	var b strings.Builder
+	b.WriteString("ffconcat version 1.0\n")
+	for _, clip := range clips {
+		if err := clip.Validate(); err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "file %s\n", quoteConcatPath(clip.Path))
+		if clip.InPoint > 0 {
+			fmt.Fprintf(&b, "inpoint %s\n", formatSeconds(clip.InPoint))
+		}
+		if clip.OutPoint > 0 {
+			fmt.Fprintf(&b, "outpoint %s\n", formatSeconds(clip.OutPoint))
+		}
+		if clip.Duration > 0 {
+			fmt.Fprintf(&b, "duration %s\n", formatSeconds(clip.Duration))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// quoteConcatPath single quotes path for the concat script; embedded quotes are
+// closed, escaped and reopened: it's -> 'it'\''s'.
+func quoteConcatPath(path string) string {
+	return "'" + strings.ReplaceAll(path, "'", `'\''`) + "'"
+}
+
+// ConcatSafeFlag represents the concat demuxer -safe input option. Disabling it
+// allows absolute paths and paths with special characters in the list.
+type ConcatSafeFlag bool
+
+func (f ConcatSafeFlag) Validate() error {
+	return nil
+}
+
+func (f ConcatSafeFlag) Parse() []string {
+	return []string{"-safe", boolArg(bool(f))}
+}
+
+// WithConcatSafe sets the concat demuxer '-safe' input option.
+func WithConcatSafe(safe bool) InputFlagFn {
+	return func(input *InputFile) {
+		input.Add(ConcatSafeFlag(safe))
+	}
+}
+
+// ConcatFilter renders: "[v0][a0][v1][a1]concat=n=2:v=1:a=1[outv][outa]"
+// Inputs are ordered by segment, then video streams before audio streams.
+type ConcatFilter struct {
+	Inputs   []string
+	Segments int
+	Video    int
+	Audio    int
+	Outputs  []string
+}
+
+func (f ConcatFilter) Validate() error {
+	if f.Segments < 1 {
+		return fmt.Errorf("concat: segments must be >= 1, got %d", f.Segments)
+	}
+	if f.Video < 0 || f.Audio < 0 || f.Video+f.Audio == 0 {
+		return fmt.Errorf("concat: at least one video or audio stream per segment is required")
+	}
+	if len(f.Inputs) != f.Segments*(f.Video+f.Audio) {
+		return fmt.Errorf("concat: expected %d inputs, got %d", f.Segments*(f.Video+f.Audio), len(f.Inputs))
+	}
+	if len(f.Outputs) != f.Video+f.Audio {
+		return fmt.Errorf("concat: expected %d outputs, got %d", f.Video+f.Audio, len(f.Outputs))
+	}
+	for _, label := range append(append([]string{}, f.Inputs...), f.Outputs...) {
+		if strings.TrimSpace(label) == "" {
+			return fmt.Errorf("concat: labels cannot be empty")
+		}
+	}
+	return nil
+}
+
+func (f ConcatFilter) Parse() string {
+	return fmt.Sprintf("[%s]concat=n=%d:v=%d:a=%d[%s]",
+		strings.Join(f.Inputs, "]["), f.Segments, f.Video, f.Audio, strings.Join(f.Outputs, "]["))
+}
+
+// Concat joins clips into a single output. Build probes the clips and picks the
+// concat demuxer (stream copy) when their streams match, or the concat filter with
+// scale/pad, fps and aresample normalization otherwise.
+type Concat struct {
+	output     string
+	clips      []ConcatClip
+	strategy   ConcatStrategy
+	selected   ConcatStrategy
+	width      int
+	height     int
+	frameRate  string
+	sampleRate int
+	opts       []OutputFlagFn
+	overwrite  bool
+	listFile   string
+}
+
+// NewConcat creates a concat job writing to output.
+func NewConcat(output string) *Concat {
+	return &Concat{output: output, opts: make([]OutputFlagFn, 0)}
+}
+
+// Add appends whole files.
+func (c *Concat) Add(paths ...string) *Concat {
+	for _, path := range paths {
+		c.clips = append(c.clips, ConcatClip{Path: path})
+	}
+	return c
+}
+
+// AddClip appends a trimmed clip.
+func (c *Concat) AddClip(clip ConcatClip) *Concat {
+	c.clips = append(c.clips, clip)
+	return c
+}
+
+// Strategy forces the demuxer or the filter instead of choosing automatically.
+func (c *Concat) Strategy(strategy ConcatStrategy) *Concat {
+	c.strategy = strategy
+	return c
+}
+
+// Normalize sets the filter strategy output format; zero values default to the first clip
+// with video, at its display size.
+func (c *Concat) Normalize(width, height int, frameRate string, sampleRate int) *Concat {
+	c.width, c.height, c.frameRate, c.sampleRate = width, height, frameRate, sampleRate
+	return c
+}
+
+// WithFlag appends an output option, e.g. the codecs used by the filter strategy.
+func (c *Concat) WithFlag(opt OutputFlagFn) *Concat {
+	c.opts = append(c.opts, opt)
+	return c
+}
+
+// Overwrite adds '-y' to the command.
+func (c *Concat) Overwrite() *Concat {
+	c.overwrite = true
+	return c
+}
+
+// Selected returns the strategy picked by the last Build.
+func (c *Concat) Selected() ConcatStrategy {
+	return c.selected
+}
+
+// Build probes every clip and returns the join command. With the demuxer it writes a
+// temporary list file, replacing the one of an earlier Build; call Cleanup once the
+// command has run.
+func (c *Concat) Build(ctx context.Context) (*Ffmpego, error) {
+	probes := make([]*ProbeResult, len(c.clips))
+	for i, clip := range c.clips {
+		probe, err := Probe(ctx, clip.Path)
+		if err != nil {
+			return nil, err
+		}
+		probes[i] = probe
+	}
+
+	return c.build(probes)
+}
+
+// Cleanup removes the list file written by Build, if any.
+func (c *Concat) Cleanup() error {
+	if c.listFile == "" {
+		return nil
+	}
+	err := os.Remove(c.listFile)
+	c.listFile = ""
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("concat: failed to remove list file: %w", err)
+	}
+	return nil
+}
+
+// Run builds, runs and cleans up the join.
+func (c *Concat) Run(ctx context.Context) error {
+	cmd, err := c.Build(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Cleanup()
+
+	return NewRunner(cmd).Run(ctx)
+}
+
+func (c *Concat) build(probes []*ProbeResult) (*Ffmpego, error) {
+	if strings.TrimSpace(c.output) == "" {
+		return nil, fmt.Errorf("concat: output cannot be empty")
+	}
+	if len(c.clips) < 2 {
+		return nil, fmt.Errorf("concat: at least two clips are required, got %d", len(c.clips))
+	}
+	if err := c.Cleanup(); err != nil {
+		return nil, err
+	}
+	for _, clip := range c.clips {
+		if err := clip.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	c.selected = c.strategy
+	if c.selected == ConcatStrategyAuto {
+		c.selected = ConcatStrategyFilter
+		if err := concatCompatible(probes); err == nil {
+			c.selected = ConcatStrategyDemuxer
+		}
+	} else if c.selected == ConcatStrategyDemuxer {
+		if err := concatCompatible(probes); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.selected == ConcatStrategyDemuxer {
+		return c.demuxerCommand()
+	}
+	return c.filterCommand(probes)
+}
+
+func (c *Concat) demuxerCommand() (*Ffmpego, error) {
+	// relative paths in the list resolve against the list file, not the working directory
+	clips := make([]ConcatClip, len(c.clips))
+	for i, clip := range c.clips {
+		abs, err := filepath.Abs(clip.Path)
+		if err != nil {
+			return nil, fmt.Errorf("concat: failed to resolve %q: %w", clip.Path, err)
+		}
+		clip.Path = abs
+		clips[i] = clip
+	}
+
+	list, err := os.CreateTemp("", "ffmpego-concat-*.txt")
+	if err != nil {
+		return nil, fmt.Errorf("concat: failed to create list file: %w", err)
+	}
+	c.listFile = list.Name()
+	if err := WriteConcatList(list, clips); err != nil {
+		list.Close()
+		c.Cleanup()
+		return nil, err
+	}
+	if err := list.Close(); err != nil {
+		c.Cleanup()
+		return nil, fmt.Errorf("concat: failed to write list file: %w", err)
+	}
+
+	flags := []FfmpegFlagFn{WithInputFile(c.listFile, WithInputFormat("concat"), WithConcatSafe(false))}
+	if c.overwrite {
+		flags = append(flags, WithOverwrite())
+	}
+
+	out := NewOutputBuilder().
+		WithFlag(WithMap("0:v?")).
+		WithFlag(WithMap("0:a?")).
+		WithFlag(WithVideoCodec("copy")).
+		WithFlag(WithAudioCodec("copy"))
+	for _, opt := range c.opts {
+		out.WithFlag(opt)
+	}
+
+	return New("").
+		WithOptions(NewFfmpegOptions(flags...)).
+		Output(out.File(c.output).Build()), nil
+}
+
+// filterCommand normalizes every clip to the target format:
+//
+//	[0:v:0]scale=iw*sar:ih,setsar=1,scale=W:H:force_original_aspect_ratio=decrease,pad=W:H:(ow-iw)/2:(oh-ih)/2,setsar=1,fps=F,format=yuv420p[cv0];
+//	[0:a:0]aresample=48000,aformat=sample_fmts=fltp:channel_layouts=stereo[ca0];...
+//	[cv0][ca0][cv1][ca1]concat=n=2:v=1:a=1[cv][ca]
+//
+// Clips without audio get matching silence from anullsrc when any other clip has audio.
+func (c *Concat) filterCommand(probes []*ProbeResult) (*Ffmpego, error) {
+	hasVideo, hasAudio := 0, false
+	for _, probe := range probes {
+		if _, ok := probe.VideoStream(); ok {
+			hasVideo++
+		}
+		if len(probe.AudioStreams()) > 0 {
+			hasAudio = true
+		}
+	}
+	if hasVideo != 0 && hasVideo != len(probes) {
+		return nil, fmt.Errorf("concat: either every clip or none must have video (%d of %d do)", hasVideo, len(probes))
+	}
+
+	width, height, frameRate, sampleRate := c.width, c.height, c.frameRate, c.sampleRate
+	if hasVideo > 0 {
+		// the first clip with video sets the target, at its display aspect ratio
+		for _, probe := range probes {
+			if video, ok := probe.VideoStream(); ok {
+				if width == 0 || height == 0 {
+					_, height = video.DisplaySize()
+					width = evenDimension(float64(height) * video.DisplayAspectRatio())
+				}
+				if frameRate == "" {
+					frameRate = video.RFrameRate
+				}
+				break
+			}
+		}
+		if width <= 0 || height <= 0 {
+			return nil, fmt.Errorf("concat: cannot derive the target size from the clips, set it with Normalize")
+		}
+		if fps, err := ParseFrameRate(frameRate); err != nil || fps <= 0 {
+			return nil, fmt.Errorf("concat: cannot derive the target frame rate from the clips (%q), set it with Normalize", frameRate)
+		}
+	}
+	if sampleRate == 0 {
+		sampleRate = 48000
+	}
+
+	var flags []FfmpegFlagFn
+	if c.overwrite {
+		flags = append(flags, WithOverwrite())
+	}
+	graph := NewComplexFilterBuilder()
+	unit := ConcatFilter{Segments: len(c.clips)}
+	for i, clip := range c.clips {
+		var inputOpts []InputFlagFn
+		if clip.InPoint > 0 {
+			inputOpts = append(inputOpts, WithSeek(clip.InPoint))
+		}
+		if clip.length() > 0 {
+			inputOpts = append(inputOpts, WithInputDuration(clip.length()))
+		}
+		flags = append(flags, WithInputFile(clip.Path, inputOpts...))
+
+		if hasVideo > 0 {
+			label := fmt.Sprintf("cv%d", i)
+			graph.Chain(fmt.Sprintf("%d:v:0", i), fmt.Sprintf(
+				"scale=iw*sar:ih,setsar=1,scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,setsar=1,fps=%s,format=yuv420p",
+				width, height, width, height, frameRate), label)
+			unit.Inputs = append(unit.Inputs, label)
+		}
+		if hasAudio {
+			label := fmt.Sprintf("ca%d", i)
+			if len(probes[i].AudioStreams()) > 0 {
+				graph.Chain(fmt.Sprintf("%d:a:0", i), fmt.Sprintf(
+					"aresample=%d,aformat=sample_fmts=fltp:channel_layouts=stereo", sampleRate), label)
+			} else {
+				length := clip.length()
+				if length == 0 {
+					length = probes[i].Duration() - clip.InPoint
+				}
+				if length <= 0 {
+					return nil, fmt.Errorf("concat: clip %d has no audio and no known duration to pad with silence", i)
+				}
+				graph.Chain("", fmt.Sprintf("anullsrc=r=%d:cl=stereo,atrim=duration=%s", sampleRate, formatSeconds(length)), label)
+			}
+			unit.Inputs = append(unit.Inputs, label)
+		}
+	}
+
+	out := NewOutputBuilder()
+	if hasVideo > 0 {
+		unit.Video = 1
+		unit.Outputs = append(unit.Outputs, "cv")
+		out.WithFlag(WithMap("[cv]"))
+	}
+	if hasAudio {
+		unit.Audio = 1
+		unit.Outputs = append(unit.Outputs, "ca")
+		out.WithFlag(WithMap("[ca]"))
+	}
+	graph.Add(withFilter(unit))
+	for _, opt := range c.opts {
+		out.WithFlag(opt)
+	}
+
+	return New("").
+		WithOptions(NewFfmpegOptions(flags...)).
+		WithFilterGraph(graph.Build()).
+		Output(out.File(c.output).Build()), nil
+}
+
+// concatCompatible reports why clips cannot be joined by the demuxer, or nil when
+// their first video and audio streams share codec and parameters.
+func concatCompatible(probes []*ProbeResult) error {
+	ref := probes[0]
+	refVideo, refHasVideo := ref.VideoStream()
+	refAudio := ref.AudioStreams()
+	for i, probe := range probes[1:] {
+		video, hasVideo := probe.VideoStream()
+		if hasVideo != refHasVideo {
+			return fmt.Errorf("concat: clip %d video presence differs from clip 0", i+1)
+		}
+		if hasVideo && (video.CodecName != refVideo.CodecName || video.Width != refVideo.Width ||
+			video.Height != refVideo.Height || video.PixFmt != refVideo.PixFmt || video.RFrameRate != refVideo.RFrameRate) {
+			return fmt.Errorf("concat: clip %d video (%s %dx%d %s %s) differs from clip 0 (%s %dx%d %s %s)", i+1,
+				video.CodecName, video.Width, video.Height, video.PixFmt, video.RFrameRate,
+				refVideo.CodecName, refVideo.Width, refVideo.Height, refVideo.PixFmt, refVideo.RFrameRate)
+		}
+
+		audio := probe.AudioStreams()
+		if len(audio) != len(refAudio) {
+			return fmt.Errorf("concat: clip %d has %d audio streams, clip 0 has %d", i+1, len(audio), len(refAudio))
+		}
+		for j := range audio {
+			if audio[j].CodecName != refAudio[j].CodecName || audio[j].SampleRate != refAudio[j].SampleRate ||
+				audio[j].Channels != refAudio[j].Channels {
+				return fmt.Errorf("concat: clip %d audio stream %d (%s %s Hz %d ch) differs from clip 0", i+1, j,
+					audio[j].CodecName, audio[j].SampleRate, audio[j].Channels)
+			}
+		}
+	}
+	return nil
+}
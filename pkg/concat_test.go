@@ -0,0 +1,159 @@
+package ffmpego
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func concatProbe(t *testing.T, data string) *ProbeResult {
+	t.Helper()
+	probe, err := ParseProbe([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseProbe() error: %v", err)
+	}
+	return probe
+}
+
+func TestWriteConcatList(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteConcatList(&buf, []ConcatClip{
+		{Path: "/media/it's.mp4", InPoint: 2 * time.Second, OutPoint: 7500 * time.Millisecond},
+		{Path: "/media/b.mp4", Duration: 3 * time.Second},
+	})
+	if err != nil {
+		t.Fatalf("WriteConcatList() error: %v", err)
+	}
+
+	want := "ffconcat version 1.0\n" +
+		"file '/media/it'\\''s.mp4'\ninpoint 2\noutpoint 7.5\n" +
+		"file '/media/b.mp4'\nduration 3\n"
+	if buf.String() != want {
+		t.Fatalf("list mismatch:\n got: %q\nwant: %q", buf.String(), want)
+	}
+
+	if err := WriteConcatList(&buf, []ConcatClip{{Path: "a.mp4", InPoint: 5 * time.Second, OutPoint: time.Second}}); err == nil {
+		t.Fatalf("expected error for outpoint before inpoint, got nil")
+	}
+}
+
+func TestConcat_DemuxerWhenStreamsMatch(t *testing.T) {
+	probe := concatProbe(t, sampleProbe)
+	c := NewConcat("joined.mp4").Add("a.mp4", "b.mp4")
+	cmd, err := c.build([]*ProbeResult{probe, probe})
+	if err != nil {
+		t.Fatalf("build() error: %v", err)
+	}
+	defer c.Cleanup()
+
+	if c.Selected() != ConcatStrategyDemuxer {
+		t.Fatalf("expected demuxer strategy, got %s", c.Selected())
+	}
+	args, err := cmd.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	got := strings.Join(args, " ")
+	want := "-f concat -safe 0 -i " + c.listFile + " -map 0:v? -map 0:a? -c:v copy -c:a copy joined.mp4"
+	if got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+
+	list, err := os.ReadFile(c.listFile)
+	if err != nil {
+		t.Fatalf("expected list file: %v", err)
+	}
+	if !strings.Contains(string(list), "/a.mp4'\n") {
+		t.Fatalf("expected absolute clip paths, got %q", list)
+	}
+
+	// a second build replaces the first list file instead of leaking it
+	first := c.listFile
+	if _, err := c.build([]*ProbeResult{probe, probe}); err != nil {
+		t.Fatalf("build() error: %v", err)
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Fatalf("expected the earlier list file to be removed, stat err: %v", err)
+	}
+
+	path := c.listFile
+	if err := c.Cleanup(); err != nil {
+		t.Fatalf("Cleanup() error: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected list file to be removed, stat err: %v", err)
+	}
+}
+
+func TestConcat_FilterWhenStreamsDiffer(t *testing.T) {
+	first := concatProbe(t, sampleProbe)
+	second := concatProbe(t, `{"streams": [{"index": 0, "codec_name": "hevc", "codec_type": "video",
+		"width": 1280, "height": 720, "pix_fmt": "yuv420p", "r_frame_rate": "25/1"}],
+		"format": {"duration": "4.000000"}}`)
+
+	c := NewConcat("joined.mp4").
+		Add("a.mp4").
+		AddClip(ConcatClip{Path: "b.mp4", InPoint: time.Second}).
+		WithFlag(VideoCodecH264)
+	cmd, err := c.build([]*ProbeResult{first, second})
+	if err != nil {
+		t.Fatalf("build() error: %v", err)
+	}
+	if c.Selected() != ConcatStrategyFilter {
+		t.Fatalf("expected filter strategy, got %s", c.Selected())
+	}
+
+	args, err := cmd.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	got := strings.Join(args, " ")
+	norm := "scale=iw*sar:ih,setsar=1,scale=1920:1080:force_original_aspect_ratio=decrease,pad=1920:1080:(ow-iw)/2:(oh-ih)/2,setsar=1,fps=60000/1001,format=yuv420p"
+	want := "-i a.mp4 -ss 00:00:01.000 -i b.mp4 -filter_complex " +
+		"[0:v:0]" + norm + "[cv0];[0:a:0]aresample=48000,aformat=sample_fmts=fltp:channel_layouts=stereo[ca0];" +
+		"[1:v:0]" + norm + "[cv1];anullsrc=r=48000:cl=stereo,atrim=duration=3[ca1];" +
+		"[cv0][ca0][cv1][ca1]concat=n=2:v=1:a=1[cv][ca] -map [cv] -map [ca] -c:v libx264 joined.mp4"
+	if got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+
+	if _, err := NewConcat("joined.mp4").Add("a.mp4", "b.mp4").Strategy(ConcatStrategyDemuxer).
+		build([]*ProbeResult{first, second}); err == nil {
+		t.Fatalf("expected error forcing the demuxer on mismatched clips, got nil")
+	}
+
+	unknown := concatProbe(t, `{"streams": [{"index": 0, "codec_name": "hevc", "codec_type": "video",
+		"width": 1280, "height": 720, "pix_fmt": "yuv420p", "r_frame_rate": "25/1"}], "format": {}}`)
+	if _, err := NewConcat("joined.mp4").Add("a.mp4", "b.mp4").build([]*ProbeResult{first, unknown}); err == nil {
+		t.Fatalf("expected error padding a clip of unknown duration, got nil")
+	}
+}
+
+func TestConcat_FilterTargetFromDisplaySize(t *testing.T) {
+	anamorphic := concatProbe(t, `{"streams": [{"index": 0, "codec_name": "mpeg2video", "codec_type": "video",
+		"width": 720, "height": 576, "sample_aspect_ratio": "64:45", "pix_fmt": "yuv420p", "r_frame_rate": "25/1"}],
+		"format": {"duration": "4.000000"}}`)
+	other := concatProbe(t, sampleProbe)
+
+	c := NewConcat("joined.mp4").Add("a.mpg", "b.mp4")
+	cmd, err := c.build([]*ProbeResult{anamorphic, other})
+	if err != nil {
+		t.Fatalf("build() error: %v", err)
+	}
+	args, err := cmd.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	want := "[0:v:0]scale=iw*sar:ih,setsar=1,scale=1024:576:force_original_aspect_ratio=decrease,pad=1024:576:(ow-iw)/2:(oh-ih)/2,setsar=1,fps=25/1,format=yuv420p[cv0]"
+	if got := strings.Join(args, " "); !strings.Contains(got, want) {
+		t.Fatalf("args %q missing %q", got, want)
+	}
+
+	noRate := concatProbe(t, `{"streams": [{"index": 0, "codec_name": "hevc", "codec_type": "video",
+		"width": 1280, "height": 720, "pix_fmt": "yuv420p", "r_frame_rate": "0/0"}], "format": {"duration": "4.000000"}}`)
+	if _, err := NewConcat("joined.mp4").Add("a.mp4", "b.mp4").build([]*ProbeResult{noRate, other}); err == nil {
+		t.Fatalf("expected error for a clip without frame rate, got nil")
+	}
+}
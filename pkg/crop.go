@@ -0,0 +1,189 @@
+package ffmpego
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cropSampleSpan is how much of the input cropdetect analyzes at each sample point.
+const cropSampleSpan = 2 * time.Second
+
+var cropSuggestionRe = regexp.MustCompile(`\[Parsed_cropdetect_(\d+) @ [^\]]*\].*crop=(-?\d+):(-?\d+):(-?\d+):(-?\d+)`)
+
+// CropDetectFilter renders: "[input]cropdetect=limit=0.1:round=2:reset=0[output]"
+type CropDetectFilter struct {
+	Input  string
+	Output string
+	Limit  float64 // black threshold, as a fraction of the pixel range (0..1)
+	Round  int     // width and height are divisible by Round
+	Reset  int     // frames after which the detected area is reset, 0 never resets
+}
+
+func (f CropDetectFilter) Validate() error {
+	if strings.TrimSpace(f.Input) == "" {
+		return fmt.Errorf("cropdetect: input label cannot be empty")
+	}
+	if strings.TrimSpace(f.Output) == "" {
+		return fmt.Errorf("cropdetect: output label cannot be empty")
+	}
+	if f.Limit < 0 || f.Limit > 1 {
+		return fmt.Errorf("cropdetect: limit must be between 0 and 1, got %v", f.Limit)
+	}
+	if f.Round <= 0 {
+		return fmt.Errorf("cropdetect: round must be positive, got %d", f.Round)
+	}
+	if f.Reset < 0 {
+		return fmt.Errorf("cropdetect: reset must be non-negative, got %d", f.Reset)
+	}
+	return nil
+}
+
+func (f CropDetectFilter) Parse() string {
+	return fmt.Sprintf("[%s]cropdetect=limit=%s:round=%d:reset=%d[%s]",
+		f.Input, formatFloat(f.Limit), f.Round, f.Reset, f.Output)
+}
+
+// WithCropDetect adds a labeled cropdetect chain.
+func WithCropDetect(input string, output string, limit float64, round int) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(CropDetectFilter{
+			Input:  strings.TrimSpace(input),
+			Output: strings.TrimSpace(output),
+			Limit:  limit,
+			Round:  round,
+		})
+	}
+}
+
+// Labeled returns a FilterFn adding the crop between input and output, e.g. to apply
+// a crop returned by DetectCrop to a FilterGraphBuilder.
+func (f CropFilter) Labeled(input, output string) FilterFn {
+	return WithCrop(input, output, f.W, f.H, f.X, f.Y)
+}
+
+// CropDetectCommand builds a single pass analyzing span of input at each point. Every
+// point is a separate fast-seeking input with its own cropdetect instance.
+func CropDetectCommand(input string, points []time.Duration, span time.Duration) *Ffmpego {
+	flags := make([]FfmpegFlagFn, 0, len(points))
+	graph := NewComplexFilterBuilder()
+	maps := make([]string, 0, len(points))
+	for i, point := range points {
+		flags = append(flags, WithInputFile(input, WithSeek(point), WithInputDuration(span)))
+		label := fmt.Sprintf("crop%d", i)
+		graph.Add(WithCropDetect(fmt.Sprintf("%d:v:0", i), label, 0.1, 2))
+		maps = append(maps, "["+label+"]")
+	}
+
+	return New("").
+		WithOptions(NewFfmpegOptions(flags...)).
+		WithFilterGraph(graph.Build()).
+		Output(NewNullOutput(maps...))
+}
+
+// DetectCrop runs cropdetect at samples points spread across the duration of input and
+// returns the consensus crop with even dimensions. The returned filter maps "0:v:0" to
+// "cropped"; use Labeled to place it elsewhere in a graph.
+func DetectCrop(ctx context.Context, input string, samples int) (*CropFilter, error) {
+	if samples <= 0 {
+		return nil, fmt.Errorf("crop: samples must be positive, got %d", samples)
+	}
+
+	probe, err := Probe(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	duration := probe.Duration()
+	if duration <= 0 {
+		return nil, fmt.Errorf("crop: input has no known duration")
+	}
+
+	// spread points evenly, away from the very start and end where slates and credits live
+	points := make([]time.Duration, samples)
+	for i := range points {
+		points[i] = duration * time.Duration(i+1) / time.Duration(samples+1)
+	}
+
+	stderr, err := NewRunner(CropDetectCommand(input, points, cropSampleSpan)).Capture(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	crop, err := CropConsensus(ParseCropSuggestions(stderr))
+	if err != nil {
+		return nil, err
+	}
+	crop.Input, crop.Output = "0:v:0", "cropped"
+	return &crop, nil
+}
+
+// ParseCropSuggestions returns the last suggestion logged by each cropdetect instance,
+// ordered by instance. cropdetect grows its area while it runs without reset, so the last
+// line covers every analyzed frame.
+func ParseCropSuggestions(stderr []byte) []CropFilter {
+	last := make(map[int]CropFilter)
+	maxInstance := -1
+	scanner := bufio.NewScanner(bytes.NewReader(stderr))
+	for scanner.Scan() {
+		m := cropSuggestionRe.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		instance, _ := strconv.Atoi(m[1])
+		values := make([]int, 4)
+		for i := range values {
+			values[i], _ = strconv.Atoi(m[i+2])
+		}
+		last[instance] = CropFilter{W: values[0], H: values[1], X: values[2], Y: values[3]}
+		if instance > maxInstance {
+			maxInstance = instance
+		}
+	}
+
+	suggestions := make([]CropFilter, 0, len(last))
+	for i := 0; i <= maxInstance; i++ {
+		if crop, ok := last[i]; ok {
+			suggestions = append(suggestions, crop)
+		}
+	}
+	return suggestions
+}
+
+// CropConsensus picks the most frequent valid suggestion; ties keep the larger area so
+// that a dark sample cannot crop into the picture. Suggestions from all-black frames,
+// which cropdetect reports with negative sizes, are ignored.
+func CropConsensus(suggestions []CropFilter) (CropFilter, error) {
+	counts := make(map[CropFilter]int)
+	var best CropFilter
+	bestCount := 0
+	for _, s := range suggestions {
+		s = evenCrop(s)
+		if s.W <= 0 || s.H <= 0 || s.X < 0 || s.Y < 0 {
+			continue
+		}
+		counts[s]++
+		count := counts[s]
+		if count > bestCount || (count == bestCount && s.W*s.H > best.W*best.H) {
+			best, bestCount = s, count
+		}
+	}
+
+	if bestCount == 0 {
+		return CropFilter{}, fmt.Errorf("crop: no usable cropdetect suggestion (%d samples)", len(suggestions))
+	}
+	return best, nil
+}
+
+// evenCrop rounds the crop area down to even sizes and offsets, as required by 4:2:0 chroma.
+func evenCrop(c CropFilter) CropFilter {
+	c.W &^= 1
+	c.H &^= 1
+	c.X &^= 1
+	c.Y &^= 1
+	return c
+}
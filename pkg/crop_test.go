@@ -0,0 +1,68 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleCropStderr = `[Parsed_cropdetect_0 @ 0x55d0c8] x1:0 x2:1919 y1:142 y2:937 w:1920 h:796 x:0 y:142 pts:1001 t:0.041708 limit:0.100000 crop=1920:796:0:142
+[Parsed_cropdetect_0 @ 0x55d0c8] x1:0 x2:1919 y1:140 y2:939 w:1920 h:800 x:0 y:140 pts:2002 t:0.083417 limit:0.100000 crop=1920:800:0:140
+[Parsed_cropdetect_1 @ 0x55d1a0] x1:0 x2:1919 y1:140 y2:939 w:1920 h:800 x:0 y:140 pts:2002 t:0.083417 limit:0.100000 crop=1920:800:0:140
+[Parsed_cropdetect_2 @ 0x55d2b4] x1:1919 x2:0 y1:1079 y2:0 w:-1904 h:-1072 x:1912 y:1076 pts:1001 t:0.041708 limit:0.100000 crop=-1904:-1072:1912:1076
+[Parsed_cropdetect_3 @ 0x55d3c8] x1:0 x2:1919 y1:181 y2:898 w:1920 h:717 x:0 y:181 pts:1001 t:0.041708 limit:0.100000 crop=1920:717:0:181
+`
+
+func TestParseCropSuggestions(t *testing.T) {
+	got := ParseCropSuggestions([]byte(sampleCropStderr))
+	if len(got) != 4 {
+		t.Fatalf("expected one suggestion per instance, got %+v", got)
+	}
+	if got[0] != (CropFilter{W: 1920, H: 800, X: 0, Y: 140}) {
+		t.Fatalf("expected the last suggestion of instance 0, got %+v", got[0])
+	}
+}
+
+func TestCropConsensus(t *testing.T) {
+	crop, err := CropConsensus(ParseCropSuggestions([]byte(sampleCropStderr)))
+	if err != nil {
+		t.Fatalf("CropConsensus() error: %v", err)
+	}
+	if crop != (CropFilter{W: 1920, H: 800, X: 0, Y: 140}) {
+		t.Fatalf("consensus mismatch: %+v", crop)
+	}
+
+	// without a majority the larger area wins, rounded to even values
+	crop, err = CropConsensus([]CropFilter{{W: 1920, H: 717, X: 0, Y: 181}, {W: 1920, H: 801, X: 0, Y: 139}})
+	if err != nil {
+		t.Fatalf("CropConsensus() error: %v", err)
+	}
+	if crop != (CropFilter{W: 1920, H: 800, X: 0, Y: 138}) {
+		t.Fatalf("tie break mismatch: %+v", crop)
+	}
+
+	if _, err := CropConsensus([]CropFilter{{W: -1904, H: -1072, X: 1912, Y: 1076}}); err == nil {
+		t.Fatalf("expected error for black-only suggestions, got nil")
+	}
+}
+
+func TestCropDetectCommand(t *testing.T) {
+	cmd := CropDetectCommand("in.mkv", []time.Duration{10 * time.Second, 20 * time.Second}, 2*time.Second)
+	args, err := cmd.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	got := strings.Join(args, " ")
+	want := "-ss 00:00:10.000 -t 00:00:02.000 -i in.mkv -ss 00:00:20.000 -t 00:00:02.000 -i in.mkv -filter_complex " +
+		"[0:v:0]cropdetect=limit=0.1:round=2:reset=0[crop0];[1:v:0]cropdetect=limit=0.1:round=2:reset=0[crop1] " +
+		"-map [crop0] -map [crop1] -f null -"
+	if got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+
+	graph := NewComplexFilterBuilder().Add(CropFilter{W: 1920, H: 800, Y: 140}.Labeled("0:v", "c")).Build()
+	if expr, err := graph.BuildAndValidate(); err != nil || expr != "[0:v]crop=1920:800:0:140[c]" {
+		t.Fatalf("Labeled mismatch: %q, %v", expr, err)
+	}
+}
@@ -0,0 +1,286 @@
+package ffmpego
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultDASHInitSegmentName is the init segment template used by NewDASHOutput.
+	DefaultDASHInitSegmentName = "init-$RepresentationID$.$ext$"
+	// DefaultDASHMediaSegmentName is the media segment template used by NewDASHOutput.
+	DefaultDASHMediaSegmentName = "chunk-$RepresentationID$-$Number%05d$.$ext$"
+	// DefaultDASHSegmentDuration is the segment duration used by NewDASHOutput.
+	DefaultDASHSegmentDuration = 4 * time.Second
+)
+
+// DASHAdaptationSet groups streams into a single MPD AdaptationSet.
+// Streams accepts "v", "a" or output stream indexes (e.g. "0", "1").
+// Renders: "id=0,streams=v"
+type DASHAdaptationSet struct {
+	ID      int
+	Streams []string
+}
+
+func (s DASHAdaptationSet) Validate() error {
+	if s.ID < 0 {
+		return fmt.Errorf("dash: adaptation set id must be non-negative, got %d", s.ID)
+	}
+	if len(s.Streams) == 0 {
+		return fmt.Errorf("dash: adaptation set %d must reference at least one stream", s.ID)
+	}
+	for _, stream := range s.Streams {
+		if stream == "v" || stream == "a" {
+			continue
+		}
+		if idx, err := strconv.Atoi(stream); err != nil || idx < 0 {
+			return fmt.Errorf("dash: adaptation set %d has invalid stream %q (expected v, a or an index)", s.ID, stream)
+		}
+	}
+	return nil
+}
+
+func (s DASHAdaptationSet) String() string {
+	return fmt.Sprintf("id=%d,streams=%s", s.ID, strings.Join(s.Streams, ","))
+}
+
+// DASHAdaptationSetsFlag represents the dash muxer -adaptation_sets option
+type DASHAdaptationSetsFlag []DASHAdaptationSet
+
+// Parse returns the adaptation sets flag arguments
+func (f DASHAdaptationSetsFlag) Parse() []string {
+	sets := make([]string, len(f))
+	for i, set := range f {
+		sets[i] = set.String()
+	}
+	return []string{"-adaptation_sets", strings.Join(sets, " ")}
+}
+
+// Validate validates every adaptation set and checks ids are unique
+func (f DASHAdaptationSetsFlag) Validate() error {
+	if len(f) == 0 {
+		return fmt.Errorf("dash: adaptation sets cannot be empty")
+	}
+	seen := make(map[int]bool, len(f))
+	for _, set := range f {
+		if err := set.Validate(); err != nil {
+			return err
+		}
+		if seen[set.ID] {
+			return fmt.Errorf("dash: duplicate adaptation set id %d", set.ID)
+		}
+		seen[set.ID] = true
+	}
+	return nil
+}
+
+// SegmentDurationFlag represents the dash muxer -seg_duration option
+type SegmentDurationFlag time.Duration
+
+// Parse returns the segment duration flag arguments in seconds
+func (f SegmentDurationFlag) Parse() []string {
+	return []string{"-seg_duration", formatSeconds(time.Duration(f))}
+}
+
+// Validate validates the segment duration flag
+func (f SegmentDurationFlag) Validate() error {
+	if f <= 0 {
+		return fmt.Errorf("dash: segment duration must be positive, got %s", time.Duration(f))
+	}
+	return nil
+}
+
+// UseTemplateFlag represents the dash muxer -use_template option
+type UseTemplateFlag bool
+
+// Parse returns the use template flag arguments
+func (f UseTemplateFlag) Parse() []string {
+	return []string{"-use_template", boolArg(bool(f))}
+}
+
+// Validate validates the use template flag
+func (f UseTemplateFlag) Validate() error {
+	return nil
+}
+
+// UseTimelineFlag represents the dash muxer -use_timeline option
+type UseTimelineFlag bool
+
+// Parse returns the use timeline flag arguments
+func (f UseTimelineFlag) Parse() []string {
+	return []string{"-use_timeline", boolArg(bool(f))}
+}
+
+// Validate validates the use timeline flag
+func (f UseTimelineFlag) Validate() error {
+	return nil
+}
+
+// InitSegmentNameFlag represents the dash muxer -init_seg_name template
+type InitSegmentNameFlag string
+
+// Parse returns the init segment name flag arguments
+func (f InitSegmentNameFlag) Parse() []string {
+	return []string{"-init_seg_name", string(f)}
+}
+
+// Validate checks the template is set and unique per representation
+func (f InitSegmentNameFlag) Validate() error {
+	return validateSegmentTemplate("init segment", string(f))
+}
+
+// MediaSegmentNameFlag represents the dash muxer -media_seg_name template
+type MediaSegmentNameFlag string
+
+// Parse returns the media segment name flag arguments
+func (f MediaSegmentNameFlag) Parse() []string {
+	return []string{"-media_seg_name", string(f)}
+}
+
+// Validate checks the template is set and unique per representation
+func (f MediaSegmentNameFlag) Validate() error {
+	return validateSegmentTemplate("media segment", string(f))
+}
+
+// HLSPlaylistFlag represents the dash muxer -hls_playlist option.
+// When enabled the muxer also writes HLS playlists over the same CMAF segments.
+type HLSPlaylistFlag bool
+
+// Parse returns the hls playlist flag arguments
+func (f HLSPlaylistFlag) Parse() []string {
+	return []string{"-hls_playlist", boolArg(bool(f))}
+}
+
+// Validate validates the hls playlist flag
+func (f HLSPlaylistFlag) Validate() error {
+	return nil
+}
+
+// DASHOutput provides a fluent API to compose an MPEG-DASH (or combined CMAF) output.
+// Like OutputBuilder it produces an OutputDescriptor on Build(), with "-f dash" and
+// the manifest path appended last.
+type DASHOutput struct {
+	manifest         string
+	opts             []OutputFlagFn
+	adaptationSets   DASHAdaptationSetsFlag
+	segmentDuration  time.Duration
+	useTemplate      bool
+	useTimeline      bool
+	initSegmentName  string
+	mediaSegmentName string
+	hlsPlaylist      bool
+}
+
+// NewDASHOutput creates a DASH output writing the MPD to manifest.
+// Defaults: 4s segments, templated names with timeline enabled.
+func NewDASHOutput(manifest string) *DASHOutput {
+	return &DASHOutput{
+		manifest:         manifest,
+		opts:             make([]OutputFlagFn, 0),
+		segmentDuration:  DefaultDASHSegmentDuration,
+		useTemplate:      true,
+		useTimeline:      true,
+		initSegmentName:  DefaultDASHInitSegmentName,
+		mediaSegmentName: DefaultDASHMediaSegmentName,
+	}
+}
+
+// Map adds one -map per stream, e.g. Map("[v720]", "[v480]", "0:a").
+// Each mapped stream becomes a representation in the MPD.
+func (d *DASHOutput) Map(streams ...string) *DASHOutput {
+	for _, stream := range streams {
+		d.opts = append(d.opts, WithMap(stream))
+	}
+	return d
+}
+
+// WithFlag appends any output option builder (e.g., VideoCodecH264).
+func (d *DASHOutput) WithFlag(opt OutputFlagFn) *DASHOutput {
+	d.opts = append(d.opts, opt)
+	return d
+}
+
+// AdaptationSet adds an adaptation set grouping the given streams.
+func (d *DASHOutput) AdaptationSet(id int, streams ...string) *DASHOutput {
+	d.adaptationSets = append(d.adaptationSets, DASHAdaptationSet{ID: id, Streams: streams})
+	return d
+}
+
+// SegmentDuration sets the target segment duration.
+func (d *DASHOutput) SegmentDuration(duration time.Duration) *DASHOutput {
+	d.segmentDuration = duration
+	return d
+}
+
+// UseTemplate toggles SegmentTemplate addressing in the MPD.
+func (d *DASHOutput) UseTemplate(enabled bool) *DASHOutput {
+	d.useTemplate = enabled
+	return d
+}
+
+// UseTimeline toggles SegmentTimeline addressing in the MPD.
+func (d *DASHOutput) UseTimeline(enabled bool) *DASHOutput {
+	d.useTimeline = enabled
+	return d
+}
+
+// SegmentNames overrides the init and media segment name templates.
+func (d *DASHOutput) SegmentNames(init, media string) *DASHOutput {
+	d.initSegmentName = init
+	d.mediaSegmentName = media
+	return d
+}
+
+// HLSPlaylist additionally writes HLS playlists for the same segments (CMAF).
+func (d *DASHOutput) HLSPlaylist(enabled bool) *DASHOutput {
+	d.hlsPlaylist = enabled
+	return d
+}
+
+// Build materializes an OutputDescriptor for the dash muxer.
+func (d *DASHOutput) Build() *OutputDescriptor {
+	opts := append([]OutputFlagFn{}, d.opts...)
+	opts = append(opts,
+		WithFormat("dash"),
+		withOutputFlag(SegmentDurationFlag(d.segmentDuration)),
+		withOutputFlag(UseTemplateFlag(d.useTemplate)),
+		withOutputFlag(UseTimelineFlag(d.useTimeline)),
+		withOutputFlag(InitSegmentNameFlag(d.initSegmentName)),
+		withOutputFlag(MediaSegmentNameFlag(d.mediaSegmentName)),
+	)
+	if len(d.adaptationSets) > 0 {
+		opts = append(opts, withOutputFlag(d.adaptationSets))
+	}
+	if d.hlsPlaylist {
+		opts = append(opts, withOutputFlag(HLSPlaylistFlag(true)))
+	}
+	opts = append(opts, WithFile(d.manifest))
+
+	return NewOutputDescriptor(opts...)
+}
+
+// withOutputFlag wraps an already typed flag into an OutputFlagFn.
+func withOutputFlag(flag OutputFlagParser) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(flag)
+	}
+}
+
+func validateSegmentTemplate(kind, template string) error {
+	if strings.TrimSpace(template) == "" {
+		return fmt.Errorf("dash: %s name cannot be empty", kind)
+	}
+	if !strings.Contains(template, "$RepresentationID$") {
+		return fmt.Errorf("dash: %s name %q must contain $RepresentationID$", kind, template)
+	}
+	return nil
+}
+
+func boolArg(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
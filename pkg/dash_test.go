@@ -0,0 +1,100 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDASHOutput_Build(t *testing.T) {
+	out := NewDASHOutput("manifest.mpd").
+		Map("[v720]", "[v480]", "0:a").
+		WithFlag(VideoCodecH264).
+		AdaptationSet(0, "v").
+		AdaptationSet(1, "a").
+		SegmentDuration(2 * time.Second).
+		HLSPlaylist(true).
+		Build()
+
+	args, err := out.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	got := strings.Join(args, " ")
+	want := "-map [v720] -map [v480] -map 0:a -c:v libx264 -f dash -seg_duration 2 -use_template 1 -use_timeline 1 " +
+		"-init_seg_name init-$RepresentationID$.$ext$ -media_seg_name chunk-$RepresentationID$-$Number%05d$.$ext$ " +
+		"-adaptation_sets id=0,streams=v id=1,streams=a -hls_playlist 1 manifest.mpd"
+	if got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestDASHOutput_InvalidTemplate(t *testing.T) {
+	out := NewDASHOutput("manifest.mpd").
+		SegmentNames("init.m4s", "chunk-$Number$.m4s").
+		Build()
+
+	if _, err := out.Build(); err == nil {
+		t.Fatalf("expected error for template without $RepresentationID$, got nil")
+	}
+}
+
+func TestDASHAdaptationSets_Invalid(t *testing.T) {
+	cases := []DASHAdaptationSetsFlag{
+		{},
+		{{ID: 0, Streams: []string{"x"}}},
+		{{ID: 0, Streams: []string{"v"}}, {ID: 0, Streams: []string{"a"}}},
+	}
+	for i, f := range cases {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("case %d: expected error, got nil", i)
+		}
+	}
+}
+
+const sampleMPD = `<?xml version="1.0" encoding="utf-8"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" type="static" mediaPresentationDuration="PT0H0M10.500S" minBufferTime="PT4.0S">
+	<Period id="0" start="PT0.0S">
+		<AdaptationSet id="0" contentType="video" segmentAlignment="true">
+			<Representation id="0" mimeType="video/mp4" codecs="avc1.64001f" bandwidth="2500000" width="1280" height="720"/>
+			<Representation id="1" mimeType="video/mp4" codecs="avc1.64001e" bandwidth="1000000" width="854" height="480"/>
+		</AdaptationSet>
+		<AdaptationSet id="1" mimeType="audio/mp4">
+			<Representation id="2" codecs="mp4a.40.2" bandwidth="128000" audioSamplingRate="48000"/>
+		</AdaptationSet>
+	</Period>
+</MPD>`
+
+func TestParseMPD_Verify(t *testing.T) {
+	mpd, err := ParseMPD(strings.NewReader(sampleMPD))
+	if err != nil {
+		t.Fatalf("ParseMPD() error: %v", err)
+	}
+
+	reps := mpd.Representations()
+	if len(reps) != 3 {
+		t.Fatalf("expected 3 representations, got %d", len(reps))
+	}
+	if reps[2].ContentType != "audio" || reps[2].MimeType != "audio/mp4" {
+		t.Fatalf("audio representation should inherit from its set, got %+v", reps[2])
+	}
+
+	duration, err := mpd.Duration()
+	if err != nil || duration != 10500*time.Millisecond {
+		t.Fatalf("duration mismatch: got %v (err=%v)", duration, err)
+	}
+
+	err = mpd.Verify(
+		ExpectedRepresentation{ContentType: "video", Width: 1280, Height: 720},
+		ExpectedRepresentation{ContentType: "video", Height: 480},
+		ExpectedRepresentation{ContentType: "audio"},
+	)
+	if err != nil {
+		t.Fatalf("Verify() error: %v", err)
+	}
+
+	if err := mpd.Verify(ExpectedRepresentation{ContentType: "video", Height: 1080}); err == nil {
+		t.Fatalf("expected error for missing 1080p representation, got nil")
+	}
+}
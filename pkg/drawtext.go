@@ -0,0 +1,216 @@
+package ffmpego
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Common drawtext position expressions.
+const (
+	TextCenterX = "(w-text_w)/2"
+	TextCenterY = "(h-text_h)/2"
+	TextBottomY = "h-text_h-th*2"
+)
+
+var (
+	timecodePattern = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}[:;.]\d{2}$`)
+	colorPattern    = regexp.MustCompile(`^(#|0x)?[0-9A-Za-z_]+(@(0x[0-9A-Fa-f]{2}|[0-9.]+))?$`)
+)
+
+// DrawTextLiteral escapes s for drawtext's own %{...} expansion, so it can be combined with
+// expansions in an Expand text, e.g. DrawTextLiteral("100% live ") + TextLocalTime("%X").
+func DrawTextLiteral(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`).Replace(s)
+}
+
+// expansionArg escapes an expansion argument, which drawtext ends at ':' or '}'.
+func expansionArg(arg string) string {
+	return strings.NewReplacer(`\`, `\\`, `:`, `\:`, `}`, `\}`).Replace(arg)
+}
+
+// TextPTS returns the %{pts} expansion, e.g. format "hms" renders the time as HH:MM:SS.mmm.
+func TextPTS(format string) string {
+	if format == "" {
+		return "%{pts}"
+	}
+	return "%{pts:" + expansionArg(format) + "}"
+}
+
+// TextLocalTime returns the %{localtime} expansion with a strftime format, e.g. "%H:%M:%S".
+func TextLocalTime(format string) string {
+	if format == "" {
+		return "%{localtime}"
+	}
+	return "%{localtime:" + expansionArg(format) + "}"
+}
+
+// TextFrameNumber returns the %{n} expansion, the frame number starting at 0.
+func TextFrameNumber() string {
+	return "%{n}"
+}
+
+// DrawTextFilter renders a drawtext filter:
+//
+//	[0:v]drawtext=text=Hello\, world:fontfile=/fonts/Inter.ttf:fontsize=48:fontcolor=white@0.9:
+//	box=1:boxcolor=black@0.5:boxborderw=12:x=(w-text_w)/2:y=h-text_h-th*2:enable='between(t,1,5)'[titled]
+//
+// Text is literal unless Expand is set, in which case %{...} sequences (TextPTS, TextLocalTime)
+// are expanded and literal parts should go through DrawTextLiteral. Option and graph level
+// escaping of every value is handled here. Timecode draws a running SMPTE timecode
+// ("00:00:00:00", ";" for drop frame) at TimecodeRate, after Text when both are set.
+type DrawTextFilter struct {
+	Input        string
+	Output       string
+	Text         string
+	TextFile     string
+	Expand       bool
+	FontFile     string
+	Font         string
+	FontSize     int
+	FontColor    string
+	Box          bool
+	BoxColor     string
+	BoxBorder    int
+	BorderWidth  int
+	BorderColor  string
+	X            string
+	Y            string
+	Timecode     string
+	TimecodeRate string
+	Start        time.Duration
+	End          time.Duration
+}
+
+func (f DrawTextFilter) Validate() error {
+	if err := checkLabels("drawtext", f.Input, f.Output); err != nil {
+		return err
+	}
+	if f.Text == "" && f.TextFile == "" && f.Timecode == "" {
+		return fmt.Errorf("drawtext: text, text file or timecode is required")
+	}
+	if f.Text != "" && f.TextFile != "" {
+		return fmt.Errorf("drawtext: text and text file are mutually exclusive")
+	}
+	if f.Timecode != "" {
+		if !timecodePattern.MatchString(f.Timecode) {
+			return fmt.Errorf("drawtext: timecode must be hh:mm:ss:ff (or ; for drop frame), got %q", f.Timecode)
+		}
+		if fps, err := ParseFrameRate(f.TimecodeRate); err != nil || fps <= 0 {
+			return fmt.Errorf("drawtext: timecode requires a valid rate, got %q", f.TimecodeRate)
+		}
+	}
+	if f.FontSize < 0 || f.BoxBorder < 0 || f.BorderWidth < 0 {
+		return fmt.Errorf("drawtext: font size and border widths must be non-negative")
+	}
+	for _, color := range []string{f.FontColor, f.BoxColor, f.BorderColor} {
+		if err := validateColor(color); err != nil {
+			return fmt.Errorf("drawtext: %w", err)
+		}
+	}
+	if f.Start < 0 || (f.End != 0 && f.End <= f.Start) {
+		return fmt.Errorf("drawtext: invalid enable window %s-%s", f.Start, f.End)
+	}
+	return nil
+}
+
+// validateColor accepts empty, a color name or hex value with an optional @alpha (0-1 or 0xAA).
+func validateColor(color string) error {
+	if color == "" {
+		return nil
+	}
+	if !colorPattern.MatchString(color) {
+		return fmt.Errorf("invalid color %q", color)
+	}
+	if _, alpha, ok := strings.Cut(color, "@"); ok && !strings.HasPrefix(alpha, "0x") {
+		if a, err := strconv.ParseFloat(alpha, 64); err != nil || a < 0 || a > 1 {
+			return fmt.Errorf("color %q alpha must be between 0 and 1", color)
+		}
+	}
+	return nil
+}
+
+func (f DrawTextFilter) Parse() string {
+	var opts []string
+	add := func(key, value string) {
+		opts = append(opts, key+"="+escapeFilterValue(value))
+	}
+
+	if f.Text != "" {
+		text := f.Text
+		if !f.Expand {
+			text = DrawTextLiteral(text)
+		}
+		add("text", text)
+	}
+	if f.TextFile != "" {
+		add("textfile", f.TextFile)
+	}
+	if f.TextFile != "" && !f.Expand {
+		add("expansion", "none")
+	}
+	if f.FontFile != "" {
+		add("fontfile", f.FontFile)
+	}
+	if f.Font != "" {
+		add("font", f.Font)
+	}
+	if f.FontSize > 0 {
+		add("fontsize", strconv.Itoa(f.FontSize))
+	}
+	if f.FontColor != "" {
+		add("fontcolor", f.FontColor)
+	}
+	if f.Box {
+		add("box", "1")
+		if f.BoxColor != "" {
+			add("boxcolor", f.BoxColor)
+		}
+		if f.BoxBorder > 0 {
+			add("boxborderw", strconv.Itoa(f.BoxBorder))
+		}
+	}
+	if f.BorderWidth > 0 {
+		add("borderw", strconv.Itoa(f.BorderWidth))
+		if f.BorderColor != "" {
+			add("bordercolor", f.BorderColor)
+		}
+	}
+	if f.X != "" {
+		add("x", f.X)
+	}
+	if f.Y != "" {
+		add("y", f.Y)
+	}
+	if f.Timecode != "" {
+		add("timecode", f.Timecode)
+		add("rate", f.TimecodeRate)
+	}
+	if f.End > 0 {
+		opts = append(opts, fmt.Sprintf("enable='between(t,%s,%s)'", formatSeconds(f.Start), formatSeconds(f.End)))
+	} else if f.Start > 0 {
+		opts = append(opts, fmt.Sprintf("enable='gte(t,%s)'", formatSeconds(f.Start)))
+	}
+	return fmt.Sprintf("[%s]drawtext=%s[%s]", f.Input, strings.Join(opts, ":"), f.Output)
+}
+
+// Labeled returns a FilterFn adding the drawtext between input and output.
+func (f DrawTextFilter) Labeled(input, output string) FilterFn {
+	f.Input = strings.TrimSpace(input)
+	f.Output = strings.TrimSpace(output)
+	return withFilter(f)
+}
+
+// WithDrawText adds a labeled drawtext filter drawing literal text at x, y.
+// Renders: "[input]drawtext=text=...:fontsize=size:fontcolor=color:x=x:y=y[output]"
+func WithDrawText(input string, output string, text string, size int, color string, x, y string) FilterFn {
+	return DrawTextFilter{Text: text, FontSize: size, FontColor: color, X: x, Y: y}.Labeled(input, output)
+}
+
+// WithTimecode adds a labeled drawtext filter burning a running SMPTE timecode at x, y.
+// Renders: "[input]drawtext=fontsize=size:...:x=x:y=y:timecode=00\\:00\\:00\\:00:rate=25[output]"
+func WithTimecode(input string, output string, start string, rate string, size int, x, y string) FilterFn {
+	return DrawTextFilter{Timecode: start, TimecodeRate: rate, FontSize: size, FontColor: "white", Box: true, BoxColor: "black@0.5", X: x, Y: y}.Labeled(input, output)
+}
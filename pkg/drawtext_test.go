@@ -0,0 +1,139 @@
+package ffmpego
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDrawTextFilter_ValidateParse(t *testing.T) {
+	f := DrawTextFilter{
+		Input:     "0:v",
+		Output:    "t",
+		Text:      DrawTextLiteral("Live, 100% ") + TextLocalTime("%X"),
+		Expand:    true,
+		FontFile:  "C:/fonts/Inter.ttf",
+		Box:       true,
+		BoxColor:  "black@0.5",
+		BoxBorder: 12,
+		X:         "if(gte(t,2),10,20)",
+		Y:         "10",
+		Start:     time.Second,
+		End:       5 * time.Second,
+	}
+	if err := f.Validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	got := f.Parse()
+	want := `[0:v]drawtext=text=Live\, 100\\\\% %{localtime\\:%X}:fontfile=C\\:/fonts/Inter.ttf:box=1:boxcolor=black@0.5:boxborderw=12:` +
+		`x=if(gte(t\,2)\,10\,20):y=10:enable='between(t,1,5)'[t]`
+	if got != want {
+		t.Fatalf("parse mismatch: got %q want %q", got, want)
+	}
+}
+
+func TestTextLocalTime_EscapesColons(t *testing.T) {
+	if got, want := TextLocalTime("%H:%M:%S"), `%{localtime:%H\:%M\:%S}`; got != want {
+		t.Fatalf("TextLocalTime() = %q, want %q", got, want)
+	}
+
+	f := DrawTextFilter{Input: "0:v", Output: "t", Text: TextLocalTime("%H:%M:%S"), Expand: true}
+	if err := f.Validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	got := f.Parse()
+	// graph and option unescaping leave "%{localtime:%H\:%M\:%S}" for drawtext
+	want := `[0:v]drawtext=text=%{localtime\\:%H\\\\\\:%M\\\\\\:%S}[t]`
+	if got != want {
+		t.Fatalf("parse mismatch: got %q want %q", got, want)
+	}
+}
+
+func TestWithDrawText_EscapesLiteral(t *testing.T) {
+	graph, err := NewComplexFilterBuilder().
+		Add(WithDrawText("0:v", "t", "It's 100%: done", 48, "white@0.9", TextCenterX, TextBottomY)).
+		Build().
+		BuildAndValidate()
+	if err != nil {
+		t.Fatalf("BuildAndValidate() error: %v", err)
+	}
+	want := `[0:v]drawtext=text=It\\\'s 100\\\\%\\: done:fontsize=48:fontcolor=white@0.9:x=(w-text_w)/2:y=h-text_h-th*2[t]`
+	if graph != want {
+		t.Fatalf("graph mismatch:\n got: %s\nwant: %s", graph, want)
+	}
+}
+
+func TestWithTimecode(t *testing.T) {
+	graph, err := NewComplexFilterBuilder().
+		Add(WithTimecode("0:v", "tc", "01:00:00;00", "30000/1001", 32, "10", "10")).
+		Build().
+		BuildAndValidate()
+	if err != nil {
+		t.Fatalf("BuildAndValidate() error: %v", err)
+	}
+	want := `[0:v]drawtext=fontsize=32:fontcolor=white:box=1:boxcolor=black@0.5:x=10:y=10:timecode=01\\:00\\:00\;00:rate=30000/1001[tc]`
+	if graph != want {
+		t.Fatalf("graph mismatch:\n got: %s\nwant: %s", graph, want)
+	}
+}
+
+func TestDrawTextFilter_TextFile(t *testing.T) {
+	f := DrawTextFilter{Input: "0:v", Output: "t", TextFile: "/tmp/title.txt", Font: "Inter", BorderWidth: 2, BorderColor: "0x000000"}
+	if err := f.Validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	got := f.Parse()
+	want := `[0:v]drawtext=textfile=/tmp/title.txt:expansion=none:font=Inter:borderw=2:bordercolor=0x000000[t]`
+	if got != want {
+		t.Fatalf("parse mismatch: got %q want %q", got, want)
+	}
+}
+
+func TestDrawTextFilter_InvalidText(t *testing.T) {
+	cases := []DrawTextFilter{
+		{Input: "a", Output: "b"},
+		{Input: "a", Output: "b", Text: "x", TextFile: "x.txt"},
+	}
+	for i, f := range cases {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("case %d: expected error for text source, got nil", i)
+		}
+	}
+}
+
+func TestDrawTextFilter_InvalidTimecode(t *testing.T) {
+	cases := []DrawTextFilter{
+		{Input: "a", Output: "b", Timecode: "1:00:00", TimecodeRate: "25"},
+		{Input: "a", Output: "b", Timecode: "00:00:00:00"},
+	}
+	for i, f := range cases {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("case %d: expected error for timecode, got nil", i)
+		}
+	}
+}
+
+func TestDrawTextFilter_InvalidColor(t *testing.T) {
+	cases := []DrawTextFilter{
+		{Input: "a", Output: "b", Text: "x", FontColor: "white@2"},
+		{Input: "a", Output: "b", Text: "x", BoxColor: "rgb(0,0,0)"},
+	}
+	for i, f := range cases {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("case %d: expected error for color, got nil", i)
+		}
+	}
+}
+
+func TestDrawTextFilter_InvalidWindow(t *testing.T) {
+	f := DrawTextFilter{Input: "a", Output: "b", Text: "x", Start: 3 * time.Second, End: time.Second}
+	if err := f.Validate(); err == nil {
+		t.Fatalf("expected error for end before start, got nil")
+	}
+}
+
+func TestDrawTextFilter_InvalidLabels(t *testing.T) {
+	f := DrawTextFilter{Input: "a", Text: "x"}
+	if err := f.Validate(); err == nil {
+		t.Fatalf("expected error for missing output label, got nil")
+	}
+}
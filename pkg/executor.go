@@ -1,6 +1,7 @@
 package ffmpego
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -67,9 +68,9 @@ func (c *FfmpegoRunner) Run(ctx context.Context) error {
 	}
 
 	// Set timeout if context doesn't have one
-	if *c.ffmpego.timeout > 0 {
+	if c.ffmpego.timeout > 0 {
 		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, *c.ffmpego.timeout)
+		ctx, cancel = context.WithTimeout(ctx, c.ffmpego.timeout)
 		defer cancel()
 	}
 
@@ -87,6 +88,90 @@ func (c *FfmpegoRunner) Run(ctx context.Context) error {
 	return nil
 }
 
+// Stream executes the FFmpeg command and hands its stdout to consume while it runs,
+// e.g. for outputs written to "pipe:1". Unread output is discarded before waiting.
+func (c *FfmpegoRunner) Stream(ctx context.Context, consume func(io.Reader) error) error {
+	args, err := c.ffmpego.Build()
+	if err != nil {
+		return err
+	}
+
+	if c.ffmpego.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.ffmpego.timeout)
+		defer cancel()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := c.commandRunner.CommandContext(ctx, "ffmpeg", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	consumeErr := consume(stdout)
+	if consumeErr != nil {
+		// Stop ffmpeg instead of waiting for output nobody will read.
+		cancel()
+	}
+	_, _ = io.Copy(io.Discard, stdout)
+
+	if err := cmd.Wait(); err != nil && consumeErr == nil {
+		return fmt.Errorf("ffmpeg failed: %w\nOutput: %s", err, stderr.String())
+	}
+
+	return consumeErr
+}
+
+// Capture executes the FFmpeg command and returns everything it logged to stderr,
+// which is where analysis filters (loudnorm, cropdetect, ...) print their results.
+// When a progress callback is set, stdout is parsed as -progress output (see PipeProgress).
+func (c *FfmpegoRunner) Capture(ctx context.Context) ([]byte, error) {
+	args, err := c.ffmpego.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.ffmpego.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.ffmpego.timeout)
+		defer cancel()
+	}
+
+	cmd := c.commandRunner.CommandContext(ctx, "ffmpeg", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if c.ffmpego.progressCallback != nil {
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("failed to start ffmpeg: %w", err)
+		}
+		parseProgress(stdout, c.ffmpego.progressCallback)
+		err = cmd.Wait()
+	} else {
+		err = cmd.Run()
+	}
+
+	if err != nil {
+		return stderr.Bytes(), fmt.Errorf("ffmpeg failed: %w\nOutput: %s", err, stderr.String())
+	}
+
+	return stderr.Bytes(), nil
+}
+
 // parseProgress parses FFmpeg progress output and calls the callback
 func parseProgress(stderr io.ReadCloser, progressCallback ProgressCallback) {
 	defer stderr.Close()
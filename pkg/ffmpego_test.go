@@ -10,7 +10,9 @@ func TestBuild_SimpleOutput_NoFilters(t *testing.T) {
 		WithVideoCodec("libx264"),
 		WithFile("out.mp4"))
 
-	cmd := New("").Output(output)
+	cmd := New("").
+		WithOptions(NewFfmpegOptions(WithInput("in.mp4"))).
+		Output(output)
 	args, err := cmd.Build()
 	if err != nil {
 		t.Fatalf("Build() error: %v", err)
@@ -30,6 +32,7 @@ func TestBuild_SimpleOutput_NoFilters(t *testing.T) {
 func TestBuild_WithFilters_FilterComplexJoin(t *testing.T) {
 	filterGrap := NewComplexFilterBuilder().
 		Add(WithCrop("0:v", "a", 800, 600, 100, 50)).
+		Add(WithScale("a", "b", 1280, 720)).
 		Build()
 
 	output := NewOutputDescriptor(
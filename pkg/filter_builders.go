@@ -2,6 +2,7 @@ package ffmpego
 
 import (
 	"strings"
+	"time"
 )
 
 // FilterGraph collects FilterComplexParser units before attaching them to Ffmpego.
@@ -27,6 +28,13 @@ func (fg *FilterGraph) BuildAndValidate() (string, error) {
 	return strings.Join(filterParts, ";"), nil
 }
 
+// withFilter wraps an already typed filter unit into a FilterFn.
+func withFilter(filter FilterComplexParser) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(filter)
+	}
+}
+
 // WithFilterExpr adds a raw filter expression (no labels).
 // Example: "scale=1280:-2"
 func WithFilterExpr(expr string) FilterFn {
@@ -107,3 +115,134 @@ func WithSplit(input string, n int, outputs ...string) FilterFn {
 		})
 	}
 }
+
+// WithFPS adds a labeled fps filter chain.
+// Renders: "[input]fps=rate[output]"
+func WithFPS(input string, output string, rate string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(FPSFilter{
+			Input:  strings.TrimSpace(input),
+			Output: strings.TrimSpace(output),
+			Rate:   strings.TrimSpace(rate),
+		})
+	}
+}
+
+// WithFPSRound adds a labeled fps filter chain with a timestamp rounding mode.
+// Renders: "[input]fps=fps=rate:round=mode[output]"
+func WithFPSRound(input string, output string, rate string, round string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(FPSFilter{
+			Input:  strings.TrimSpace(input),
+			Output: strings.TrimSpace(output),
+			Rate:   strings.TrimSpace(rate),
+			Round:  round,
+		})
+	}
+}
+
+// WithTile adds a labeled tile filter chain that packs frames into a columns x rows grid.
+// Renders: "[input]tile=colsxrows[output]"
+func WithTile(input string, output string, columns, rows int) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(TileFilter{
+			Input:   strings.TrimSpace(input),
+			Output:  strings.TrimSpace(output),
+			Columns: columns,
+			Rows:    rows,
+		})
+	}
+}
+
+// WithSelect adds a labeled select filter chain, e.g. "eq(pict_type,I)" or "gt(scene,0.4)".
+// Renders: "[input]select='expr'[output]"
+func WithSelect(input string, output string, expr string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(SelectFilter{
+			Input:  strings.TrimSpace(input),
+			Output: strings.TrimSpace(output),
+			Expr:   strings.TrimSpace(expr),
+		})
+	}
+}
+
+// WithScaleFlags adds a labeled scale filter chain using the given scaler, e.g. "lanczos".
+// Renders: "[input]scale=width:height:flags=flags[output]"
+func WithScaleFlags(input string, output string, width, height int, flags string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(ScaleFilter{
+			Input:  strings.TrimSpace(input),
+			Output: strings.TrimSpace(output),
+			Width:  width,
+			Height: height,
+			Flags:  strings.TrimSpace(flags),
+		})
+	}
+}
+
+// WithScaleExpr adds a labeled scale filter chain with width and height expressions.
+// Renders: "[input]scale=width:height[output]", e.g. "[0:v]scale=trunc(iw/4)*2:-2[half]"
+func WithScaleExpr(input string, output string, width, height string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(ScaleFilter{
+			Input:      strings.TrimSpace(input),
+			Output:     strings.TrimSpace(output),
+			WidthExpr:  strings.TrimSpace(width),
+			HeightExpr: strings.TrimSpace(height),
+		})
+	}
+}
+
+// WithPaletteGen adds a labeled palettegen chain.
+// Renders: "[input]palettegen=max_colors=n:stats_mode=mode[output]"
+func WithPaletteGen(input string, output string, maxColors int, mode PaletteStatsMode) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(PaletteGenFilter{
+			Input:     strings.TrimSpace(input),
+			Output:    strings.TrimSpace(output),
+			MaxColors: maxColors,
+			StatsMode: mode,
+		})
+	}
+}
+
+// WithPaletteUse adds a labeled paletteuse chain mapping input onto palette.
+// Renders: "[input][palette]paletteuse=dither=mode[output]"
+func WithPaletteUse(input string, palette string, output string, dither DitherMode, bayerScale int, diffRectangle bool) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(PaletteUseFilter{
+			Input:         strings.TrimSpace(input),
+			Palette:       strings.TrimSpace(palette),
+			Output:        strings.TrimSpace(output),
+			Dither:        dither,
+			BayerScale:    bayerScale,
+			DiffRectangle: diffRectangle,
+		})
+	}
+}
+
+// WithMetadataPrint adds a labeled metadata=print chain; an empty key prints every entry.
+// Renders: "[input]metadata=print:key=key[output]"
+func WithMetadataPrint(input string, output string, key string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(MetadataPrintFilter{
+			Input:  strings.TrimSpace(input),
+			Output: strings.TrimSpace(output),
+			Key:    strings.TrimSpace(key),
+		})
+	}
+}
+
+// WithTrim adds a labeled frame accurate trim chain; set audio for atrim.
+// Renders: "[input]trim=start=s:end=e,setpts=PTS-STARTPTS[output]"
+func WithTrim(input string, output string, start, end time.Duration, audio bool) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(TrimFilter{
+			Input:  strings.TrimSpace(input),
+			Output: strings.TrimSpace(output),
+			Start:  start,
+			End:    end,
+			Audio:  audio,
+		})
+	}
+}
@@ -2,7 +2,9 @@ package ffmpego
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // TransposeMode enumerates FFmpeg transpose options for rotation.
@@ -37,7 +39,23 @@ func (f LabeledFilter) Validate() error {
 }
 
 func (f LabeledFilter) Parse() string {
-	return fmt.Sprintf("[%s]%s[%s]", strings.Join(f.Inputs, ":"), f.Expr, strings.Join(f.Outputs, ":"))
+	return padLabels(f.Inputs) + f.Expr + padLabels(f.Outputs)
+}
+
+// padLabels renders labels as "[a][b]"; no labels render nothing (e.g. source filters).
+func padLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(labels, "][") + "]"
+}
+
+// escapeFilterValue escapes a filter option value for both parsing levels of a
+// filtergraph: first the option level (\ ' :), then the graph level (\ ' [ ] , ;).
+// Example: "C:\subs\it's.srt" becomes "C\\:\\\\subs\\\\it\\\'s.srt".
+func escapeFilterValue(value string) string {
+	option := strings.NewReplacer(`\`, `\\`, `'`, `\'`, `:`, `\:`).Replace(value)
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`, `[`, `\[`, `]`, `\]`, `,`, `\,`, `;`, `\;`).Replace(option)
 }
 
 func (f UnlabeledFilter) Validate() error {
@@ -49,11 +67,21 @@ func (f UnlabeledFilter) Parse() string {
 }
 
 // ScaleFilter renders: "[input]scale=width:height[output]"
+// With Flags set (e.g. "lanczos") it renders "[input]scale=width:height:flags=lanczos[output]".
+// WidthExpr/HeightExpr replace Width/Height with expressions (e.g. "trunc(iw/4)*2").
+// ForceOriginalAspectRatio ("decrease" or "increase") fits the source aspect ratio into the box,
+// and ForceDivisibleBy then rounds both dimensions to a multiple, e.g. 2 for yuv420p:
+// "[input]scale=1920:1080:force_original_aspect_ratio=decrease:force_divisible_by=2[output]"
 type ScaleFilter struct {
-	Input  string
-	Output string
-	Width  int
-	Height int
+	Input                    string
+	Output                   string
+	Width                    int
+	Height                   int
+	Flags                    string
+	WidthExpr                string
+	HeightExpr               string
+	ForceOriginalAspectRatio string
+	ForceDivisibleBy         int
 }
 
 func (f ScaleFilter) Validate() error {
@@ -63,19 +91,54 @@ func (f ScaleFilter) Validate() error {
 	if strings.TrimSpace(f.Output) == "" {
 		return fmt.Errorf("scale: output label cannot be empty")
 	}
+	return f.validateArgs()
+}
+
+func (f ScaleFilter) validateArgs() error {
 	// FFmpeg scale accepts positive ints or -1/-2 for aspect preservation.
 	validDim := func(v int) bool { return v > 0 || v == -1 || v == -2 }
-	if !validDim(f.Width) {
+	if f.WidthExpr == "" && !validDim(f.Width) {
 		return fmt.Errorf("scale: width must be >0 or -1 or -2, got %d", f.Width)
 	}
-	if !validDim(f.Height) {
+	if f.HeightExpr == "" && !validDim(f.Height) {
 		return fmt.Errorf("scale: height must be >0 or -1 or -2, got %d", f.Height)
 	}
+	if err := oneOf("scale force_original_aspect_ratio", f.ForceOriginalAspectRatio, []string{"disable", "decrease", "increase"}); err != nil {
+		return err
+	}
+	if f.ForceDivisibleBy < 0 {
+		return fmt.Errorf("scale: force_divisible_by must be positive, got %d", f.ForceDivisibleBy)
+	}
+	if f.ForceDivisibleBy > 0 && (f.ForceOriginalAspectRatio == "" || f.ForceOriginalAspectRatio == "disable") {
+		return fmt.Errorf("scale: force_divisible_by only applies with force_original_aspect_ratio")
+	}
 	return nil
 }
 
 func (f ScaleFilter) Parse() string {
-	return fmt.Sprintf("[%s]scale=%d:%d[%s]", f.Input, f.Width, f.Height, f.Output)
+	return fmt.Sprintf("[%s]%s[%s]", f.Input, f.args(), f.Output)
+}
+
+// args renders the unlabeled filter, for use inside a chain.
+func (f ScaleFilter) args() string {
+	width, height := strconv.Itoa(f.Width), strconv.Itoa(f.Height)
+	if f.WidthExpr != "" {
+		width = escapeFilterValue(f.WidthExpr)
+	}
+	if f.HeightExpr != "" {
+		height = escapeFilterValue(f.HeightExpr)
+	}
+	args := "scale=" + width + ":" + height
+	if f.ForceOriginalAspectRatio != "" {
+		args += ":force_original_aspect_ratio=" + f.ForceOriginalAspectRatio
+	}
+	if f.ForceDivisibleBy > 0 {
+		args += ":force_divisible_by=" + strconv.Itoa(f.ForceDivisibleBy)
+	}
+	if f.Flags != "" {
+		args += ":flags=" + f.Flags
+	}
+	return args
 }
 
 // CropFilter renders: "[input]crop=w:h:x:y[output]"
@@ -160,3 +223,249 @@ func (f SplitFilter) Validate() error {
 func (f SplitFilter) Parse() string {
 	return fmt.Sprintf("[%s]split=%d[%s]", f.Input, f.N, strings.Join(f.Outputs, "]["))
 }
+
+// FPSFilter renders: "[input]fps=rate[output]", or "[input]fps=fps=rate:round=near[output]"
+// with a rounding mode. Rate accepts any FFmpeg video rate, e.g. "30", "30000/1001" or
+// "1/5" (one frame every 5s).
+type FPSFilter struct {
+	Input  string
+	Output string
+	Rate   string
+	Round  string // zero, inf, down, up or near (the filter's default)
+}
+
+func (f FPSFilter) Validate() error {
+	if strings.TrimSpace(f.Input) == "" {
+		return fmt.Errorf("fps: input label cannot be empty")
+	}
+	if strings.TrimSpace(f.Output) == "" {
+		return fmt.Errorf("fps: output label cannot be empty")
+	}
+	if strings.TrimSpace(f.Rate) == "" {
+		return fmt.Errorf("fps: rate cannot be empty")
+	}
+	switch f.Round {
+	case "", "zero", "inf", "down", "up", "near":
+	default:
+		return fmt.Errorf("fps: round must be one of zero, inf, down, up or near, got %q", f.Round)
+	}
+	return nil
+}
+
+func (f FPSFilter) Parse() string {
+	if f.Round != "" {
+		return fmt.Sprintf("[%s]fps=fps=%s:round=%s[%s]", f.Input, f.Rate, f.Round, f.Output)
+	}
+	return fmt.Sprintf("[%s]fps=%s[%s]", f.Input, f.Rate, f.Output)
+}
+
+// TileFilter renders: "[input]tile=colsxrows[output]"
+type TileFilter struct {
+	Input   string
+	Output  string
+	Columns int
+	Rows    int
+}
+
+func (f TileFilter) Validate() error {
+	if strings.TrimSpace(f.Input) == "" {
+		return fmt.Errorf("tile: input label cannot be empty")
+	}
+	if strings.TrimSpace(f.Output) == "" {
+		return fmt.Errorf("tile: output label cannot be empty")
+	}
+	if f.Columns <= 0 || f.Rows <= 0 {
+		return fmt.Errorf("tile: columns and rows must be positive, got %dx%d", f.Columns, f.Rows)
+	}
+	return nil
+}
+
+func (f TileFilter) Parse() string {
+	return fmt.Sprintf("[%s]tile=%dx%d[%s]", f.Input, f.Columns, f.Rows, f.Output)
+}
+
+// SelectFilter renders: "[input]select='expr'[output]"
+// The expression is quoted so commas inside it (e.g. "eq(pict_type,I)") are not treated as filter separators.
+type SelectFilter struct {
+	Input  string
+	Output string
+	Expr   string
+}
+
+func (f SelectFilter) Validate() error {
+	if strings.TrimSpace(f.Input) == "" {
+		return fmt.Errorf("select: input label cannot be empty")
+	}
+	if strings.TrimSpace(f.Output) == "" {
+		return fmt.Errorf("select: output label cannot be empty")
+	}
+	if strings.TrimSpace(f.Expr) == "" {
+		return fmt.Errorf("select: expression cannot be empty")
+	}
+	if strings.Contains(f.Expr, "'") {
+		return fmt.Errorf("select: expression cannot contain single quotes: %s", f.Expr)
+	}
+	return nil
+}
+
+func (f SelectFilter) Parse() string {
+	return fmt.Sprintf("[%s]select='%s'[%s]", f.Input, f.Expr, f.Output)
+}
+
+// PaletteStatsMode enumerates palettegen stats_mode values.
+type PaletteStatsMode string
+
+const (
+	// PaletteStatsFull builds one palette from every frame
+	PaletteStatsFull PaletteStatsMode = "full"
+	// PaletteStatsDiff favours the moving parts of the image, good for static backgrounds
+	PaletteStatsDiff PaletteStatsMode = "diff"
+)
+
+// PaletteGenFilter renders: "[input]palettegen=max_colors=n:stats_mode=mode[output]"
+type PaletteGenFilter struct {
+	Input     string
+	Output    string
+	MaxColors int
+	StatsMode PaletteStatsMode
+}
+
+func (f PaletteGenFilter) Validate() error {
+	if strings.TrimSpace(f.Input) == "" {
+		return fmt.Errorf("palettegen: input label cannot be empty")
+	}
+	if strings.TrimSpace(f.Output) == "" {
+		return fmt.Errorf("palettegen: output label cannot be empty")
+	}
+	if f.MaxColors < 2 || f.MaxColors > 256 {
+		return fmt.Errorf("palettegen: max colors must be between 2 and 256, got %d", f.MaxColors)
+	}
+	if f.StatsMode != PaletteStatsFull && f.StatsMode != PaletteStatsDiff {
+		return fmt.Errorf("palettegen: invalid stats mode %q (expected full or diff)", f.StatsMode)
+	}
+	return nil
+}
+
+func (f PaletteGenFilter) Parse() string {
+	return fmt.Sprintf("[%s]palettegen=max_colors=%d:stats_mode=%s[%s]", f.Input, f.MaxColors, f.StatsMode, f.Output)
+}
+
+// DitherMode enumerates paletteuse dither values.
+type DitherMode string
+
+const (
+	DitherBayer          DitherMode = "bayer"
+	DitherHeckbert       DitherMode = "heckbert"
+	DitherFloydSteinberg DitherMode = "floyd_steinberg"
+	DitherSierra2        DitherMode = "sierra2"
+	DitherSierra2_4a     DitherMode = "sierra2_4a"
+	DitherNone           DitherMode = "none"
+)
+
+// PaletteUseFilter renders: "[input][palette]paletteuse=dither=mode[output]"
+// BayerScale (0..5) is only rendered for bayer dithering; DiffRectangle only
+// re-processes the changed rectangle of each frame (pairs with PaletteStatsDiff).
+type PaletteUseFilter struct {
+	Input         string
+	Palette       string
+	Output        string
+	Dither        DitherMode
+	BayerScale    int
+	DiffRectangle bool
+}
+
+func (f PaletteUseFilter) Validate() error {
+	if strings.TrimSpace(f.Input) == "" || strings.TrimSpace(f.Palette) == "" {
+		return fmt.Errorf("paletteuse: input and palette labels cannot be empty")
+	}
+	if strings.TrimSpace(f.Output) == "" {
+		return fmt.Errorf("paletteuse: output label cannot be empty")
+	}
+	switch f.Dither {
+	case DitherBayer, DitherHeckbert, DitherFloydSteinberg, DitherSierra2, DitherSierra2_4a, DitherNone:
+	default:
+		return fmt.Errorf("paletteuse: invalid dither mode %q", f.Dither)
+	}
+	if f.BayerScale < 0 || f.BayerScale > 5 {
+		return fmt.Errorf("paletteuse: bayer scale must be between 0 and 5, got %d", f.BayerScale)
+	}
+	return nil
+}
+
+func (f PaletteUseFilter) Parse() string {
+	opts := "dither=" + string(f.Dither)
+	if f.Dither == DitherBayer {
+		opts += fmt.Sprintf(":bayer_scale=%d", f.BayerScale)
+	}
+	if f.DiffRectangle {
+		opts += ":diff_mode=rectangle"
+	}
+	return fmt.Sprintf("[%s][%s]paletteuse=%s[%s]", f.Input, f.Palette, opts, f.Output)
+}
+
+// MetadataPrintFilter renders: "[input]metadata=print:key=key[output]"
+// It logs frame timing and the given metadata key (e.g. "lavfi.scene_score") for every frame.
+type MetadataPrintFilter struct {
+	Input  string
+	Output string
+	Key    string
+}
+
+func (f MetadataPrintFilter) Validate() error {
+	if strings.TrimSpace(f.Input) == "" {
+		return fmt.Errorf("metadata: input label cannot be empty")
+	}
+	if strings.TrimSpace(f.Output) == "" {
+		return fmt.Errorf("metadata: output label cannot be empty")
+	}
+	if strings.ContainsAny(f.Key, ":=,;[]'") {
+		return fmt.Errorf("metadata: invalid key %q", f.Key)
+	}
+	return nil
+}
+
+func (f MetadataPrintFilter) Parse() string {
+	if f.Key == "" {
+		return fmt.Sprintf("[%s]metadata=print[%s]", f.Input, f.Output)
+	}
+	return fmt.Sprintf("[%s]metadata=print:key=%s[%s]", f.Input, f.Key, f.Output)
+}
+
+// TrimFilter renders: "[input]trim=start=2.5:end=7,setpts=PTS-STARTPTS[output]"
+// With Audio set it renders atrim/asetpts. Timestamps restart at zero after the cut;
+// a zero End keeps everything after Start.
+type TrimFilter struct {
+	Input  string
+	Output string
+	Start  time.Duration
+	End    time.Duration
+	Audio  bool
+}
+
+func (f TrimFilter) Validate() error {
+	if strings.TrimSpace(f.Input) == "" {
+		return fmt.Errorf("trim: input label cannot be empty")
+	}
+	if strings.TrimSpace(f.Output) == "" {
+		return fmt.Errorf("trim: output label cannot be empty")
+	}
+	if f.Start < 0 {
+		return fmt.Errorf("trim: start must be non-negative, got %s", f.Start)
+	}
+	if f.End != 0 && f.End <= f.Start {
+		return fmt.Errorf("trim: end %s must be after start %s", f.End, f.Start)
+	}
+	return nil
+}
+
+func (f TrimFilter) Parse() string {
+	trim, setpts := "trim", "setpts"
+	if f.Audio {
+		trim, setpts = "atrim", "asetpts"
+	}
+	opts := "start=" + formatSeconds(f.Start)
+	if f.End > 0 {
+		opts += ":end=" + formatSeconds(f.End)
+	}
+	return fmt.Sprintf("[%s]%s=%s,%s=PTS-STARTPTS[%s]", f.Input, trim, opts, setpts, f.Output)
+}
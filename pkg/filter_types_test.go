@@ -82,3 +82,15 @@ func TestSplitFilter_InvalidOutputsCount(t *testing.T) {
 		t.Fatalf("expected error for outputs count != n, got nil")
 	}
 }
+
+func TestLabeledFilter_Parse(t *testing.T) {
+	cases := map[string]LabeledFilter{
+		"[a][b]overlay[out]":       {Inputs: []string{"a", "b"}, Expr: "overlay", Outputs: []string{"out"}},
+		"anullsrc=r=48000[silent]": {Expr: "anullsrc=r=48000", Outputs: []string{"silent"}},
+	}
+	for want, f := range cases {
+		if got := f.Parse(); got != want {
+			t.Fatalf("parse mismatch: got %q want %q", got, want)
+		}
+	}
+}
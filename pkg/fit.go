@@ -0,0 +1,127 @@
+package ffmpego
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FitMode selects how a source is fitted into a target box of a different aspect ratio
+type FitMode string
+
+const (
+	// FitContain scales the whole picture into the box and pads the rest (letterbox/pillarbox).
+	FitContain FitMode = "contain"
+	// FitCover scales the picture to fill the box and crops the overflow.
+	FitCover FitMode = "cover"
+	// FitStretch scales to the box, ignoring the aspect ratio.
+	FitStretch FitMode = "stretch"
+	// FitBlurredBackground contains the picture over a blurred, cropped copy of itself.
+	FitBlurredBackground FitMode = "blurred-background"
+)
+
+// FitFilter fits its input into Width x Height, always with square pixels (setsar=1). The
+// aspect preserving modes first resample anamorphic sources to square pixels, since
+// force_original_aspect_ratio only looks at the storage size:
+//
+//	contain: [in]scale=iw*sar:ih,setsar=1,scale=1080:1920:force_original_aspect_ratio=decrease:force_divisible_by=2,pad=1080:1920:(ow-iw)/2:(oh-ih)/2:color=black,setsar=1[out]
+//	cover:   [in]scale=iw*sar:ih,setsar=1,scale=1080:1920:force_original_aspect_ratio=increase:force_divisible_by=2,crop=1080:1920,setsar=1[out]
+//	stretch: [in]scale=1080:1920,setsar=1[out]
+//
+// The blurred background mode splits the squared input into Output_bg and Output_fg, blurs
+// a covering copy (boxblur radius Blur, 20 when unset) and overlays the contained picture
+// centered on it. PadColor (black when unset) only applies to contain and Flags (e.g.
+// "lanczos", "bicubic") to every scale.
+type FitFilter struct {
+	Input    string
+	Output   string
+	Width    int
+	Height   int
+	Mode     FitMode
+	PadColor string
+	Flags    string
+	Blur     int
+}
+
+func (f FitFilter) Validate() error {
+	if err := checkLabels("fit", f.Input, f.Output); err != nil {
+		return err
+	}
+	if f.Width <= 0 || f.Height <= 0 || f.Width%2 != 0 || f.Height%2 != 0 {
+		return fmt.Errorf("fit: width and height must be positive and even, got %dx%d", f.Width, f.Height)
+	}
+	switch f.Mode {
+	case FitContain, FitCover, FitStretch, FitBlurredBackground:
+	default:
+		return fmt.Errorf("fit: unknown mode %q", string(f.Mode))
+	}
+	if err := validateColor(f.PadColor); err != nil {
+		return fmt.Errorf("fit: pad %w", err)
+	}
+	if f.Blur < 0 {
+		return fmt.Errorf("fit: blur radius must be non-negative, got %d", f.Blur)
+	}
+	if blur := f.blurRadius(); f.Mode == FitBlurredBackground && blur*2 > min(f.Width, f.Height)/2 {
+		return fmt.Errorf("fit: blur radius %d exceeds a quarter of the smaller dimension of %dx%d", blur, f.Width, f.Height)
+	}
+	return nil
+}
+
+// blurRadius returns the boxblur radius of the blurred background, 20 when Blur is unset.
+func (f FitFilter) blurRadius() int {
+	if f.Blur == 0 {
+		return 20
+	}
+	return f.Blur
+}
+
+// scale returns the scale to the target box with the given aspect ratio handling.
+func (f FitFilter) scale(aspect string) string {
+	scale := ScaleFilter{Width: f.Width, Height: f.Height, Flags: f.Flags, ForceOriginalAspectRatio: aspect}
+	if aspect != "" {
+		scale.ForceDivisibleBy = 2
+	}
+	return scale.args()
+}
+
+// square resamples the input to square pixels, keeping its height.
+func (f FitFilter) square() string {
+	return ScaleFilter{WidthExpr: "iw*sar", HeightExpr: "ih", Flags: f.Flags}.args() + ",setsar=1"
+}
+
+func (f FitFilter) Parse() string {
+	box := fmt.Sprintf("%d:%d", f.Width, f.Height)
+	switch f.Mode {
+	case FitContain:
+		color := f.PadColor
+		if color == "" {
+			color = "black"
+		}
+		return fmt.Sprintf("[%s]%s,%s,pad=%s:(ow-iw)/2:(oh-ih)/2:color=%s,setsar=1[%s]",
+			f.Input, f.square(), f.scale("decrease"), box, color, f.Output)
+	case FitCover:
+		return fmt.Sprintf("[%s]%s,%s,crop=%s,setsar=1[%s]", f.Input, f.square(), f.scale("increase"), box, f.Output)
+	case FitBlurredBackground:
+		blur := f.blurRadius()
+		bg, fg := f.Output+"_bg", f.Output+"_fg"
+		return strings.Join([]string{
+			fmt.Sprintf("[%s]%s,split=2[%s][%s]", f.Input, f.square(), bg, fg),
+			fmt.Sprintf("[%s]%s,crop=%s,boxblur=%d:1[%s_blur]", bg, f.scale("increase"), box, blur, bg),
+			fmt.Sprintf("[%s]%s[%s_fit]", fg, f.scale("decrease"), fg),
+			fmt.Sprintf("[%s_blur][%s_fit]overlay=x=(main_w-overlay_w)/2:y=(main_h-overlay_h)/2,setsar=1[%s]", bg, fg, f.Output),
+		}, ";")
+	}
+	return fmt.Sprintf("[%s]%s,setsar=1[%s]", f.Input, f.scale(""), f.Output)
+}
+
+// Labeled returns a FilterFn adding the fit between input and output.
+func (f FitFilter) Labeled(input, output string) FilterFn {
+	f.Input = strings.TrimSpace(input)
+	f.Output = strings.TrimSpace(output)
+	return withFilter(f)
+}
+
+// Fit adds a filter fitting input into width x height with mode, e.g.
+// Fit("0:v", "v", 1080, 1920, FitBlurredBackground) for a vertical export of landscape footage.
+func Fit(input string, output string, width, height int, mode FitMode) FilterFn {
+	return FitFilter{Width: width, Height: height, Mode: mode}.Labeled(input, output)
+}
@@ -0,0 +1,121 @@
+package ffmpego
+
+import "testing"
+
+func TestFitFilter_ValidateParse(t *testing.T) {
+	f := FitFilter{Input: "0:v", Output: "v", Width: 1920, Height: 1080, Mode: FitContain, PadColor: "white", Flags: "lanczos"}
+	if err := f.Validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	got := f.Parse()
+	want := "[0:v]scale=iw*sar:ih:flags=lanczos,setsar=1," +
+		"scale=1920:1080:force_original_aspect_ratio=decrease:force_divisible_by=2:flags=lanczos," +
+		"pad=1920:1080:(ow-iw)/2:(oh-ih)/2:color=white,setsar=1[v]"
+	if got != want {
+		t.Fatalf("parse mismatch: got %q want %q", got, want)
+	}
+}
+
+func TestFit_Cover(t *testing.T) {
+	graph, err := NewComplexFilterBuilder().Add(Fit("0:v", "v", 1080, 1920, FitCover)).Build().BuildAndValidate()
+	if err != nil {
+		t.Fatalf("BuildAndValidate() error: %v", err)
+	}
+	want := "[0:v]scale=iw*sar:ih,setsar=1,scale=1080:1920:force_original_aspect_ratio=increase:force_divisible_by=2,crop=1080:1920,setsar=1[v]"
+	if graph != want {
+		t.Fatalf("graph mismatch:\n got: %s\nwant: %s", graph, want)
+	}
+}
+
+func TestFit_Stretch(t *testing.T) {
+	graph, err := NewComplexFilterBuilder().Add(Fit("0:v", "v", 1280, 720, FitStretch)).Build().BuildAndValidate()
+	if err != nil {
+		t.Fatalf("BuildAndValidate() error: %v", err)
+	}
+	want := "[0:v]scale=1280:720,setsar=1[v]"
+	if graph != want {
+		t.Fatalf("graph mismatch:\n got: %s\nwant: %s", graph, want)
+	}
+}
+
+func TestFit_BlurredBackground(t *testing.T) {
+	graph, err := NewComplexFilterBuilder().Add(Fit("0:v", "v", 1080, 1920, FitBlurredBackground)).Build().BuildAndValidate()
+	if err != nil {
+		t.Fatalf("BuildAndValidate() error: %v", err)
+	}
+	want := "[0:v]scale=iw*sar:ih,setsar=1,split=2[v_bg][v_fg];" +
+		"[v_bg]scale=1080:1920:force_original_aspect_ratio=increase:force_divisible_by=2,crop=1080:1920,boxblur=20:1[v_bg_blur];" +
+		"[v_fg]scale=1080:1920:force_original_aspect_ratio=decrease:force_divisible_by=2[v_fg_fit];" +
+		"[v_bg_blur][v_fg_fit]overlay=x=(main_w-overlay_w)/2:y=(main_h-overlay_h)/2,setsar=1[v]"
+	if graph != want {
+		t.Fatalf("graph mismatch:\n got: %s\nwant: %s", graph, want)
+	}
+}
+
+func TestFitFilter_InvalidDims(t *testing.T) {
+	cases := []FitFilter{
+		{Input: "a", Output: "b", Width: 1079, Height: 1920, Mode: FitContain},
+		{Input: "a", Output: "b", Mode: FitCover},
+	}
+	for i, f := range cases {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("case %d: expected error for invalid dims, got nil", i)
+		}
+	}
+}
+
+func TestFitFilter_InvalidMode(t *testing.T) {
+	f := FitFilter{Input: "a", Output: "b", Width: 1280, Height: 720, Mode: "zoom"}
+	if err := f.Validate(); err == nil {
+		t.Fatalf("expected error for unknown mode, got nil")
+	}
+}
+
+func TestFitFilter_InvalidPadColor(t *testing.T) {
+	f := FitFilter{Input: "a", Output: "b", Width: 1280, Height: 720, Mode: FitContain, PadColor: "black@3"}
+	if err := f.Validate(); err == nil {
+		t.Fatalf("expected error for invalid pad color, got nil")
+	}
+}
+
+func TestFitFilter_InvalidBlur(t *testing.T) {
+	f := FitFilter{Input: "a", Output: "b", Width: 128, Height: 72, Mode: FitBlurredBackground, Blur: 40}
+	if err := f.Validate(); err == nil {
+		t.Fatalf("expected error for blur radius, got nil")
+	}
+}
+
+func TestFitFilter_InvalidDefaultBlur(t *testing.T) {
+	f := FitFilter{Input: "a", Output: "b", Width: 64, Height: 64, Mode: FitBlurredBackground}
+	if err := f.Validate(); err == nil {
+		t.Fatalf("expected error for the default blur radius on a 64x64 box, got nil")
+	}
+	f.Mode = FitContain
+	if err := f.Validate(); err != nil {
+		t.Fatalf("validate failed for contain: %v", err)
+	}
+}
+
+func TestScaleFilter_Extensions(t *testing.T) {
+	graph, err := NewComplexFilterBuilder().
+		Add(WithScaleExpr("0:v", "half", "min(iw,1280)", "-2")).
+		Build().
+		BuildAndValidate()
+	if err != nil {
+		t.Fatalf("BuildAndValidate() error: %v", err)
+	}
+	if want := `[0:v]scale=min(iw\,1280):-2[half]`; graph != want {
+		t.Fatalf("graph = %q, want %q", graph, want)
+	}
+
+	invalid := []ScaleFilter{
+		{Input: "a", Output: "b", Width: 1280, Height: 720, ForceOriginalAspectRatio: "shrink"},
+		{Input: "a", Output: "b", Width: 1280, Height: 720, ForceDivisibleBy: 2},
+		{Input: "a", Output: "b", WidthExpr: "iw/2", Height: 0},
+	}
+	for _, f := range invalid {
+		if err := f.Validate(); err == nil {
+			t.Errorf("expected error for %+v, got nil", f)
+		}
+	}
+}
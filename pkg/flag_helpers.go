@@ -1,5 +1,7 @@
 package ffmpego
 
+import "time"
+
 var (
 	PipeProgress = WithProgress("pipe:1")
 )
@@ -10,6 +12,45 @@ func WithInput(input ...string) FfmpegFlagFn {
 	}
 }
 
+// WithInputFile adds an input with its own options rendered before '-i'.
+func WithInputFile(path string, opts ...InputFlagFn) FfmpegFlagFn {
+	return func(options *FfmpegOptions) {
+		input := &InputFile{Path: path, Options: make([]FfmpegFlagParser, 0)}
+		for _, fn := range opts {
+			fn(input)
+		}
+		options.Add(*input)
+	}
+}
+
+// WithSeek adds a fast input seek ('-ss' before '-i').
+func WithSeek(position time.Duration) InputFlagFn {
+	return func(input *InputFile) {
+		input.Add(Seek(position))
+	}
+}
+
+// WithInputDuration limits how much of the input is read ('-t' before '-i').
+func WithInputDuration(duration time.Duration) InputFlagFn {
+	return func(input *InputFile) {
+		input.Add(InputDuration(duration))
+	}
+}
+
+// WithInputFormat forces the input demuxer ('-f' before '-i').
+func WithInputFormat(format string) InputFlagFn {
+	return func(input *InputFile) {
+		input.Add(InputFormat(format))
+	}
+}
+
+// WithInputLoop loops a still image input ('-loop 1' before '-i'), e.g. a watermark logo.
+func WithInputLoop() InputFlagFn {
+	return func(input *InputFile) {
+		input.Add(InputLoop(1))
+	}
+}
+
 // Adds new '-y' flag to ffmpeg command.
 func WithOverwrite() FfmpegFlagFn {
 	return func(options *FfmpegOptions) {
@@ -28,3 +69,17 @@ func WithProgress(progress string) FfmpegFlagFn {
 		options.Add(Output(progress))
 	}
 }
+
+// WithCopyTS adds '-copyts', keeping the input timestamps instead of starting at zero.
+func WithCopyTS() FfmpegFlagFn {
+	return func(options *FfmpegOptions) {
+		options.Add(CopyTS{})
+	}
+}
+
+// WithStartAtZero adds '-start_at_zero'; combine it with WithCopyTS.
+func WithStartAtZero() FfmpegFlagFn {
+	return func(options *FfmpegOptions) {
+		options.Add(StartAtZero{})
+	}
+}
@@ -1,5 +1,10 @@
 package ffmpego
 
+import (
+	"fmt"
+	"time"
+)
+
 type FfmpegOptions struct {
 	flags []FfmpegFlagParser
 }
@@ -59,6 +64,98 @@ func (input Input) Parse() []string {
 	return inputs
 }
 
+// InputFile renders per-input options followed by "-i path", so options such as
+// -ss apply to this input only (input seeking).
+type InputFile struct {
+	Path    string
+	Options []FfmpegFlagParser
+}
+
+type InputFlagFn = func(*InputFile)
+
+// Add appends an input option placed before "-i".
+func (in *InputFile) Add(option FfmpegFlagParser) {
+	in.Options = append(in.Options, option)
+}
+
+func (in InputFile) Validate() error {
+	if in.Path == "" {
+		return fmt.Errorf("input path cannot be empty")
+	}
+	for _, option := range in.Options {
+		if err := option.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (in InputFile) Parse() []string {
+	var args []string
+	for _, option := range in.Options {
+		args = append(args, option.Parse()...)
+	}
+
+	return append(args, "-i", in.Path)
+}
+
+// Seek represents an input -ss option
+type Seek time.Duration
+
+func (s Seek) Validate() error {
+	if s < 0 {
+		return fmt.Errorf("seek position must be non-negative, got %s", time.Duration(s))
+	}
+	return nil
+}
+
+func (s Seek) Parse() []string {
+	return []string{"-ss", FormatTimestamp(time.Duration(s))}
+}
+
+// InputDuration represents an input -t option limiting how much of the input is read
+type InputDuration time.Duration
+
+func (d InputDuration) Validate() error {
+	if d <= 0 {
+		return fmt.Errorf("input duration must be positive, got %s", time.Duration(d))
+	}
+	return nil
+}
+
+func (d InputDuration) Parse() []string {
+	return []string{"-t", FormatTimestamp(time.Duration(d))}
+}
+
+// InputFormat represents an input -f option
+type InputFormat string
+
+func (f InputFormat) Validate() error {
+	if f == "" {
+		return fmt.Errorf("input format cannot be empty")
+	}
+	return nil
+}
+
+func (f InputFormat) Parse() []string {
+	return []string{"-f", string(f)}
+}
+
+// InputLoop represents an input -loop option of the image demuxer; 1 repeats a still image
+// as a video stream
+type InputLoop int
+
+func (l InputLoop) Validate() error {
+	if l != 0 && l != 1 {
+		return fmt.Errorf("input loop must be 0 or 1, got %d", int(l))
+	}
+	return nil
+}
+
+func (l InputLoop) Parse() []string {
+	return []string{"-loop", fmt.Sprintf("%d", int(l))}
+}
+
 type Overwrite struct{}
 
 func (ow Overwrite) Validate() error {
@@ -88,3 +185,26 @@ func (ll Output) Validate() error {
 func (ll Output) Parse() []string {
 	return []string{"-progress", string(ll)}
 }
+
+// CopyTS represents the global -copyts option, keeping input timestamps
+type CopyTS struct{}
+
+func (c CopyTS) Validate() error {
+	return nil
+}
+
+func (c CopyTS) Parse() []string {
+	return []string{"-copyts"}
+}
+
+// StartAtZero represents the global -start_at_zero option; with -copyts it shifts
+// input timestamps so they start at zero
+type StartAtZero struct{}
+
+func (s StartAtZero) Validate() error {
+	return nil
+}
+
+func (s StartAtZero) Parse() []string {
+	return []string{"-start_at_zero"}
+}
@@ -0,0 +1,336 @@
+package ffmpego
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"time"
+)
+
+// FrameSelection enumerates how a FrameExtractor picks frames.
+type FrameSelection int
+
+const (
+	// FramesAtTimestamps grabs one frame per timestamp using a fast input seek each.
+	FramesAtTimestamps FrameSelection = iota
+	// FramesEvery grabs one frame every interval (fps=1/N).
+	FramesEvery
+	// FramesKeyframes grabs only I-frames (select='eq(pict_type,I)').
+	FramesKeyframes
+	// FramesSceneChanges grabs frames whose scene score exceeds a threshold (select='gt(scene,T)').
+	FramesSceneChanges
+)
+
+// FrameFormat selects how frames are piped back when decoding into images.
+type FrameFormat int
+
+const (
+	// FramePNG pipes PNG images through image2pipe.
+	FramePNG FrameFormat = iota
+	// FrameRawRGBA pipes raw RGBA frames; requires an explicit Scale(width, height).
+	FrameRawRGBA
+)
+
+// FrameExtractor builds frame grabbing commands that either write files using a
+// naming template or decode frames piped to stdout into image.Image values.
+type FrameExtractor struct {
+	input      string
+	selection  FrameSelection
+	selected   bool
+	timestamps []time.Duration
+	interval   time.Duration
+	threshold  float64
+	width      int
+	height     int
+	format     FrameFormat
+	overwrite  bool
+	version    FFmpegVersion
+}
+
+// ExtractFrames creates a frame extractor for input. Pick a selection with At, Every,
+// Keyframes or SceneChanges before building.
+func ExtractFrames(input string) *FrameExtractor {
+	return &FrameExtractor{input: input, format: FramePNG}
+}
+
+// At grabs a frame at each timestamp.
+func (e *FrameExtractor) At(timestamps ...time.Duration) *FrameExtractor {
+	e.selection, e.selected = FramesAtTimestamps, true
+	e.timestamps = timestamps
+	return e
+}
+
+// Every grabs a frame every interval.
+func (e *FrameExtractor) Every(interval time.Duration) *FrameExtractor {
+	e.selection, e.selected = FramesEvery, true
+	e.interval = interval
+	return e
+}
+
+// Keyframes grabs every I-frame.
+func (e *FrameExtractor) Keyframes() *FrameExtractor {
+	e.selection, e.selected = FramesKeyframes, true
+	return e
+}
+
+// SceneChanges grabs frames with a scene change score above threshold (0..1).
+func (e *FrameExtractor) SceneChanges(threshold float64) *FrameExtractor {
+	e.selection, e.selected = FramesSceneChanges, true
+	e.threshold = threshold
+	return e
+}
+
+// Scale resizes extracted frames; -1/-2 keep the aspect ratio as in ScaleFilter.
+func (e *FrameExtractor) Scale(width, height int) *FrameExtractor {
+	e.width, e.height = width, height
+	return e
+}
+
+// Format selects the pipe encoding used by Images.
+func (e *FrameExtractor) Format(format FrameFormat) *FrameExtractor {
+	e.format = format
+	return e
+}
+
+// Overwrite adds '-y' to generated commands.
+func (e *FrameExtractor) Overwrite() *FrameExtractor {
+	e.overwrite = true
+	return e
+}
+
+// Version sets the ffmpeg release the commands target, so releases before 5.1 get -vsync
+// instead of -fps_mode. An unset version is assumed to be recent.
+func (e *FrameExtractor) Version(version FFmpegVersion) *FrameExtractor {
+	e.version = version
+	return e
+}
+
+// Validate validates the extractor configuration
+func (e *FrameExtractor) Validate() error {
+	if strings.TrimSpace(e.input) == "" {
+		return fmt.Errorf("frames: input cannot be empty")
+	}
+	if !e.selected {
+		return fmt.Errorf("frames: no selection configured (use At, Every, Keyframes or SceneChanges)")
+	}
+
+	switch e.selection {
+	case FramesAtTimestamps:
+		if len(e.timestamps) == 0 {
+			return fmt.Errorf("frames: at least one timestamp is required")
+		}
+		for _, ts := range e.timestamps {
+			if ts < 0 {
+				return fmt.Errorf("frames: timestamp must be non-negative, got %s", ts)
+			}
+		}
+	case FramesEvery:
+		if e.interval <= 0 {
+			return fmt.Errorf("frames: interval must be positive, got %s", e.interval)
+		}
+	case FramesSceneChanges:
+		if e.threshold <= 0 || e.threshold >= 1 {
+			return fmt.Errorf("frames: scene threshold must be between 0 and 1, got %v", e.threshold)
+		}
+	}
+
+	if e.format == FrameRawRGBA && (e.width <= 0 || e.height <= 0) {
+		return fmt.Errorf("frames: raw RGBA frames require explicit positive Scale dimensions")
+	}
+	return nil
+}
+
+// Command builds a single command writing frames to files. For timestamp selection
+// pattern is formatted with the 1-based timestamp index (one input and output per
+// timestamp); otherwise it is passed to the image2 muxer, e.g. "frame_%04d.jpg".
+func (e *FrameExtractor) Command(pattern string) (*Ffmpego, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	if !strings.Contains(pattern, "%") {
+		return nil, fmt.Errorf("frames: pattern %q must contain a numeric placeholder", pattern)
+	}
+
+	if e.selection != FramesAtTimestamps {
+		return e.command(pattern), nil
+	}
+
+	flags := make([]FfmpegFlagFn, 0, len(e.timestamps)+1)
+	if e.overwrite {
+		flags = append(flags, WithOverwrite())
+	}
+	graph := NewComplexFilterBuilder()
+	cmd := New("")
+	for i, ts := range e.timestamps {
+		flags = append(flags, WithInputFile(e.input, WithSeek(ts)))
+		source := e.frameChain(graph, fmt.Sprintf("%d:v:0", i), fmt.Sprintf("frame%d", i), false)
+		cmd.Output(NewOutputBuilder().
+			WithFlag(WithMap(source)).
+			WithFlag(WithFrames(1)).
+			File(fmt.Sprintf(pattern, i+1)).
+			Build())
+	}
+
+	return cmd.
+		WithOptions(NewFfmpegOptions(flags...)).
+		WithFilterGraph(graph.Build()), nil
+}
+
+// WriteFiles runs Command(pattern).
+func (e *FrameExtractor) WriteFiles(ctx context.Context, pattern string) error {
+	cmd, err := e.Command(pattern)
+	if err != nil {
+		return err
+	}
+
+	return NewRunner(cmd).Run(ctx)
+}
+
+// Images runs the extraction and decodes the frames piped to stdout. Timestamp
+// selection runs one fast-seeking command per timestamp, in order.
+func (e *FrameExtractor) Images(ctx context.Context) ([]image.Image, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+
+	if e.selection != FramesAtTimestamps {
+		return e.stream(ctx, e.command("pipe:1"))
+	}
+
+	images := make([]image.Image, 0, len(e.timestamps))
+	for _, ts := range e.timestamps {
+		frames, err := e.stream(ctx, e.seekCommand(ts))
+		if err != nil {
+			return nil, err
+		}
+		if len(frames) == 0 {
+			return nil, fmt.Errorf("frames: no frame decoded at %s", FormatTimestamp(ts))
+		}
+		images = append(images, frames[0])
+	}
+	return images, nil
+}
+
+// command builds a single-input command for every selection but timestamps.
+// Writing to "pipe:1" switches the output to the configured pipe format.
+func (e *FrameExtractor) command(target string) *Ffmpego {
+	flags := []FfmpegFlagFn{WithInput(e.input)}
+	if e.overwrite {
+		flags = append(flags, WithOverwrite())
+	}
+
+	graph := NewComplexFilterBuilder()
+	switch e.selection {
+	case FramesEvery:
+		graph.Add(WithFPS("0:v:0", "selected", "1/"+formatSeconds(e.interval)))
+	case FramesKeyframes:
+		graph.Add(WithSelect("0:v:0", "selected", "eq(pict_type,I)"))
+	case FramesSceneChanges:
+		graph.Add(WithSelect("0:v:0", "selected", fmt.Sprintf("gt(scene,%s)", formatFloat(e.threshold))))
+	}
+	pipe := target == "pipe:1"
+	source := e.frameChain(graph, "selected", "frames", pipe)
+
+	// image2 defaults to constant frame rate and would duplicate selected frames.
+	out := NewOutputBuilder().
+		WithFlag(WithMap(source)).
+		WithFlag(WithVideoSync("vfr", e.version))
+	if pipe {
+		e.pipeFlags(out)
+	}
+
+	return New("").
+		WithOptions(NewFfmpegOptions(flags...)).
+		WithFilterGraph(graph.Build()).
+		Output(out.File(target).Build())
+}
+
+// seekCommand builds a command piping the single frame at ts to stdout.
+func (e *FrameExtractor) seekCommand(ts time.Duration) *Ffmpego {
+	graph := NewComplexFilterBuilder()
+	source := e.frameChain(graph, "0:v:0", "frames", true)
+	out := NewOutputBuilder().
+		WithFlag(WithMap(source)).
+		WithFlag(WithFrames(1))
+	e.pipeFlags(out)
+
+	return New("").
+		WithOptions(NewFfmpegOptions(WithInputFile(e.input, WithSeek(ts)))).
+		WithFilterGraph(graph.Build()).
+		Output(out.File("pipe:1").Build())
+}
+
+// frameChain adds the optional scale (and RGBA conversion when piping raw frames)
+// after input and returns what the output should map.
+func (e *FrameExtractor) frameChain(graph *FilterGraphBuilder, input, output string, pipe bool) string {
+	target := input
+	if e.width != 0 || e.height != 0 {
+		graph.Add(WithScale(input, output, e.width, e.height))
+		target = output
+	}
+	if pipe && e.format == FrameRawRGBA {
+		graph.Chain(target, "format=rgba", output+"_rgba")
+		target = output + "_rgba"
+	}
+
+	// stream specifiers such as "0:v:0" are mapped as is, graph labels need brackets
+	if strings.Contains(target, ":") {
+		return target
+	}
+	return "[" + target + "]"
+}
+
+// pipeFlags configures the output for image2pipe PNG or raw RGBA frames.
+func (e *FrameExtractor) pipeFlags(out *OutputBuilder) {
+	if e.format == FrameRawRGBA {
+		out.WithFlag(WithFormat("rawvideo")).WithFlag(WithVideoCodec("rawvideo"))
+		return
+	}
+	out.WithFlag(WithFormat("image2pipe")).WithFlag(WithVideoCodec("png"))
+}
+
+func (e *FrameExtractor) stream(ctx context.Context, cmd *Ffmpego) ([]image.Image, error) {
+	var images []image.Image
+	err := NewRunner(cmd).Stream(ctx, func(r io.Reader) error {
+		var err error
+		images, err = decodeFrames(r, e.format, e.width, e.height)
+		return err
+	})
+	return images, err
+}
+
+// decodeFrames reads consecutive PNG images or fixed-size RGBA frames until EOF.
+func decodeFrames(r io.Reader, format FrameFormat, width, height int) ([]image.Image, error) {
+	var images []image.Image
+	if format == FrameRawRGBA {
+		size := width * height * 4
+		for {
+			img := image.NewRGBA(image.Rect(0, 0, width, height))
+			_, err := io.ReadFull(r, img.Pix[:size])
+			if errors.Is(err, io.EOF) {
+				return images, nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("frames: truncated raw frame: %w", err)
+			}
+			images = append(images, img)
+		}
+	}
+
+	br := bufio.NewReader(r)
+	for {
+		if _, err := br.Peek(1); errors.Is(err, io.EOF) {
+			return images, nil
+		}
+		img, err := png.Decode(br)
+		if err != nil {
+			return nil, fmt.Errorf("frames: failed to decode piped frame: %w", err)
+		}
+		images = append(images, img)
+	}
+}
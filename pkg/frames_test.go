@@ -0,0 +1,98 @@
+package ffmpego
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFrameExtractor_AtTimestamps(t *testing.T) {
+	cmd, err := ExtractFrames("in.mp4").
+		At(1500*time.Millisecond, 90*time.Second).
+		Scale(320, -2).
+		Command("poster_%02d.jpg")
+	if err != nil {
+		t.Fatalf("Command() error: %v", err)
+	}
+
+	args, err := cmd.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	got := strings.Join(args, " ")
+	want := "-ss 00:00:01.500 -i in.mp4 -ss 00:01:30.000 -i in.mp4 " +
+		"-filter_complex [0:v:0]scale=320:-2[frame0];[1:v:0]scale=320:-2[frame1] " +
+		"-map [frame0] -frames:v 1 poster_01.jpg -map [frame1] -frames:v 1 poster_02.jpg"
+	if got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestFrameExtractor_KeyframesPipe(t *testing.T) {
+	cmd := ExtractFrames("in.mp4").Keyframes().command("pipe:1")
+	args, err := cmd.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	got := strings.Join(args, " ")
+	want := "-i in.mp4 -filter_complex [0:v:0]select='eq(pict_type,I)'[selected] " +
+		"-map [selected] -fps_mode vfr -f image2pipe -c:v png pipe:1"
+	if got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestFrameExtractor_VideoSyncForVersion(t *testing.T) {
+	cmd := ExtractFrames("in.mp4").Every(time.Second).Version(FFmpegVersion{Major: 4, Minor: 4, Raw: "4.4"}).command("frame_%03d.jpg")
+	args, err := cmd.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	got := strings.Join(args, " ")
+	want := "-i in.mp4 -filter_complex [0:v:0]fps=1/1[selected] -map [selected] -vsync vfr frame_%03d.jpg"
+	if got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestFrameExtractor_RawRequiresSize(t *testing.T) {
+	if err := ExtractFrames("in.mp4").Every(time.Second).Format(FrameRawRGBA).Validate(); err == nil {
+		t.Fatalf("expected error for raw frames without explicit size, got nil")
+	}
+}
+
+func TestDecodeFrames(t *testing.T) {
+	var buf bytes.Buffer
+	for _, c := range []color.Gray{{Y: 10}, {Y: 200}} {
+		img := image.NewGray(image.Rect(0, 0, 2, 2))
+		img.SetGray(0, 0, c)
+		if err := png.Encode(&buf, img); err != nil {
+			t.Fatalf("png.Encode() error: %v", err)
+		}
+	}
+
+	images, err := decodeFrames(&buf, FramePNG, 0, 0)
+	if err != nil {
+		t.Fatalf("decodeFrames() error: %v", err)
+	}
+	if len(images) != 2 {
+		t.Fatalf("expected 2 images, got %d", len(images))
+	}
+
+	raw := bytes.Repeat([]byte{1, 2, 3, 4}, 2*2*3)
+	images, err = decodeFrames(bytes.NewReader(raw), FrameRawRGBA, 2, 2)
+	if err != nil || len(images) != 3 {
+		t.Fatalf("expected 3 raw frames, got %d (err=%v)", len(images), err)
+	}
+
+	if _, err := decodeFrames(bytes.NewReader(raw[:10]), FrameRawRGBA, 2, 2); err == nil {
+		t.Fatalf("expected error for truncated raw frame, got nil")
+	}
+}
@@ -0,0 +1,267 @@
+package ffmpego
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// AnimationFormat selects the animated image format produced by AnimatedImage.
+type AnimationFormat int
+
+const (
+	// AnimationGIF uses the palettegen/paletteuse pipeline.
+	AnimationGIF AnimationFormat = iota
+	// AnimationWebP encodes with libwebp, no palette needed.
+	AnimationWebP
+)
+
+// maxSizeAttempts bounds how many encodes Run performs while targeting a file size.
+const maxSizeAttempts = 8
+
+// AnimatedImage builds high quality GIF or animated WebP outputs. For GIF it renders
+// "fps,scale:flags=lanczos,split" feeding palettegen and paletteuse:
+//
+//	[0:v]fps=15[anim_fps];[anim_fps]scale=480:-1:flags=lanczos[anim_scaled];
+//	[anim_scaled]split=2[anim_a][anim_b];[anim_a]palettegen=...[anim_pal];
+//	[anim_b][anim_pal]paletteuse=...[anim]
+type AnimatedImage struct {
+	input      string
+	format     AnimationFormat
+	start      time.Duration
+	duration   time.Duration
+	fps        float64
+	width      int
+	maxColors  int
+	statsMode  PaletteStatsMode
+	dither     DitherMode
+	bayerScale int
+	loop       int
+	quality    int
+	lossless   bool
+	maxSize    int64
+	minFPS     float64
+	minWidth   int
+	overwrite  bool
+}
+
+// AnimationResult reports the settings of the encode kept by Run.
+type AnimationResult struct {
+	FPS      float64
+	Width    int
+	Size     int64
+	Attempts int
+}
+
+// NewGIF creates a GIF builder with 15 fps, 480px width, 256 colors, diff stats,
+// bayer dithering and infinite looping.
+func NewGIF(input string) *AnimatedImage {
+	return &AnimatedImage{
+		input:      input,
+		format:     AnimationGIF,
+		fps:        15,
+		width:      480,
+		maxColors:  256,
+		statsMode:  PaletteStatsDiff,
+		dither:     DitherBayer,
+		bayerScale: 5,
+		minFPS:     5,
+		minWidth:   160,
+	}
+}
+
+// NewAnimatedWebP creates an animated WebP builder with the same defaults as NewGIF and quality 75.
+func NewAnimatedWebP(input string) *AnimatedImage {
+	a := NewGIF(input)
+	a.format = AnimationWebP
+	a.quality = 75
+	return a
+}
+
+// Trim encodes only duration of the input starting at start (input -ss/-t).
+func (a *AnimatedImage) Trim(start, duration time.Duration) *AnimatedImage {
+	a.start, a.duration = start, duration
+	return a
+}
+
+// FPS sets the output frame rate.
+func (a *AnimatedImage) FPS(fps float64) *AnimatedImage {
+	a.fps = fps
+	return a
+}
+
+// Width sets the output width; height keeps the aspect ratio.
+func (a *AnimatedImage) Width(width int) *AnimatedImage {
+	a.width = width
+	return a
+}
+
+// Colors sets the GIF palette size (2..256).
+func (a *AnimatedImage) Colors(maxColors int) *AnimatedImage {
+	a.maxColors = maxColors
+	return a
+}
+
+// StatsMode sets the palettegen stats mode.
+func (a *AnimatedImage) StatsMode(mode PaletteStatsMode) *AnimatedImage {
+	a.statsMode = mode
+	return a
+}
+
+// Dither sets the paletteuse dithering; bayerScale only applies to DitherBayer.
+func (a *AnimatedImage) Dither(mode DitherMode, bayerScale int) *AnimatedImage {
+	a.dither, a.bayerScale = mode, bayerScale
+	return a
+}
+
+// Loop sets the loop count (0 = infinite, -1 = play once). WebP has no -1 and takes
+// 0..65535 plays instead, so use 1 to play a WebP once.
+func (a *AnimatedImage) Loop(count int) *AnimatedImage {
+	a.loop = count
+	return a
+}
+
+// Quality sets the WebP quality (0..100) and lossless mode.
+func (a *AnimatedImage) Quality(quality int, lossless bool) *AnimatedImage {
+	a.quality, a.lossless = quality, lossless
+	return a
+}
+
+// MaxFileSize makes Run re-encode with lower width and frame rate until the output
+// fits in bytes, without going under minFPS or minWidth.
+func (a *AnimatedImage) MaxFileSize(bytes int64, minFPS float64, minWidth int) *AnimatedImage {
+	a.maxSize, a.minFPS, a.minWidth = bytes, minFPS, minWidth
+	return a
+}
+
+// Overwrite adds '-y' to the command.
+func (a *AnimatedImage) Overwrite() *AnimatedImage {
+	a.overwrite = true
+	return a
+}
+
+// Validate validates the animation settings
+func (a *AnimatedImage) Validate() error {
+	if strings.TrimSpace(a.input) == "" {
+		return fmt.Errorf("animation: input cannot be empty")
+	}
+	if a.start < 0 || a.duration < 0 {
+		return fmt.Errorf("animation: trim start and duration must be non-negative")
+	}
+	if a.fps <= 0 {
+		return fmt.Errorf("animation: fps must be positive, got %v", a.fps)
+	}
+	if a.width <= 0 {
+		return fmt.Errorf("animation: width must be positive, got %d", a.width)
+	}
+	if a.format == AnimationWebP && (a.loop < 0 || a.loop > 65535) {
+		return fmt.Errorf("animation: webp loop count must be between 0 (infinite) and 65535, got %d", a.loop)
+	}
+	if a.maxSize < 0 {
+		return fmt.Errorf("animation: max file size must be non-negative, got %d", a.maxSize)
+	}
+	if a.maxSize > 0 && (a.minFPS <= 0 || a.minWidth <= 0) {
+		return fmt.Errorf("animation: max file size targeting requires positive minimum fps and width")
+	}
+	return nil
+}
+
+// Command builds the ffmpeg command writing the animation to output.
+func (a *AnimatedImage) Command(output string) (*Ffmpego, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+
+	var inputOpts []InputFlagFn
+	if a.start > 0 {
+		inputOpts = append(inputOpts, WithSeek(a.start))
+	}
+	if a.duration > 0 {
+		inputOpts = append(inputOpts, WithInputDuration(a.duration))
+	}
+	flags := []FfmpegFlagFn{WithInputFile(a.input, inputOpts...)}
+	if a.overwrite {
+		flags = append(flags, WithOverwrite())
+	}
+
+	graph := NewComplexFilterBuilder().
+		Add(WithFPS("0:v:0", "anim_fps", formatFloat(a.fps))).
+		Add(WithScaleFlags("anim_fps", "anim_scaled", a.width, -1, "lanczos"))
+
+	out := NewOutputBuilder()
+	if a.format == AnimationGIF {
+		graph.
+			Add(WithSplit("anim_scaled", 2, "anim_a", "anim_b")).
+			Add(WithPaletteGen("anim_a", "anim_pal", a.maxColors, a.statsMode)).
+			Add(WithPaletteUse("anim_b", "anim_pal", "anim", a.dither, a.bayerScale, a.statsMode == PaletteStatsDiff))
+		out.WithFlag(WithMap("[anim]"))
+	} else {
+		out.WithFlag(WithMap("[anim_scaled]")).
+			WithFlag(WithVideoCodec("libwebp")).
+			WithFlag(withOutputFlag(WebPQualityFlag(a.quality))).
+			WithFlag(withOutputFlag(WebPLosslessFlag(a.lossless)))
+	}
+
+	return New("").
+		WithOptions(NewFfmpegOptions(flags...)).
+		WithFilterGraph(graph.Build()).
+		Output(out.WithFlag(WithLoop(a.loop)).File(output).Build()), nil
+}
+
+// Run encodes the animation. With MaxFileSize set it checks the written size and
+// retries with a smaller width and frame rate until it fits.
+func (a *AnimatedImage) Run(ctx context.Context, output string) (*AnimationResult, error) {
+	current := *a
+	for attempt := 1; ; attempt++ {
+		cmd, err := current.Command(output)
+		if err != nil {
+			return nil, err
+		}
+		if err := NewRunner(cmd).Run(ctx); err != nil {
+			return nil, err
+		}
+
+		info, err := os.Stat(output)
+		if err != nil {
+			return nil, fmt.Errorf("animation: failed to stat output: %w", err)
+		}
+
+		result := &AnimationResult{FPS: current.fps, Width: current.width, Size: info.Size(), Attempts: attempt}
+		if a.maxSize == 0 || info.Size() <= a.maxSize {
+			return result, nil
+		}
+		if attempt == maxSizeAttempts || !current.shrink(float64(a.maxSize)/float64(info.Size())) {
+			return result, fmt.Errorf("animation: %d bytes exceeds max file size %d at %dpx/%v fps", info.Size(), a.maxSize, current.width, current.fps)
+		}
+		// later attempts must overwrite the previous encode
+		current.overwrite = true
+	}
+}
+
+// shrink lowers width and frame rate for the next attempt, splitting the required
+// reduction between both. It reports false when both are already at their minimum.
+func (a *AnimatedImage) shrink(ratio float64) bool {
+	// size scales roughly with width² * fps; aim a little below the target
+	factor := math.Cbrt(ratio * 0.9)
+	if factor > 0.9 {
+		factor = 0.9
+	}
+
+	width := evenDimension(float64(a.width) * factor)
+	if width < a.minWidth {
+		width = a.minWidth
+	}
+	fps := math.Round(a.fps*factor*100) / 100
+	if fps < a.minFPS {
+		fps = a.minFPS
+	}
+
+	if width == a.width && fps == a.fps {
+		return false
+	}
+	a.width, a.fps = width, fps
+	return true
+}
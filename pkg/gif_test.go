@@ -0,0 +1,84 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGIF_Command(t *testing.T) {
+	cmd, err := NewGIF("in.mp4").
+		Trim(2*time.Second, 3*time.Second).
+		FPS(12).
+		Width(320).
+		Command("out.gif")
+	if err != nil {
+		t.Fatalf("Command() error: %v", err)
+	}
+
+	args, err := cmd.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	got := strings.Join(args, " ")
+	want := "-ss 00:00:02.000 -t 00:00:03.000 -i in.mp4 -filter_complex " +
+		"[0:v:0]fps=12[anim_fps];[anim_fps]scale=320:-1:flags=lanczos[anim_scaled];" +
+		"[anim_scaled]split=2[anim_a][anim_b];[anim_a]palettegen=max_colors=256:stats_mode=diff[anim_pal];" +
+		"[anim_b][anim_pal]paletteuse=dither=bayer:bayer_scale=5:diff_mode=rectangle[anim] " +
+		"-map [anim] -loop 0 out.gif"
+	if got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestAnimatedWebP_Command(t *testing.T) {
+	cmd, err := NewAnimatedWebP("in.mp4").Quality(60, false).Command("out.webp")
+	if err != nil {
+		t.Fatalf("Command() error: %v", err)
+	}
+
+	args, err := cmd.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	got := strings.Join(args, " ")
+	if strings.Contains(got, "palettegen") {
+		t.Fatalf("webp should not use a palette: %s", got)
+	}
+	if !strings.HasSuffix(got, "-map [anim_scaled] -c:v libwebp -quality 60 -lossless 0 -loop 0 out.webp") {
+		t.Fatalf("unexpected webp output args: %s", got)
+	}
+}
+
+func TestAnimatedWebP_InvalidLoop(t *testing.T) {
+	if err := NewAnimatedWebP("in.mp4").Loop(-1).Validate(); err == nil {
+		t.Fatalf("expected error for play once on webp, got nil")
+	}
+	if err := NewGIF("in.mp4").Loop(-1).Validate(); err != nil {
+		t.Fatalf("Validate() error for play once on gif: %v", err)
+	}
+}
+
+func TestAnimatedImage_Shrink(t *testing.T) {
+	a := NewGIF("in.mp4").MaxFileSize(1_000_000, 8, 200)
+	if !a.shrink(0.5) {
+		t.Fatalf("expected shrink to reduce settings")
+	}
+	if a.width >= 480 || a.width%2 != 0 || a.fps >= 15 {
+		t.Fatalf("unexpected settings after shrink: %dpx %v fps", a.width, a.fps)
+	}
+
+	a.width, a.fps = 200, 8
+	if a.shrink(0.5) {
+		t.Fatalf("expected shrink to report minimum reached")
+	}
+}
+
+func TestPaletteUseFilter_InvalidDither(t *testing.T) {
+	f := PaletteUseFilter{Input: "a", Palette: "p", Output: "o", Dither: "random"}
+	if err := f.Validate(); err == nil {
+		t.Fatalf("expected error for invalid dither, got nil")
+	}
+}
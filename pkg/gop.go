@@ -0,0 +1,252 @@
+package ffmpego
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyframesAt returns a -force_key_frames value placing keyframes at the given times.
+func KeyframesAt(times ...time.Duration) ForceKeyFramesFlag {
+	values := make([]string, len(times))
+	for i, t := range times {
+		values[i] = FormatTimestamp(t)
+	}
+	return ForceKeyFramesFlag(strings.Join(values, ","))
+}
+
+// KeyframesEvery returns a -force_key_frames expression forcing a keyframe on the first
+// frame at or after every multiple of interval: "expr:gte(t,n_forced*4)".
+func KeyframesEvery(interval time.Duration) ForceKeyFramesFlag {
+	return ForceKeyFramesFlag(fmt.Sprintf("expr:gte(t,n_forced*%s)", formatSeconds(interval)))
+}
+
+// WithKeyframesAt creates a new force key frames flag for a list of times
+func WithKeyframesAt(times ...time.Duration) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(KeyframesAt(times...))
+	}
+}
+
+// WithKeyframesEvery creates a new force key frames flag repeating every interval
+func WithKeyframesEvery(interval time.Duration) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(KeyframesEvery(interval))
+	}
+}
+
+// SegmentGOP renders closed, fixed length GOPs aligned to segment boundaries: the GOP
+// length in frames, no scene cut keyframes and a keyframe forced at every boundary.
+//
+//	-g 120 -keyint_min 120 -sc_threshold 0 -force_key_frames expr:gte(t,n_forced*4)
+//
+// libx265 ignores -sc_threshold and uses open GOPs by default, so it gets
+// "-x265-params keyint=120:min-keyint=120:scenecut=0:open-gop=0" instead.
+type SegmentGOP struct {
+	SegmentDuration time.Duration
+	FrameRate       string // output frame rate, e.g. "30000/1001"
+	encoder         string
+}
+
+func (g SegmentGOP) forEncoder(encoder string) (OutputFlagParser, error) {
+	g.encoder = encoder
+	return g, nil
+}
+
+// Frames returns the GOP length in frames, rounded to the nearest frame.
+func (g SegmentGOP) Frames() (int, error) {
+	fps, err := ParseFrameRate(g.FrameRate)
+	if err != nil || fps <= 0 {
+		return 0, fmt.Errorf("segment gop: invalid frame rate %q", g.FrameRate)
+	}
+	frames := int(math.Round(fps * g.SegmentDuration.Seconds()))
+	if frames < 1 {
+		return 0, fmt.Errorf("segment gop: segment %s is shorter than a frame at %s fps", g.SegmentDuration, g.FrameRate)
+	}
+	return frames, nil
+}
+
+// Validate validates the segment duration and frame rate
+func (g SegmentGOP) Validate() error {
+	if g.SegmentDuration <= 0 {
+		return fmt.Errorf("segment gop: segment duration must be positive, got %s", g.SegmentDuration)
+	}
+	_, err := g.Frames()
+	return err
+}
+
+// Parse returns the keyframe flags for the encoder
+func (g SegmentGOP) Parse() []string {
+	frames, _ := g.Frames()
+	gop := strconv.Itoa(frames)
+	force := string(KeyframesEvery(g.SegmentDuration))
+
+	if videoEncoderFamily(g.encoder) == familyX265 {
+		return []string{
+			"-force_key_frames", force,
+			"-x265-params", fmt.Sprintf("keyint=%s:min-keyint=%s:scenecut=0:open-gop=0", gop, gop),
+		}
+	}
+	return []string{"-g", gop, "-keyint_min", gop, "-sc_threshold", "0", "-force_key_frames", force}
+}
+
+// WithSegmentGOP creates a new segment aligned GOP flag
+func WithSegmentGOP(segment time.Duration, frameRate string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(SegmentGOP{SegmentDuration: segment, FrameRate: frameRate})
+	}
+}
+
+// AlignSegmentGOPs adds the same segment aligned GOP settings to every output of cmd that
+// encodes video, so renditions of a ladder switch cleanly at segment boundaries. An
+// output's own -r takes precedence over frameRate. Outputs that stream copy or carry no
+// video (-vn, or only audio, subtitle or data maps) are left alone; outputs that already
+// set keyframe options are rejected.
+func AlignSegmentGOPs(cmd *Ffmpego, segment time.Duration, frameRate string) error {
+	for i, out := range cmd.outputs {
+		rate := frameRate
+		copied := false
+		for _, option := range out.Options {
+			switch f := option.(type) {
+			case GOPFlag, KeyintMinFlag, SceneChangeThresholdFlag, ForceKeyFramesFlag, SegmentGOP:
+				return fmt.Errorf("segment gop: output %d already sets keyframe options", i)
+			case X264Options:
+				if f.Keyint > 0 || f.MinKeyint > 0 {
+					return fmt.Errorf("segment gop: output %d already sets keyframe options", i)
+				}
+			case X265Options:
+				if f.Keyint > 0 || f.MinKeyint > 0 {
+					return fmt.Errorf("segment gop: output %d already sets keyframe options", i)
+				}
+			case FrameRateFlag:
+				rate = string(f)
+			case VideoCodec:
+				copied = f == "copy"
+			case CodecFlag:
+				copied = f == "copy"
+			}
+		}
+		if copied || !mapsVideo(out) {
+			continue
+		}
+
+		gop := SegmentGOP{SegmentDuration: segment, FrameRate: rate}
+		if err := gop.Validate(); err != nil {
+			return fmt.Errorf("output %d: %w", i, err)
+		}
+		insertBeforeFile(out, gop)
+	}
+	return nil
+}
+
+// mapsVideo reports whether an output may carry a video stream. Filtergraph labels and
+// specifiers without a stream type count as video, since their type is not known here.
+func mapsVideo(out *OutputDescriptor) bool {
+	maps := 0
+	video := false
+	for _, option := range out.Options {
+		switch f := option.(type) {
+		case DisableStreamsFlag:
+			if MediaType(f) == MediaVideo {
+				return false
+			}
+		case MapFlag:
+			maps++
+			spec, err := ParseMapSpec(string(f))
+			if err != nil {
+				// a filtergraph label
+				video = true
+				continue
+			}
+			switch spec.Type {
+			case "", MediaVideo, MediaVideoOnly:
+				video = video || !spec.Negative
+			}
+		}
+	}
+	// without maps ffmpeg picks the best video stream itself
+	return maps == 0 || video
+}
+
+// insertBeforeFile adds option to a built output, ahead of its file path.
+func insertBeforeFile(out *OutputDescriptor, option OutputFlagParser) {
+	for i, existing := range out.Options {
+		if _, ok := existing.(File); ok {
+			out.Options = append(out.Options[:i], append([]OutputFlagParser{option}, out.Options[i:]...)...)
+			return
+		}
+	}
+	out.Add(option)
+}
+
+// KeyframeReport is the result of checking keyframe positions against segment boundaries.
+// Times are relative to the first keyframe.
+type KeyframeReport struct {
+	Keyframes []time.Duration
+	// Missing lists segment boundaries without a keyframe within tolerance.
+	Missing []time.Duration
+	// Extra lists keyframes that are not on a boundary, e.g. scene cuts.
+	Extra []time.Duration
+}
+
+// Aligned reports whether every segment boundary starts with a keyframe.
+func (r *KeyframeReport) Aligned() bool {
+	return len(r.Missing) == 0
+}
+
+// CheckSegmentKeyframes matches keyframes (presentation times) against the boundaries of
+// segment long segments over duration. A keyframe counts for a boundary when it is at
+// most tolerance after it, since forced keyframes land on the first frame at or after
+// the boundary.
+func CheckSegmentKeyframes(keyframes []time.Duration, duration, segment, tolerance time.Duration) *KeyframeReport {
+	report := &KeyframeReport{}
+	if len(keyframes) == 0 || segment <= 0 {
+		return report
+	}
+
+	start := keyframes[0]
+	matched := make(map[int]bool)
+	for _, kf := range keyframes {
+		rel := kf - start
+		report.Keyframes = append(report.Keyframes, rel)
+		// nearest boundary, allowing a millisecond of timestamp rounding before it
+		n := int((rel + segment/2) / segment)
+		if offset := rel - time.Duration(n)*segment; offset >= -time.Millisecond && offset <= tolerance {
+			matched[n] = true
+		} else {
+			report.Extra = append(report.Extra, rel)
+		}
+	}
+
+	for n := 0; time.Duration(n)*segment < duration; n++ {
+		if !matched[n] {
+			report.Missing = append(report.Missing, time.Duration(n)*segment)
+		}
+	}
+	return report
+}
+
+// VerifySegmentKeyframes probes an encoded file and checks that a keyframe starts every
+// segment, allowing one frame of slack at frameRate.
+func VerifySegmentKeyframes(ctx context.Context, file string, segment time.Duration, frameRate string) (*KeyframeReport, error) {
+	fps, err := ParseFrameRate(frameRate)
+	if err != nil || fps <= 0 {
+		return nil, fmt.Errorf("segment gop: invalid frame rate %q", frameRate)
+	}
+
+	prober := NewProber("")
+	probe, err := prober.Probe(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	keyframes, err := prober.Keyframes(ctx, file, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	tolerance := time.Duration(float64(time.Second) / fps)
+	return CheckSegmentKeyframes(keyframes, probe.Duration(), segment, tolerance), nil
+}
@@ -0,0 +1,89 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyframeFlags(t *testing.T) {
+	if got := KeyframesAt(0, 4*time.Second, 9500*time.Millisecond); got != "00:00:00.000,00:00:04.000,00:00:09.500" {
+		t.Fatalf("KeyframesAt() = %q", got)
+	}
+	if got := KeyframesEvery(2 * time.Second); got != "expr:gte(t,n_forced*2)" {
+		t.Fatalf("KeyframesEvery() = %q", got)
+	}
+	for _, value := range []ForceKeyFramesFlag{"expr:", "00:00:04.000,soon", ""} {
+		if err := value.Validate(); err == nil {
+			t.Errorf("expected error for %q, got nil", value)
+		}
+	}
+	for _, value := range []ForceKeyFramesFlag{"source", "source_no_drop", "chapters-0.1", "4,8.5,00:00:12"} {
+		if err := value.Validate(); err != nil {
+			t.Errorf("Validate(%q) error: %v", value, err)
+		}
+	}
+}
+
+func TestAlignSegmentGOPs(t *testing.T) {
+	cmd := New("").
+		WithOptions(NewFfmpegOptions(WithInput("in.mp4"))).
+		Output(NewOutputBuilder().WithFlag(VideoCodecH264).File("h264.mp4").Build()).
+		Output(NewOutputBuilder().WithFlag(VideoCodecH265).WithFlag(WithFrameRate("25")).File("hevc.mp4").Build()).
+		Output(NewOutputBuilder().WithFlag(WithCodec("copy")).File("copy.mkv").Build()).
+		Output(NewOutputBuilder().WithFlag(WithDisabledStreams(MediaVideo)).WithFlag(AudioCodecAAC).File("novideo.m4a").Build()).
+		Output(NewOutputBuilder().WithFlag(WithMap("0:a:0")).WithFlag(WithMap("0:s?")).File("audio.mka").Build()).
+		Output(NewOutputBuilder().WithFlag(WithMap("0:#0x101")).WithFlag(VideoCodecH264).File("pid.mp4").Build())
+
+	if err := AlignSegmentGOPs(cmd, 2*time.Second, "30"); err != nil {
+		t.Fatalf("AlignSegmentGOPs() error: %v", err)
+	}
+	args, err := cmd.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	got := strings.Join(args, " ")
+	for _, want := range []string{
+		"-c:v libx264 -g 60 -keyint_min 60 -sc_threshold 0 -force_key_frames expr:gte(t,n_forced*2) h264.mp4",
+		"-c:v libx265 -r 25 -force_key_frames expr:gte(t,n_forced*2) -x265-params keyint=50:min-keyint=50:scenecut=0:open-gop=0 hevc.mp4",
+		"-c copy copy.mkv",
+		"-vn -c:a aac novideo.m4a",
+		"-map 0:a:0 -map 0:s? audio.mka",
+		"-map 0:#0x101 -c:v libx264 -g 60 -keyint_min 60 -sc_threshold 0 -force_key_frames expr:gte(t,n_forced*2) pid.mp4",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("args %q missing %q", got, want)
+		}
+	}
+
+	conflicting := New("").Output(NewOutputBuilder().WithFlag(WithGOP(48)).File("out.mp4").Build())
+	if err := AlignSegmentGOPs(conflicting, 2*time.Second, "24"); err == nil {
+		t.Fatalf("expected error for an output with its own GOP, got nil")
+	}
+	if err := AlignSegmentGOPs(New("").Output(NewOutputBuilder().File("out.mp4").Build()), 10*time.Millisecond, "24"); err == nil {
+		t.Fatalf("expected error for a segment shorter than a frame, got nil")
+	}
+}
+
+func TestCheckSegmentKeyframes(t *testing.T) {
+	ms := time.Millisecond
+	frame := 33 * ms
+
+	// starts at 1.4s like an MPEG-TS output; 3.999999s is timestamp rounding of 4s
+	aligned := CheckSegmentKeyframes([]time.Duration{1400 * ms, 3433 * ms, 5400*ms - time.Microsecond, 7433 * ms}, 8*time.Second, 2*time.Second, frame)
+	if !aligned.Aligned() || len(aligned.Extra) != 0 {
+		t.Fatalf("expected aligned keyframes, got %+v", aligned)
+	}
+
+	report := CheckSegmentKeyframes([]time.Duration{0, 2000 * ms, 3100 * ms, 6010 * ms}, 8*time.Second, 2*time.Second, frame)
+	if report.Aligned() {
+		t.Fatalf("expected misaligned keyframes")
+	}
+	if len(report.Missing) != 1 || report.Missing[0] != 4*time.Second {
+		t.Errorf("Missing = %v, want [4s]", report.Missing)
+	}
+	if len(report.Extra) != 1 || report.Extra[0] != 3100*ms {
+		t.Errorf("Extra = %v, want [3.1s]", report.Extra)
+	}
+}
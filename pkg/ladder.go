@@ -0,0 +1,268 @@
+package ffmpego
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LadderPolicy configures GenerateLadder.
+type LadderPolicy struct {
+	// Heights are the candidate rung heights; rungs above the source height are dropped.
+	Heights []int
+	// MaxFrameRate caps the output frame rate by dividing the source rate (0 = no cap).
+	MaxFrameRate float64
+	// BitsPerPixel is the video bitrate heuristic: width*height*fps*BitsPerPixel.
+	BitsPerPixel float64
+	// MinBitrate and MaxBitrate clamp the computed video bitrate in bits per second (0 = no clamp).
+	MinBitrate int64
+	MaxBitrate int64
+	// SegmentDuration is the keyframe interval shared by all rungs.
+	SegmentDuration time.Duration
+	VideoCodec      string
+	AudioCodec      string
+	AudioBitrate    string
+	// OutputPattern names each rung file; "%d" is replaced by the rung height.
+	OutputPattern string
+	Overwrite     bool
+}
+
+// DefaultLadderPolicy returns a 1080p..360p H.264 ladder with 4s aligned GOPs.
+func DefaultLadderPolicy() LadderPolicy {
+	return LadderPolicy{
+		Heights:         []int{1080, 720, 480, 360},
+		MaxFrameRate:    30,
+		BitsPerPixel:    0.08,
+		MinBitrate:      300_000,
+		MaxBitrate:      8_000_000,
+		SegmentDuration: 4 * time.Second,
+		VideoCodec:      "libx264",
+		AudioCodec:      "aac",
+		AudioBitrate:    "128k",
+		OutputPattern:   "output_%dp.mp4",
+	}
+}
+
+// Validate validates the ladder policy
+func (p LadderPolicy) Validate() error {
+	if len(p.Heights) == 0 {
+		return fmt.Errorf("ladder: at least one rung height is required")
+	}
+	for _, h := range p.Heights {
+		if h <= 0 {
+			return fmt.Errorf("ladder: rung height must be positive, got %d", h)
+		}
+	}
+	if p.MaxFrameRate < 0 {
+		return fmt.Errorf("ladder: max frame rate must be non-negative, got %v", p.MaxFrameRate)
+	}
+	if p.BitsPerPixel <= 0 {
+		return fmt.Errorf("ladder: bits per pixel must be positive, got %v", p.BitsPerPixel)
+	}
+	if p.MaxBitrate > 0 && p.MinBitrate > p.MaxBitrate {
+		return fmt.Errorf("ladder: min bitrate %d exceeds max bitrate %d", p.MinBitrate, p.MaxBitrate)
+	}
+	if p.SegmentDuration <= 0 {
+		return fmt.Errorf("ladder: segment duration must be positive, got %s", p.SegmentDuration)
+	}
+	if strings.TrimSpace(p.VideoCodec) == "" {
+		return fmt.Errorf("ladder: video codec cannot be empty")
+	}
+	if !strings.Contains(p.OutputPattern, "%d") {
+		return fmt.Errorf("ladder: output pattern %q must contain %%d", p.OutputPattern)
+	}
+	return nil
+}
+
+// Rung describes a single rendition of a generated ladder.
+type Rung struct {
+	Label     string
+	Width     int
+	Height    int
+	FrameRate string // rational accepted by -r, e.g. "30000/1001"
+	FPS       float64
+	Bitrate   int64 // bits per second
+	GOP       int   // frames between keyframes
+	File      string
+}
+
+// GenerateLadder derives rendition rungs from a probed source and returns a complete
+// command: one SplitFilter feeding a scale to square pixels (setsar=1) per rung, and one output per rung
+// with keyframes forced at every SegmentDuration so segments align across rungs.
+// Rungs never upscale, keep the source display aspect ratio and use even dimensions.
+func GenerateLadder(probe *ProbeResult, policy LadderPolicy) (*Ffmpego, []Rung, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if probe == nil || probe.Format.Filename == "" {
+		return nil, nil, fmt.Errorf("ladder: probe result must include the source filename")
+	}
+
+	video, ok := probe.VideoStream()
+	if !ok {
+		return nil, nil, fmt.Errorf("ladder: source %q has no video stream", probe.Format.Filename)
+	}
+
+	rungs, err := computeRungs(video, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	flags := []FfmpegFlagFn{WithInput(probe.Format.Filename)}
+	if policy.Overwrite {
+		flags = append(flags, WithOverwrite())
+	}
+
+	source := fmt.Sprintf("0:%d", video.Index)
+	graph := NewComplexFilterBuilder()
+	if len(rungs) > 1 {
+		splits := make([]string, len(rungs))
+		for i, rung := range rungs {
+			splits[i] = "split_" + rung.Label
+		}
+		graph.Add(WithSplit(source, len(rungs), splits...))
+		for i, rung := range rungs {
+			graph.Add(WithFilterChain(splits[i], rung.scaleExpr(), rung.Label))
+		}
+	} else {
+		graph.Add(WithFilterChain(source, rungs[0].scaleExpr(), rungs[0].Label))
+	}
+
+	hasAudio := len(probe.AudioStreams()) > 0
+
+	cmd := New("").
+		WithOptions(NewFfmpegOptions(flags...)).
+		WithFilterGraph(graph.Build())
+
+	for _, rung := range rungs {
+		out := NewOutputBuilder().
+			WithFlag(WithMap("[" + rung.Label + "]")).
+			WithFlag(WithVideoCodec(policy.VideoCodec)).
+			WithFlag(WithBitrate(fmt.Sprintf("%dk", rung.Bitrate/1000))).
+			WithFlag(WithFrameRate(rung.FrameRate)).
+			WithFlag(WithSegmentGOP(policy.SegmentDuration, rung.FrameRate))
+
+		if hasAudio {
+			out.WithFlag(WithMap("0:a:0"))
+			if policy.AudioCodec != "" {
+				out.WithFlag(WithAudioCodec(policy.AudioCodec))
+			}
+			if policy.AudioBitrate != "" {
+				out.WithFlag(WithAudioBitrate(policy.AudioBitrate))
+			}
+		}
+
+		cmd.Output(out.File(rung.File).Build())
+	}
+
+	return cmd, rungs, nil
+}
+
+func computeRungs(video ProbeStream, policy LadderPolicy) ([]Rung, error) {
+	srcW, srcH := video.DisplaySize()
+	if srcW <= 0 || srcH <= 0 {
+		return nil, fmt.Errorf("ladder: source video has invalid dimensions %dx%d", srcW, srcH)
+	}
+
+	rateValue := video.AvgFrameRate
+	if fps, err := ParseFrameRate(rateValue); err != nil || fps <= 0 {
+		rateValue = video.RFrameRate
+	}
+	num, den, err := parseRational(rateValue)
+	if err != nil || num <= 0 {
+		return nil, fmt.Errorf("ladder: source video has invalid frame rate %q", rateValue)
+	}
+	num, den = capFrameRate(num, den, policy.MaxFrameRate)
+	fps := float64(num) / float64(den)
+	frameRate := fmt.Sprintf("%d/%d", num, den)
+	if den == 1 {
+		frameRate = fmt.Sprintf("%d", num)
+	}
+
+	gop := int(math.Round(fps * policy.SegmentDuration.Seconds()))
+	if gop < 1 {
+		gop = 1
+	}
+
+	aspect := video.DisplayAspectRatio()
+	heights := candidateHeights(policy.Heights, srcH)
+	rungs := make([]Rung, 0, len(heights))
+	for _, h := range heights {
+		w := evenDimension(float64(h) * aspect)
+		rungs = append(rungs, Rung{
+			Label:     fmt.Sprintf("v%dp", h),
+			Width:     w,
+			Height:    h,
+			FrameRate: frameRate,
+			FPS:       fps,
+			Bitrate:   rungBitrate(w, h, fps, policy),
+			GOP:       gop,
+			File:      strings.Replace(policy.OutputPattern, "%d", fmt.Sprintf("%d", h), 1),
+		})
+	}
+
+	return rungs, nil
+}
+
+// scaleExpr scales to the rung size with square pixels, so anamorphic sources play back
+// at their display aspect ratio.
+func (r Rung) scaleExpr() string {
+	return fmt.Sprintf("scale=%d:%d,setsar=1", r.Width, r.Height)
+}
+
+// candidateHeights returns the unique even policy heights not above the source,
+// in descending order. When every rung would upscale, the source height is used.
+func candidateHeights(heights []int, srcH int) []int {
+	seen := make(map[int]bool)
+	var result []int
+	for _, h := range heights {
+		h = evenDimension(float64(h))
+		if h > srcH || seen[h] {
+			continue
+		}
+		seen[h] = true
+		result = append(result, h)
+	}
+	if len(result) == 0 {
+		result = append(result, evenDimension(float64(srcH)))
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(result)))
+	return result
+}
+
+// capFrameRate divides the source rate by the smallest integer that brings it under
+// max, which keeps frame cadence regular (60 -> 30, 59.94 -> 29.97).
+func capFrameRate(num, den int64, max float64) (int64, int64) {
+	if max <= 0 {
+		return num, den
+	}
+	divisor := int64(1)
+	for float64(num)/float64(den*divisor) > max+1e-9 {
+		divisor++
+	}
+	den *= divisor
+	g := gcd(num, den)
+	return num / g, den / g
+}
+
+func rungBitrate(w, h int, fps float64, policy LadderPolicy) int64 {
+	bitrate := int64(float64(w*h) * fps * policy.BitsPerPixel)
+	if policy.MinBitrate > 0 && bitrate < policy.MinBitrate {
+		bitrate = policy.MinBitrate
+	}
+	if policy.MaxBitrate > 0 && bitrate > policy.MaxBitrate {
+		bitrate = policy.MaxBitrate
+	}
+	return (bitrate + 500) / 1000 * 1000
+}
+
+// evenDimension rounds v to an even value of at least 2, as required by 4:2:0 encoders.
+func evenDimension(v float64) int {
+	d := int(math.Round(v)) &^ 1
+	if d < 2 {
+		return 2
+	}
+	return d
+}
@@ -0,0 +1,94 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateLadder(t *testing.T) {
+	probe, err := ParseProbe([]byte(sampleProbe))
+	if err != nil {
+		t.Fatalf("ParseProbe() error: %v", err)
+	}
+
+	policy := DefaultLadderPolicy()
+	policy.Heights = []int{2160, 1080, 720, 480}
+	cmd, rungs, err := GenerateLadder(probe, policy)
+	if err != nil {
+		t.Fatalf("GenerateLadder() error: %v", err)
+	}
+
+	if len(rungs) != 3 {
+		t.Fatalf("expected 3 rungs (no 2160p upscale), got %d: %+v", len(rungs), rungs)
+	}
+	if rungs[2].Width != 852 || rungs[2].Height != 480 {
+		t.Fatalf("480p rung should be 852x480, got %dx%d", rungs[2].Width, rungs[2].Height)
+	}
+	for _, rung := range rungs {
+		if rung.FrameRate != "30000/1001" {
+			t.Fatalf("frame rate should be capped to 30000/1001, got %s", rung.FrameRate)
+		}
+		if rung.GOP != 120 {
+			t.Fatalf("GOP should be 120 frames for 4s segments, got %d", rung.GOP)
+		}
+	}
+
+	args, err := cmd.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	idx := indexOf(args, "-filter_complex")
+	if idx < 0 {
+		t.Fatalf("missing -filter_complex in args: %v", args)
+	}
+	wantGraph := "[0:0]split=3[split_v1080p][split_v720p][split_v480p];" +
+		"[split_v1080p]scale=1920:1080,setsar=1[v1080p];[split_v720p]scale=1280:720,setsar=1[v720p];" +
+		"[split_v480p]scale=852:480,setsar=1[v480p]"
+	if args[idx+1] != wantGraph {
+		t.Fatalf("graph mismatch:\n got: %s\nwant: %s", args[idx+1], wantGraph)
+	}
+
+	joined := strings.Join(args, " ")
+	for _, part := range []string{"-map [v720p]", "-force_key_frames expr:gte(t,n_forced*4)", "-map 0:a:0", "output_480p.mp4"} {
+		if !strings.Contains(joined, part) {
+			t.Fatalf("args missing %q: %s", part, joined)
+		}
+	}
+}
+
+func TestGenerateLadder_SmallSourceSingleRung(t *testing.T) {
+	probe := &ProbeResult{
+		Format:  ProbeFormat{Filename: "small.mp4"},
+		Streams: []ProbeStream{{Index: 0, CodecType: "video", Width: 321, Height: 241, RFrameRate: "25/1"}},
+	}
+
+	cmd, rungs, err := GenerateLadder(probe, DefaultLadderPolicy())
+	if err != nil {
+		t.Fatalf("GenerateLadder() error: %v", err)
+	}
+	if len(rungs) != 1 || rungs[0].Width != 320 || rungs[0].Height != 240 {
+		t.Fatalf("expected a single 320x240 rung, got %+v", rungs)
+	}
+	if _, err := cmd.Build(); err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+}
+
+func TestGenerateLadder_AnamorphicSource(t *testing.T) {
+	// PAL 16:9 DVD: 720x576 storage, 64:45 pixels
+	probe := &ProbeResult{
+		Format:  ProbeFormat{Filename: "dvd.mpg"},
+		Streams: []ProbeStream{{Index: 0, CodecType: "video", Width: 720, Height: 576, SampleAspectRatio: "64:45", RFrameRate: "25/1"}},
+	}
+
+	policy := DefaultLadderPolicy()
+	policy.Heights = []int{576}
+	_, rungs, err := GenerateLadder(probe, policy)
+	if err != nil {
+		t.Fatalf("GenerateLadder() error: %v", err)
+	}
+	if len(rungs) != 1 || rungs[0].Width != 1024 || rungs[0].Height != 576 {
+		t.Fatalf("expected a single 1024x576 rung, got %+v", rungs)
+	}
+}
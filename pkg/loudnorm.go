@@ -0,0 +1,293 @@
+package ffmpego
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// LoudnessTarget holds the EBU R128 targets passed to loudnorm.
+type LoudnessTarget struct {
+	I   float64 // integrated loudness in LUFS (-70..-5)
+	TP  float64 // maximum true peak in dBTP (-9..0)
+	LRA float64 // loudness range in LU (1..50)
+}
+
+var (
+	// LoudnessPodcast targets -16 LUFS, common for spoken word
+	LoudnessPodcast = LoudnessTarget{I: -16, TP: -1.5, LRA: 11}
+	// LoudnessBroadcast targets -23 LUFS per EBU R128
+	LoudnessBroadcast = LoudnessTarget{I: -23, TP: -1, LRA: 7}
+	// LoudnessStreaming targets -14 LUFS, used by most music streaming services
+	LoudnessStreaming = LoudnessTarget{I: -14, TP: -1, LRA: 11}
+)
+
+// Validate validates the loudness targets against the loudnorm ranges
+func (t LoudnessTarget) Validate() error {
+	if t.I < -70 || t.I > -5 {
+		return fmt.Errorf("loudnorm: integrated loudness must be between -70 and -5, got %v", t.I)
+	}
+	if t.TP < -9 || t.TP > 0 {
+		return fmt.Errorf("loudnorm: true peak must be between -9 and 0, got %v", t.TP)
+	}
+	if t.LRA < 1 || t.LRA > 50 {
+		return fmt.Errorf("loudnorm: loudness range must be between 1 and 50, got %v", t.LRA)
+	}
+	return nil
+}
+
+// LoudnormStats is the JSON block loudnorm prints with print_format=json.
+type LoudnormStats struct {
+	InputI            float64
+	InputTP           float64
+	InputLRA          float64
+	InputThresh       float64
+	OutputI           float64
+	OutputTP          float64
+	OutputLRA         float64
+	OutputThresh      float64
+	NormalizationType string
+	TargetOffset      float64
+}
+
+// UnmarshalJSON decodes loudnorm's output, which writes every number as a string.
+func (s *LoudnormStats) UnmarshalJSON(data []byte) error {
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	fields := map[string]*float64{
+		"input_i":       &s.InputI,
+		"input_tp":      &s.InputTP,
+		"input_lra":     &s.InputLRA,
+		"input_thresh":  &s.InputThresh,
+		"output_i":      &s.OutputI,
+		"output_tp":     &s.OutputTP,
+		"output_lra":    &s.OutputLRA,
+		"output_thresh": &s.OutputThresh,
+		"target_offset": &s.TargetOffset,
+	}
+	for key, dst := range fields {
+		value, ok := raw[key]
+		if !ok {
+			return fmt.Errorf("loudnorm: missing %q in measurement", key)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return fmt.Errorf("loudnorm: invalid %s value %q", key, value)
+		}
+		*dst = v
+	}
+	s.NormalizationType = raw["normalization_type"]
+
+	return nil
+}
+
+// ParseLoudnormStats extracts the last loudnorm JSON block from ffmpeg's stderr.
+func ParseLoudnormStats(stderr []byte) (*LoudnormStats, error) {
+	marker := bytes.LastIndex(stderr, []byte("Parsed_loudnorm"))
+	if marker < 0 {
+		return nil, fmt.Errorf("loudnorm: no measurement found in ffmpeg output")
+	}
+
+	start := bytes.IndexByte(stderr[marker:], '{')
+	if start < 0 {
+		return nil, fmt.Errorf("loudnorm: no JSON block found after loudnorm output")
+	}
+	start += marker
+	end := bytes.IndexByte(stderr[start:], '}')
+	if end < 0 {
+		return nil, fmt.Errorf("loudnorm: unterminated JSON block in ffmpeg output")
+	}
+
+	var stats LoudnormStats
+	if err := json.Unmarshal(stderr[start:start+end+1], &stats); err != nil {
+		return nil, fmt.Errorf("loudnorm: failed to decode measurement: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// LoudnormFilter renders: "[input]loudnorm=I=i:TP=tp:LRA=lra[:measured_*...]:print_format=fmt[output]"
+// With Measured set it renders the second, linear pass fed with the first pass results.
+type LoudnormFilter struct {
+	Input       string
+	Output      string
+	Target      LoudnessTarget
+	Measured    *LoudnormStats
+	PrintFormat string // "json", "summary" or "none"
+}
+
+func (f LoudnormFilter) Validate() error {
+	if strings.TrimSpace(f.Input) == "" {
+		return fmt.Errorf("loudnorm: input label cannot be empty")
+	}
+	if strings.TrimSpace(f.Output) == "" {
+		return fmt.Errorf("loudnorm: output label cannot be empty")
+	}
+	switch f.PrintFormat {
+	case "", "json", "summary", "none":
+	default:
+		return fmt.Errorf("loudnorm: invalid print format %q", f.PrintFormat)
+	}
+	if m := f.Measured; m != nil {
+		for _, v := range []float64{m.InputI, m.InputTP, m.InputLRA, m.InputThresh, m.TargetOffset} {
+			if math.IsInf(v, 0) || math.IsNaN(v) {
+				return fmt.Errorf("loudnorm: measured values must be finite (silent input?), got %+v", *m)
+			}
+		}
+	}
+	return f.Target.Validate()
+}
+
+func (f LoudnormFilter) Parse() string {
+	opts := []string{
+		"I=" + formatFloat(f.Target.I),
+		"TP=" + formatFloat(f.Target.TP),
+		"LRA=" + formatFloat(f.Target.LRA),
+	}
+	if m := f.Measured; m != nil {
+		opts = append(opts,
+			"measured_I="+formatFloat(m.InputI),
+			"measured_TP="+formatFloat(m.InputTP),
+			"measured_LRA="+formatFloat(m.InputLRA),
+			"measured_thresh="+formatFloat(m.InputThresh),
+			"offset="+formatFloat(m.TargetOffset),
+			"linear=true")
+	}
+	if f.PrintFormat != "" {
+		opts = append(opts, "print_format="+f.PrintFormat)
+	}
+	return fmt.Sprintf("[%s]loudnorm=%s[%s]", f.Input, strings.Join(opts, ":"), f.Output)
+}
+
+// WithLoudnorm adds a labeled loudnorm chain; pass measured stats for the second pass.
+func WithLoudnorm(input string, output string, target LoudnessTarget, measured *LoudnormStats, printFormat string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(LoudnormFilter{
+			Input:       strings.TrimSpace(input),
+			Output:      strings.TrimSpace(output),
+			Target:      target,
+			Measured:    measured,
+			PrintFormat: printFormat,
+		})
+	}
+}
+
+// LoudnessNormalize runs two-pass EBU R128 normalization: a measurement pass with
+// print_format=json into the null muxer, then a linear encode fed with the measured values.
+type LoudnessNormalize struct {
+	input      string
+	output     string
+	stream     string
+	target     LoudnessTarget
+	sampleRate int
+	keepVideo  bool
+	overwrite  bool
+	opts       []OutputFlagFn
+}
+
+// NewLoudnessNormalize creates a normalization job for the first audio stream of input.
+// loudnorm resamples internally, so the output is resampled to 48kHz by default.
+func NewLoudnessNormalize(input, output string, target LoudnessTarget) *LoudnessNormalize {
+	return &LoudnessNormalize{
+		input:      input,
+		output:     output,
+		stream:     "0:a:0",
+		target:     target,
+		sampleRate: 48000,
+		opts:       make([]OutputFlagFn, 0),
+	}
+}
+
+// Stream selects the audio stream to normalize, e.g. "0:a:1".
+func (l *LoudnessNormalize) Stream(stream string) *LoudnessNormalize {
+	l.stream = stream
+	return l
+}
+
+// SampleRate sets the output sample rate.
+func (l *LoudnessNormalize) SampleRate(rate int) *LoudnessNormalize {
+	l.sampleRate = rate
+	return l
+}
+
+// KeepVideo copies the input video streams, if any, into the output.
+func (l *LoudnessNormalize) KeepVideo() *LoudnessNormalize {
+	l.keepVideo = true
+	return l
+}
+
+// WithFlag appends an output option for the final encode (e.g. AudioCodecAAC).
+func (l *LoudnessNormalize) WithFlag(opt OutputFlagFn) *LoudnessNormalize {
+	l.opts = append(l.opts, opt)
+	return l
+}
+
+// Overwrite adds '-y' to the final encode.
+func (l *LoudnessNormalize) Overwrite() *LoudnessNormalize {
+	l.overwrite = true
+	return l
+}
+
+// MeasureCommand builds the first pass.
+func (l *LoudnessNormalize) MeasureCommand() *Ffmpego {
+	return New("").
+		WithOptions(NewFfmpegOptions(WithInput(l.input))).
+		WithFilterGraph(NewComplexFilterBuilder().
+			Add(WithLoudnorm(l.stream, "measured", l.target, nil, "json")).
+			Build()).
+		Output(NewNullOutput("[measured]"))
+}
+
+// NormalizeCommand builds the second pass from the measured values.
+func (l *LoudnessNormalize) NormalizeCommand(measured *LoudnormStats) *Ffmpego {
+	flags := []FfmpegFlagFn{WithInput(l.input)}
+	if l.overwrite {
+		flags = append(flags, WithOverwrite())
+	}
+
+	out := NewOutputBuilder().WithFlag(WithMap("[normalized]"))
+	if l.keepVideo {
+		out.WithFlag(WithMap("0:v?")).WithFlag(WithVideoCodec("copy"))
+	}
+	for _, opt := range l.opts {
+		out.WithFlag(opt)
+	}
+
+	return New("").
+		WithOptions(NewFfmpegOptions(flags...)).
+		WithFilterGraph(NewComplexFilterBuilder().
+			Add(WithLoudnorm(l.stream, "normalized", l.target, measured, "summary")).
+			Build()).
+		Output(out.WithFlag(WithSampleRate(l.sampleRate)).File(l.output).Build())
+}
+
+// Measure runs the first pass and returns the parsed measurement.
+func (l *LoudnessNormalize) Measure(ctx context.Context) (*LoudnormStats, error) {
+	stderr, err := NewRunner(l.MeasureCommand()).Capture(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseLoudnormStats(stderr)
+}
+
+// Run performs both passes and returns the first pass measurement.
+func (l *LoudnessNormalize) Run(ctx context.Context) (*LoudnormStats, error) {
+	measured, err := l.Measure(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := NewRunner(l.NormalizeCommand(measured)).Run(ctx); err != nil {
+		return measured, err
+	}
+
+	return measured, nil
+}
@@ -0,0 +1,73 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleLoudnormStderr = `Input #0, mp3, from 'in.mp3':
+  Duration: 00:30:00.00, start: 0.000000, bitrate: 128 kb/s
+[Parsed_loudnorm_0 @ 0x55d5c1a3e2c0]
+{
+	"input_i" : "-27.61",
+	"input_tp" : "-4.47",
+	"input_lra" : "18.06",
+	"input_thresh" : "-39.20",
+	"output_i" : "-16.58",
+	"output_tp" : "-1.50",
+	"output_lra" : "14.78",
+	"output_thresh" : "-27.71",
+	"normalization_type" : "dynamic",
+	"target_offset" : "0.58"
+}
+size=N/A time=00:30:00.00 bitrate=N/A speed= 412x
+`
+
+func TestParseLoudnormStats(t *testing.T) {
+	stats, err := ParseLoudnormStats([]byte(sampleLoudnormStderr))
+	if err != nil {
+		t.Fatalf("ParseLoudnormStats() error: %v", err)
+	}
+	if stats.InputI != -27.61 || stats.InputThresh != -39.2 || stats.TargetOffset != 0.58 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if stats.NormalizationType != "dynamic" {
+		t.Fatalf("normalization type mismatch: %q", stats.NormalizationType)
+	}
+
+	if _, err := ParseLoudnormStats([]byte("no measurement here")); err == nil {
+		t.Fatalf("expected error for missing measurement, got nil")
+	}
+}
+
+func TestLoudnessNormalize_Commands(t *testing.T) {
+	job := NewLoudnessNormalize("in.mp3", "out.m4a", LoudnessPodcast).WithFlag(AudioCodecAAC)
+
+	args, err := job.MeasureCommand().Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	want := "-i in.mp3 -filter_complex [0:a:0]loudnorm=I=-16:TP=-1.5:LRA=11:print_format=json[measured] -map [measured] -f null -"
+	if got := strings.Join(args, " "); got != want {
+		t.Fatalf("measure args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+
+	stats, _ := ParseLoudnormStats([]byte(sampleLoudnormStderr))
+	args, err = job.NormalizeCommand(stats).Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	want = "-i in.mp3 -filter_complex [0:a:0]loudnorm=I=-16:TP=-1.5:LRA=11:measured_I=-27.61:measured_TP=-4.47:" +
+		"measured_LRA=18.06:measured_thresh=-39.2:offset=0.58:linear=true:print_format=summary[normalized] " +
+		"-map [normalized] -c:a aac -ar 48000 out.m4a"
+	if got := strings.Join(args, " "); got != want {
+		t.Fatalf("normalize args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestLoudnormFilter_InvalidTarget(t *testing.T) {
+	f := LoudnormFilter{Input: "0:a", Output: "n", Target: LoudnessTarget{I: -3, TP: -1, LRA: 11}}
+	if err := f.Validate(); err == nil {
+		t.Fatalf("expected error for out of range integrated loudness, got nil")
+	}
+}
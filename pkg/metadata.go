@@ -0,0 +1,405 @@
+package ffmpego
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ffmetadataHeader is the first line of every FFMETADATA file.
+const ffmetadataHeader = ";FFMETADATA1"
+
+// MetadataFlag represents a -metadata option, global or scoped to a stream
+// specifier such as "s:a:0": "-metadata:s:a:0 language=eng"
+type MetadataFlag struct {
+	Specifier string
+	Key       string
+	Value     string
+}
+
+// Parse returns the metadata flag arguments
+func (f MetadataFlag) Parse() []string {
+	flag := "-metadata"
+	if f.Specifier != "" {
+		flag += ":" + f.Specifier
+	}
+	return []string{flag, f.Key + "=" + f.Value}
+}
+
+// Validate validates the metadata key and specifier
+func (f MetadataFlag) Validate() error {
+	if strings.TrimSpace(f.Key) == "" {
+		return fmt.Errorf("metadata key cannot be empty")
+	}
+	if strings.Contains(f.Key, "=") {
+		return fmt.Errorf("metadata key %q cannot contain '='", f.Key)
+	}
+	switch {
+	case f.Specifier == "", f.Specifier == "g",
+		strings.HasPrefix(f.Specifier, "c:"), strings.HasPrefix(f.Specifier, "p:"):
+		return nil
+	case strings.HasPrefix(f.Specifier, "s:"):
+		_, err := ParseStreamSpec(strings.TrimPrefix(f.Specifier, "s:"))
+		return err
+	}
+	return fmt.Errorf("metadata specifier must be g, s:<stream>, c:<chapter> or p:<program>, got %q", f.Specifier)
+}
+
+// DispositionFlag represents a per-stream -disposition option, e.g. "-disposition:a:1 default+forced".
+// An empty Dispositions list clears every flag ("0").
+type DispositionFlag struct {
+	Stream       StreamSpec
+	Dispositions []string
+}
+
+// Parse returns the disposition flag arguments
+func (f DispositionFlag) Parse() []string {
+	value := "0"
+	if len(f.Dispositions) > 0 {
+		value = strings.Join(f.Dispositions, "+")
+	}
+	return []string{"-disposition:" + f.Stream.String(), value}
+}
+
+// Validate validates the stream specifier and disposition names
+func (f DispositionFlag) Validate() error {
+	if f.Stream.IsMap() {
+		return fmt.Errorf("disposition stream specifier %q cannot have an input index", f.Stream)
+	}
+	if err := f.Stream.Validate(); err != nil {
+		return err
+	}
+	for _, d := range f.Dispositions {
+		if !isDisposition(d) {
+			return fmt.Errorf("unknown disposition %q", d)
+		}
+	}
+	return nil
+}
+
+// isDisposition reports whether name is a stream disposition known to ffmpeg
+func isDisposition(name string) bool {
+	switch name {
+	case "default", "dub", "original", "comment", "lyrics", "karaoke", "forced", "hearing_impaired",
+		"visual_impaired", "clean_effects", "attached_pic", "captions", "descriptions", "metadata",
+		"dependent", "still_image":
+		return true
+	}
+	return false
+}
+
+// MapMetadataFlag represents -map_metadata, copying global metadata from an input index (-1 drops it)
+type MapMetadataFlag int
+
+// Parse returns the map metadata flag arguments
+func (f MapMetadataFlag) Parse() []string {
+	return []string{"-map_metadata", strconv.Itoa(int(f))}
+}
+
+// Validate validates the map metadata flag
+func (f MapMetadataFlag) Validate() error {
+	if f < -1 {
+		return fmt.Errorf("map_metadata input must be -1 or an input index, got %d", f)
+	}
+	return nil
+}
+
+// MapChaptersFlag represents -map_chapters, copying chapters from an input index (-1 drops them)
+type MapChaptersFlag int
+
+// Parse returns the map chapters flag arguments
+func (f MapChaptersFlag) Parse() []string {
+	return []string{"-map_chapters", strconv.Itoa(int(f))}
+}
+
+// Validate validates the map chapters flag
+func (f MapChaptersFlag) Validate() error {
+	if f < -1 {
+		return fmt.Errorf("map_chapters input must be -1 or an input index, got %d", f)
+	}
+	return nil
+}
+
+// WithMetadata sets a global metadata tag, e.g. WithMetadata("title", "Episode 12")
+func WithMetadata(key, value string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(MetadataFlag{Key: key, Value: value})
+	}
+}
+
+// WithStreamMetadata sets a tag on the streams matching stream, e.g. WithStreamMetadata(AudioStream(0), "language", "eng")
+func WithStreamMetadata(stream StreamSpec, key, value string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(MetadataFlag{Specifier: "s:" + stream.String(), Key: key, Value: value})
+	}
+}
+
+// WithDisposition sets the dispositions of the streams matching stream; none clears them
+func WithDisposition(stream StreamSpec, dispositions ...string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(DispositionFlag{Stream: stream, Dispositions: dispositions})
+	}
+}
+
+// WithMapMetadata copies global metadata from the given input index (-1 to strip it)
+func WithMapMetadata(input int) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(MapMetadataFlag(input))
+	}
+}
+
+// WithMapChapters copies chapters from the given input index (-1 to strip them)
+func WithMapChapters(input int) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(MapChaptersFlag(input))
+	}
+}
+
+// Chapter is an FFMETADATA chapter. Tags holds extra keys besides the title.
+type Chapter struct {
+	Start time.Duration
+	End   time.Duration
+	Title string
+	Tags  map[string]string
+}
+
+// FFMetadata is the content of an FFMETADATA1 file: global tags, per-stream tags and
+// chapters. Attach it as an input and use WithMapMetadata/WithMapChapters with its index.
+type FFMetadata struct {
+	Global   map[string]string
+	Streams  []map[string]string
+	Chapters []Chapter
+}
+
+// Validate checks chapters are in order and do not overlap
+func (m *FFMetadata) Validate() error {
+	var previous time.Duration
+	for i, chapter := range m.Chapters {
+		if chapter.Start < 0 || chapter.End <= chapter.Start {
+			return fmt.Errorf("ffmetadata: chapter %d must end after it starts (%s-%s)", i, chapter.Start, chapter.End)
+		}
+		if chapter.Start < previous {
+			return fmt.Errorf("ffmetadata: chapter %d starts at %s, before the previous chapter ends", i, chapter.Start)
+		}
+		previous = chapter.End
+	}
+	return nil
+}
+
+// WriteTo writes the FFMETADATA1 representation, chapters in millisecond timebase.
+func (m *FFMetadata) WriteTo(w io.Writer) (int64, error) {
+	if err := m.Validate(); err != nil {
+		return 0, err
+	}
+
+	var b strings.Builder
+	b.WriteString(ffmetadataHeader + "\n")
+	writeMetadataTags(&b, m.Global)
+	for _, stream := range m.Streams {
+		b.WriteString("[STREAM]\n")
+		writeMetadataTags(&b, stream)
+	}
+	for _, chapter := range m.Chapters {
+		b.WriteString("[CHAPTER]\nTIMEBASE=1/1000\n")
+		fmt.Fprintf(&b, "START=%d\nEND=%d\n", chapter.Start.Milliseconds(), chapter.End.Milliseconds())
+		if chapter.Title != "" {
+			b.WriteString("title=" + escapeMetadata(chapter.Title) + "\n")
+		}
+		writeMetadataTags(&b, chapter.Tags)
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// WriteFile writes the metadata to path.
+func (m *FFMetadata) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("ffmetadata: failed to create %s: %w", path, err)
+	}
+	if _, err := m.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ParseFFMetadata reads an FFMETADATA1 file, undoing the backslash escaping of keys and values.
+func ParseFFMetadata(r io.Reader) (*FFMetadata, error) {
+	reader := bufio.NewReader(r)
+	header, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if strings.TrimRight(header, "\r\n") != ffmetadataHeader {
+		return nil, fmt.Errorf("ffmetadata: missing %s header", ffmetadataHeader)
+	}
+
+	meta := &FFMetadata{Global: make(map[string]string)}
+	tags := meta.Global
+	var chapter *chapterSection
+	for {
+		line, err := readMetadataLine(reader)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "#"):
+		case trimmed == "[STREAM]" || trimmed == "[CHAPTER]":
+			if chapter != nil {
+				meta.Chapters = append(meta.Chapters, chapter.finish())
+			}
+			tags = make(map[string]string)
+			chapter = nil
+			if trimmed == "[STREAM]" {
+				meta.Streams = append(meta.Streams, tags)
+			} else {
+				// ffmpeg assumes nanoseconds when TIMEBASE is missing
+				chapter = &chapterSection{chapter: Chapter{Tags: tags}, num: 1, den: int64(time.Second)}
+			}
+		default:
+			key, value, ok := splitMetadataLine(line)
+			if !ok {
+				return nil, fmt.Errorf("ffmetadata: invalid line %q", line)
+			}
+			if chapter == nil {
+				tags[key] = value
+			} else if perr := chapter.set(key, value); perr != nil {
+				return nil, perr
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if chapter != nil {
+		meta.Chapters = append(meta.Chapters, chapter.finish())
+	}
+
+	return meta, nil
+}
+
+// chapterSection accumulates a [CHAPTER] section; START and END are kept in timebase
+// units until the section ends, as TIMEBASE may come after them.
+type chapterSection struct {
+	chapter    Chapter
+	start, end int64
+	num, den   int64
+}
+
+func (c *chapterSection) set(key, value string) error {
+	switch key {
+	case "TIMEBASE":
+		num, den, err := parseRational(value)
+		if err != nil || num <= 0 || den <= 0 {
+			return fmt.Errorf("ffmetadata: invalid TIMEBASE %q", value)
+		}
+		c.num, c.den = num, den
+	case "START", "END":
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("ffmetadata: invalid %s %q", key, value)
+		}
+		if key == "START" {
+			c.start = v
+		} else {
+			c.end = v
+		}
+	case "title":
+		c.chapter.Title = value
+	default:
+		c.chapter.Tags[key] = value
+	}
+	return nil
+}
+
+func (c *chapterSection) finish() Chapter {
+	// float math avoids overflowing int64 with nanosecond timebases
+	unit := float64(c.num) / float64(c.den) * float64(time.Second)
+	c.chapter.Start = time.Duration(math.Round(float64(c.start) * unit))
+	c.chapter.End = time.Duration(math.Round(float64(c.end) * unit))
+	return c.chapter
+}
+
+// readMetadataLine reads a logical line: an escaped newline ("\" at the end of a
+// line) continues the value on the next line. Escapes are kept for splitMetadataLine.
+func readMetadataLine(r *bufio.Reader) (string, error) {
+	var line strings.Builder
+	for {
+		part, err := r.ReadString('\n')
+		line.WriteString(part)
+		if err != nil {
+			return strings.TrimRight(line.String(), "\r\n"), err
+		}
+		content := strings.TrimRight(part, "\r\n")
+		if trailingBackslashes(content)%2 == 0 {
+			return strings.TrimRight(line.String(), "\r\n"), nil
+		}
+	}
+}
+
+func trailingBackslashes(s string) int {
+	n := 0
+	for i := len(s) - 1; i >= 0 && s[i] == '\\'; i-- {
+		n++
+	}
+	return n
+}
+
+// splitMetadataLine splits "key=value" on the first unescaped '=' and unescapes both sides.
+func splitMetadataLine(line string) (string, string, bool) {
+	var key, current strings.Builder
+	found := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line):
+			i++
+			current.WriteByte(line[i])
+		case c == '=' && !found:
+			key.WriteString(current.String())
+			current.Reset()
+			found = true
+		default:
+			current.WriteByte(c)
+		}
+	}
+	if !found || key.Len() == 0 {
+		return "", "", false
+	}
+	return key.String(), current.String(), true
+}
+
+// escapeMetadata escapes the characters FFMETADATA treats specially: '=', ';', '#',
+// '\' and newlines are prefixed with a backslash.
+func escapeMetadata(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '=', ';', '#', '\\', '\n':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// writeMetadataTags writes tags sorted by key for a stable output.
+func writeMetadataTags(b *strings.Builder, tags map[string]string) {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		b.WriteString(escapeMetadata(key) + "=" + escapeMetadata(tags[key]) + "\n")
+	}
+}
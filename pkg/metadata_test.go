@@ -0,0 +1,85 @@
+package ffmpego
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetadataFlags(t *testing.T) {
+	args, err := NewOutputBuilder().
+		WithFlag(WithMetadata("title", "Episode 12")).
+		WithFlag(WithStreamMetadata(AudioStream(0), "language", "eng")).
+		WithFlag(WithDisposition(AudioStream(1), "default", "forced")).
+		WithFlag(WithDisposition(SubtitleStream(0))).
+		WithFlag(WithMapMetadata(1)).
+		WithFlag(WithMapChapters(1)).
+		File("out.m4a").
+		Build().
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	got := strings.Join(args, " ")
+	want := "-metadata title=Episode 12 -metadata:s:a:0 language=eng -disposition:a:1 default+forced " +
+		"-disposition:s:0 0 -map_metadata 1 -map_chapters 1 out.m4a"
+	if got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+
+	if err := (DispositionFlag{Stream: AudioStream(0), Dispositions: []string{"loud"}}).Validate(); err == nil {
+		t.Fatalf("expected error for unknown disposition, got nil")
+	}
+}
+
+func TestFFMetadata_RoundTrip(t *testing.T) {
+	meta := &FFMetadata{
+		Global: map[string]string{"title": "A=B; #1 \\ best\nof", "artist": "Someone"},
+		Chapters: []Chapter{
+			{Start: 0, End: 90 * time.Second, Title: "Intro"},
+			{Start: 90 * time.Second, End: 1500500 * time.Millisecond, Title: "Part 1", Tags: map[string]string{"comment": "x"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if _, err := meta.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error: %v", err)
+	}
+	want := ";FFMETADATA1\nartist=Someone\ntitle=A\\=B\\; \\#1 \\\\ best\\\nof\n" +
+		"[CHAPTER]\nTIMEBASE=1/1000\nSTART=0\nEND=90000\ntitle=Intro\n" +
+		"[CHAPTER]\nTIMEBASE=1/1000\nSTART=90000\nEND=1500500\ntitle=Part 1\ncomment=x\n"
+	if buf.String() != want {
+		t.Fatalf("ffmetadata mismatch:\n got: %q\nwant: %q", buf.String(), want)
+	}
+
+	parsed, err := ParseFFMetadata(&buf)
+	if err != nil {
+		t.Fatalf("ParseFFMetadata() error: %v", err)
+	}
+	if parsed.Global["title"] != meta.Global["title"] || parsed.Global["artist"] != "Someone" {
+		t.Fatalf("global tags mismatch: %+v", parsed.Global)
+	}
+	if len(parsed.Chapters) != 2 || parsed.Chapters[1].End != 1500500*time.Millisecond ||
+		parsed.Chapters[1].Title != "Part 1" || parsed.Chapters[1].Tags["comment"] != "x" {
+		t.Fatalf("chapters mismatch: %+v", parsed.Chapters)
+	}
+}
+
+func TestParseFFMetadata_Timebase(t *testing.T) {
+	parsed, err := ParseFFMetadata(strings.NewReader(";FFMETADATA1\n[CHAPTER]\nSTART=0\nEND=450\nTIMEBASE=1/90\ntitle=One\n"))
+	if err != nil {
+		t.Fatalf("ParseFFMetadata() error: %v", err)
+	}
+	if len(parsed.Chapters) != 1 || parsed.Chapters[0].End != 5*time.Second {
+		t.Fatalf("chapter mismatch: %+v", parsed.Chapters)
+	}
+
+	if _, err := ParseFFMetadata(strings.NewReader("title=x\n")); err == nil {
+		t.Fatalf("expected error without header, got nil")
+	}
+	if _, err := (&FFMetadata{Chapters: []Chapter{{Start: 5 * time.Second, End: time.Second}}}).WriteTo(&bytes.Buffer{}); err == nil {
+		t.Fatalf("expected error for chapter ending before it starts, got nil")
+	}
+}
@@ -0,0 +1,194 @@
+package ffmpego
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MPD is the subset of an MPEG-DASH manifest needed to verify packaging results.
+type MPD struct {
+	XMLName                   xml.Name    `xml:"MPD"`
+	Type                      string      `xml:"type,attr"`
+	Profiles                  string      `xml:"profiles,attr"`
+	MediaPresentationDuration string      `xml:"mediaPresentationDuration,attr"`
+	MinBufferTime             string      `xml:"minBufferTime,attr"`
+	Periods                   []MPDPeriod `xml:"Period"`
+}
+
+// MPDPeriod represents a Period element.
+type MPDPeriod struct {
+	ID             string             `xml:"id,attr"`
+	Start          string             `xml:"start,attr"`
+	AdaptationSets []MPDAdaptationSet `xml:"AdaptationSet"`
+}
+
+// MPDAdaptationSet represents an AdaptationSet element.
+type MPDAdaptationSet struct {
+	ID              string              `xml:"id,attr"`
+	ContentType     string              `xml:"contentType,attr"`
+	MimeType        string              `xml:"mimeType,attr"`
+	Lang            string              `xml:"lang,attr"`
+	Representations []MPDRepresentation `xml:"Representation"`
+}
+
+// MPDRepresentation represents a Representation element.
+// ContentType and MimeType are inherited from the parent AdaptationSet when absent.
+type MPDRepresentation struct {
+	ID                string `xml:"id,attr"`
+	ContentType       string `xml:"-"`
+	MimeType          string `xml:"mimeType,attr"`
+	Codecs            string `xml:"codecs,attr"`
+	Bandwidth         int64  `xml:"bandwidth,attr"`
+	Width             int    `xml:"width,attr"`
+	Height            int    `xml:"height,attr"`
+	FrameRate         string `xml:"frameRate,attr"`
+	AudioSamplingRate string `xml:"audioSamplingRate,attr"`
+}
+
+// ExpectedRepresentation describes a representation that must be present in an MPD.
+// Zero-valued fields are not checked.
+type ExpectedRepresentation struct {
+	ContentType string
+	Width       int
+	Height      int
+}
+
+func (e ExpectedRepresentation) String() string {
+	if e.Width > 0 || e.Height > 0 {
+		return fmt.Sprintf("%s %dx%d", e.ContentType, e.Width, e.Height)
+	}
+	return e.ContentType
+}
+
+func (e ExpectedRepresentation) matches(r MPDRepresentation) bool {
+	if e.ContentType != "" && e.ContentType != r.ContentType {
+		return false
+	}
+	if e.Width > 0 && e.Width != r.Width {
+		return false
+	}
+	if e.Height > 0 && e.Height != r.Height {
+		return false
+	}
+	return true
+}
+
+// ParseMPD decodes an MPD manifest.
+func ParseMPD(r io.Reader) (*MPD, error) {
+	var mpd MPD
+	if err := xml.NewDecoder(r).Decode(&mpd); err != nil {
+		return nil, fmt.Errorf("mpd: failed to decode manifest: %w", err)
+	}
+
+	for p := range mpd.Periods {
+		period := &mpd.Periods[p]
+		for s := range period.AdaptationSets {
+			set := &period.AdaptationSets[s]
+			for i := range set.Representations {
+				rep := &set.Representations[i]
+				if rep.MimeType == "" {
+					rep.MimeType = set.MimeType
+				}
+				rep.ContentType = set.ContentType
+				if rep.ContentType == "" {
+					rep.ContentType, _, _ = strings.Cut(rep.MimeType, "/")
+				}
+			}
+		}
+	}
+
+	return &mpd, nil
+}
+
+// ReadMPD opens and decodes the MPD manifest at path.
+func ReadMPD(path string) (*MPD, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("mpd: failed to open manifest: %w", err)
+	}
+	defer f.Close()
+
+	return ParseMPD(f)
+}
+
+// Representations returns every representation across all periods.
+func (m *MPD) Representations() []MPDRepresentation {
+	var reps []MPDRepresentation
+	for _, period := range m.Periods {
+		for _, set := range period.AdaptationSets {
+			reps = append(reps, set.Representations...)
+		}
+	}
+	return reps
+}
+
+// Duration parses the mediaPresentationDuration attribute.
+func (m *MPD) Duration() (time.Duration, error) {
+	return parseISODuration(m.MediaPresentationDuration)
+}
+
+// Verify checks that every expected representation is present, each matched
+// by a distinct Representation, and that all representations declare a bandwidth.
+func (m *MPD) Verify(expected ...ExpectedRepresentation) error {
+	reps := m.Representations()
+	if len(reps) == 0 {
+		return fmt.Errorf("mpd: manifest has no representations")
+	}
+	for _, rep := range reps {
+		if rep.Bandwidth <= 0 {
+			return fmt.Errorf("mpd: representation %q has no bandwidth", rep.ID)
+		}
+	}
+
+	used := make([]bool, len(reps))
+	var missing []string
+	for _, want := range expected {
+		found := false
+		for i, rep := range reps {
+			if !used[i] && want.matches(rep) {
+				used[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, want.String())
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("mpd: missing representations: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+var isoDurationRe = regexp.MustCompile(`^P(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// parseISODuration parses the ISO 8601 durations used in manifests, e.g. "PT0H1M30.500S".
+func parseISODuration(value string) (time.Duration, error) {
+	match := isoDurationRe.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil || value == "P" || value == "PT" {
+		return 0, fmt.Errorf("mpd: invalid ISO 8601 duration %q", value)
+	}
+
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var total time.Duration
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(match[i+1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("mpd: invalid ISO 8601 duration %q: %w", value, err)
+		}
+		total += time.Duration(v * float64(unit))
+	}
+
+	return total, nil
+}
@@ -35,3 +35,14 @@ func (b *OutputBuilder) Build() *OutputDescriptor {
 
 	return desc
 }
+
+// NewNullOutput maps the given streams to the null muxer ("-f null -"), discarding the
+// encoded result. Analysis passes use it to only run the filter graph.
+func NewNullOutput(streams ...string) *OutputDescriptor {
+	b := NewOutputBuilder()
+	for _, stream := range streams {
+		b.WithFlag(WithMap(stream))
+	}
+
+	return b.WithFlag(WithFormat("null")).File("-").Build()
+}
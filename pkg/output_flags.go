@@ -2,6 +2,7 @@ package ffmpego
 
 import (
 	"fmt"
+	"strings"
 )
 
 // Common output flag presets
@@ -42,8 +43,13 @@ func (oo *OutputDescriptor) Add(option OutputFlagParser) {
 }
 
 func (oo *OutputDescriptor) Build() ([]string, error) {
+	options, err := resolveEncoderOptions(oo.Options)
+	if err != nil {
+		return []string{}, err
+	}
+
 	var args []string
-	for _, flag := range oo.Options {
+	for _, flag := range options {
 		if err := flag.Validate(); err != nil {
 			return []string{}, err
 		}
@@ -51,7 +57,7 @@ func (oo *OutputDescriptor) Build() ([]string, error) {
 		args = append(args, flag.Parse()...)
 	}
 
-	return args, nil
+	return mergeEncoderParams(args), nil
 }
 
 func NewOutputDescriptor(opts ...OutputFlagFn) *OutputDescriptor {
@@ -84,6 +90,70 @@ func (f AudioCodec) Validate() error {
 	return nil
 }
 
+type SubtitleCodec string
+
+// Parse returns the subtitle codec flag arguments
+func (f SubtitleCodec) Parse() []string {
+	return []string{"-c:s", string(f)}
+}
+
+func (f SubtitleCodec) Validate() error {
+	return nil
+}
+
+// StreamCodecFlag represents a codec option for the streams matching a specifier, e.g. "-c:s:1 ass"
+type StreamCodecFlag struct {
+	Stream StreamSpec
+	Codec  string
+}
+
+// Parse returns the stream codec flag arguments
+func (f StreamCodecFlag) Parse() []string {
+	return []string{"-c:" + f.Stream.String(), f.Codec}
+}
+
+// Validate validates the stream codec flag
+func (f StreamCodecFlag) Validate() error {
+	if err := validateOptionSpec(f.Stream); err != nil {
+		return err
+	}
+	if strings.TrimSpace(f.Codec) == "" {
+		return fmt.Errorf("codec for stream %s cannot be empty", f.Stream)
+	}
+	return nil
+}
+
+// StreamBitrateFlag represents a bitrate option for the streams matching a specifier, e.g. "-b:a:1 96k"
+type StreamBitrateFlag struct {
+	Stream  StreamSpec
+	Bitrate string
+}
+
+// Parse returns the stream bitrate flag arguments
+func (f StreamBitrateFlag) Parse() []string {
+	return []string{"-b:" + f.Stream.String(), f.Bitrate}
+}
+
+// Validate validates the stream bitrate flag
+func (f StreamBitrateFlag) Validate() error {
+	if err := validateOptionSpec(f.Stream); err != nil {
+		return err
+	}
+	if strings.TrimSpace(f.Bitrate) == "" {
+		return fmt.Errorf("bitrate for stream %s cannot be empty", f.Stream)
+	}
+	return nil
+}
+
+// validateOptionSpec validates the specifier of a per-stream output option, which
+// refers to output streams and so cannot carry an input index.
+func validateOptionSpec(spec StreamSpec) error {
+	if spec.IsMap() {
+		return fmt.Errorf("per-stream option specifier %q cannot have an input index", spec)
+	}
+	return spec.Validate()
+}
+
 // WithVideoCodec creates a new video output codec flag
 func WithVideoCodec(codec string) OutputFlagFn {
 	return func(options *OutputDescriptor) {
@@ -98,6 +168,20 @@ func WithAudioCodec(codec string) OutputFlagFn {
 	}
 }
 
+// WithSubtitleCodec creates a new subtitle output codec flag, e.g. "mov_text"
+func WithSubtitleCodec(codec string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(SubtitleCodec(codec))
+	}
+}
+
+// WithStreamCodec creates a new codec flag for the streams matching stream, e.g. (AudioStream(1), "aac")
+func WithStreamCodec(stream StreamSpec, codec string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(StreamCodecFlag{Stream: stream, Codec: codec})
+	}
+}
+
 // WithCRF creates a new CRF flag
 func WithCRF(crf int) OutputFlagFn {
 	return func(options *OutputDescriptor) {
@@ -112,6 +196,13 @@ func WithBitrate(bitrate string) OutputFlagFn {
 	}
 }
 
+// WithStreamBitrate creates a new bitrate flag for the streams matching stream, e.g. (AudioStream(1), "96k")
+func WithStreamBitrate(stream StreamSpec, bitrate string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(StreamBitrateFlag{Stream: stream, Bitrate: bitrate})
+	}
+}
+
 // WithPreset creates a new preset flag
 func WithPreset(preset string) OutputFlagFn {
 	return func(options *OutputDescriptor) {
@@ -154,6 +245,98 @@ func WithMap(stream string) OutputFlagFn {
 	}
 }
 
+// WithMapSpec creates a new map flag from a typed specifier, e.g. InputStreams(0).OfType(MediaAudio).IfPresent()
+func WithMapSpec(spec StreamSpec) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(MapFlag(spec.String()))
+	}
+}
+
+// WithFrameRate creates a new output frame rate flag, e.g. "30" or "30000/1001"
+func WithFrameRate(rate string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(FrameRateFlag(rate))
+	}
+}
+
+// WithGOP creates a new GOP size (-g) flag
+func WithGOP(frames int) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(GOPFlag(frames))
+	}
+}
+
+// WithKeyintMin creates a new minimum keyframe interval flag
+func WithKeyintMin(frames int) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(KeyintMinFlag(frames))
+	}
+}
+
+// WithSceneChangeThreshold creates a new scene change threshold flag.
+// Use 0 to disable scene-cut keyframes so GOPs stay aligned across outputs.
+func WithSceneChangeThreshold(threshold int) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(SceneChangeThresholdFlag(threshold))
+	}
+}
+
+// WithForceKeyFrames creates a new force key frames flag, e.g. "expr:gte(t,n_forced*4)"
+func WithForceKeyFrames(value string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(ForceKeyFramesFlag(value))
+	}
+}
+
+// WithQScale creates a new fixed video quality scale flag (-q:v), e.g. 2..31 for JPEG
+func WithQScale(q int) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(QScaleFlag(q))
+	}
+}
+
+// WithFrames creates a new flag limiting the number of video frames written
+func WithFrames(frames int) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(FramesFlag(frames))
+	}
+}
+
+// WithFPSMode creates a new video sync method flag (-fps_mode), e.g. "vfr" or "passthrough"
+func WithFPSMode(mode string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(FPSModeFlag(mode))
+	}
+}
+
+// WithLoop creates a new animation loop count flag (0 = infinite, -1 = play once) for gif/webp
+func WithLoop(count int) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(LoopFlag(count))
+	}
+}
+
+// WithCodec creates a new codec flag (-c) applying to every mapped stream, e.g. "copy"
+func WithCodec(codec string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(CodecFlag(codec))
+	}
+}
+
+// WithAvoidNegativeTS creates a new -avoid_negative_ts flag, e.g. "make_zero" after a stream copy cut
+func WithAvoidNegativeTS(mode string) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(AvoidNegativeTSFlag(mode))
+	}
+}
+
+// WithDisabledStreams creates a new flag dropping every stream of type t, e.g. MediaVideo for -vn
+func WithDisabledStreams(t MediaType) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(DisableStreamsFlag(t))
+	}
+}
+
 // File represents an output file path
 type File string
 
@@ -281,13 +464,14 @@ func (f ChannelsFlag) Parse() []string {
 
 // Validate validates the channels flag
 func (f ChannelsFlag) Validate() error {
-	if f <= 0 || f > 8 {
-		return fmt.Errorf("channels must be between 1 and 8, got %d", f)
+	if f <= 0 || f > 64 {
+		return fmt.Errorf("channels must be between 1 and 64, got %d", f)
 	}
 	return nil
 }
 
-// MapFlag represents a stream mapping option
+// MapFlag represents a stream mapping option: a filtergraph label such as "[v720]" or a
+// stream specifier with input index such as "0:a:1?" (see StreamSpec)
 type MapFlag string
 
 // Parse returns the map flag arguments
@@ -300,5 +484,253 @@ func (f MapFlag) Validate() error {
 	if f == "" {
 		return fmt.Errorf("stream mapping cannot be empty")
 	}
+	if strings.HasPrefix(string(f), "[") {
+		if !strings.HasSuffix(string(f), "]") || len(f) < 3 {
+			return fmt.Errorf("invalid stream mapping label %q", string(f))
+		}
+		return nil
+	}
+	_, err := ParseMapSpec(string(f))
+	return err
+}
+
+// FrameRateFlag represents an output frame rate option
+type FrameRateFlag string
+
+// Parse returns the frame rate flag arguments
+func (f FrameRateFlag) Parse() []string {
+	return []string{"-r", string(f)}
+}
+
+// Validate validates the frame rate flag
+func (f FrameRateFlag) Validate() error {
+	fps, err := ParseFrameRate(string(f))
+	if err != nil || fps <= 0 {
+		return fmt.Errorf("frame rate must be a positive number or rational, got %q", string(f))
+	}
 	return nil
 }
+
+// GOPFlag represents a maximum keyframe interval option in frames
+type GOPFlag int
+
+// Parse returns the GOP flag arguments
+func (f GOPFlag) Parse() []string {
+	return []string{"-g", fmt.Sprintf("%d", int(f))}
+}
+
+// Validate validates the GOP flag
+func (f GOPFlag) Validate() error {
+	if f <= 0 {
+		return fmt.Errorf("GOP size must be positive, got %d", f)
+	}
+	return nil
+}
+
+// KeyintMinFlag represents a minimum keyframe interval option in frames
+type KeyintMinFlag int
+
+// Parse returns the keyint min flag arguments
+func (f KeyintMinFlag) Parse() []string {
+	return []string{"-keyint_min", fmt.Sprintf("%d", int(f))}
+}
+
+// Validate validates the keyint min flag
+func (f KeyintMinFlag) Validate() error {
+	if f <= 0 {
+		return fmt.Errorf("minimum keyframe interval must be positive, got %d", f)
+	}
+	return nil
+}
+
+// SceneChangeThresholdFlag represents a scene change threshold option
+type SceneChangeThresholdFlag int
+
+// Parse returns the scene change threshold flag arguments
+func (f SceneChangeThresholdFlag) Parse() []string {
+	return []string{"-sc_threshold", fmt.Sprintf("%d", int(f))}
+}
+
+// Validate validates the scene change threshold flag
+func (f SceneChangeThresholdFlag) Validate() error {
+	if f < 0 {
+		return fmt.Errorf("scene change threshold must be non-negative, got %d", f)
+	}
+	return nil
+}
+
+// ForceKeyFramesFlag represents a force key frames option
+type ForceKeyFramesFlag string
+
+// Parse returns the force key frames flag arguments
+func (f ForceKeyFramesFlag) Parse() []string {
+	return []string{"-force_key_frames", string(f)}
+}
+
+// Validate validates the force key frames flag: "expr:<expression>", "source",
+// "source_no_drop", "chapters[delta]" or a comma separated list of times
+func (f ForceKeyFramesFlag) Validate() error {
+	value := string(f)
+	switch {
+	case value == "":
+		return fmt.Errorf("force key frames cannot be empty")
+	case strings.HasPrefix(value, "expr:"):
+		if strings.TrimSpace(strings.TrimPrefix(value, "expr:")) == "" {
+			return fmt.Errorf("force key frames expression cannot be empty")
+		}
+		return nil
+	case value == "source", value == "source_no_drop", strings.HasPrefix(value, "chapters"):
+		return nil
+	}
+	for _, t := range strings.Split(value, ",") {
+		if _, err := ParseTimestamp(t); err != nil {
+			return fmt.Errorf("force key frames: invalid time %q", t)
+		}
+	}
+	return nil
+}
+
+// QScaleFlag represents a fixed video quality scale option
+type QScaleFlag int
+
+// Parse returns the quality scale flag arguments
+func (f QScaleFlag) Parse() []string {
+	return []string{"-q:v", fmt.Sprintf("%d", int(f))}
+}
+
+// Validate validates the quality scale flag
+func (f QScaleFlag) Validate() error {
+	if f < 1 || f > 31 {
+		return fmt.Errorf("quality scale must be between 1 and 31, got %d", f)
+	}
+	return nil
+}
+
+// FramesFlag represents a video frame count limit option
+type FramesFlag int
+
+// Parse returns the frames flag arguments
+func (f FramesFlag) Parse() []string {
+	return []string{"-frames:v", fmt.Sprintf("%d", int(f))}
+}
+
+// Validate validates the frames flag
+func (f FramesFlag) Validate() error {
+	if f <= 0 {
+		return fmt.Errorf("frame count must be positive, got %d", f)
+	}
+	return nil
+}
+
+// FPSModeFlag represents a video sync method option
+type FPSModeFlag string
+
+// Parse returns the fps mode flag arguments
+func (f FPSModeFlag) Parse() []string {
+	return []string{"-fps_mode", string(f)}
+}
+
+// Validate validates the fps mode flag
+func (f FPSModeFlag) Validate() error {
+	switch f {
+	case "passthrough", "cfr", "vfr", "drop", "auto":
+		return nil
+	}
+	return fmt.Errorf("fps mode must be one of passthrough, cfr, vfr, drop or auto, got %q", string(f))
+}
+
+// LoopFlag represents an animated output loop count option. The gif muxer takes -1 (no
+// loop); the webp muxer only 0..65535 (see AnimatedImage.Validate).
+type LoopFlag int
+
+// Parse returns the loop flag arguments
+func (f LoopFlag) Parse() []string {
+	return []string{"-loop", fmt.Sprintf("%d", int(f))}
+}
+
+// Validate validates the loop flag
+func (f LoopFlag) Validate() error {
+	if f < -1 {
+		return fmt.Errorf("loop count must be -1 (no loop), 0 (infinite) or positive, got %d", f)
+	}
+	return nil
+}
+
+// WebPQualityFlag represents the libwebp -quality option (0..100)
+type WebPQualityFlag int
+
+// Parse returns the webp quality flag arguments
+func (f WebPQualityFlag) Parse() []string {
+	return []string{"-quality", fmt.Sprintf("%d", int(f))}
+}
+
+// Validate validates the webp quality flag
+func (f WebPQualityFlag) Validate() error {
+	if f < 0 || f > 100 {
+		return fmt.Errorf("webp quality must be between 0 and 100, got %d", f)
+	}
+	return nil
+}
+
+// WebPLosslessFlag represents the libwebp -lossless option
+type WebPLosslessFlag bool
+
+// Parse returns the webp lossless flag arguments
+func (f WebPLosslessFlag) Parse() []string {
+	return []string{"-lossless", boolArg(bool(f))}
+}
+
+// Validate validates the webp lossless flag
+func (f WebPLosslessFlag) Validate() error {
+	return nil
+}
+
+// CodecFlag represents a codec option for all streams
+type CodecFlag string
+
+// Parse returns the codec flag arguments
+func (f CodecFlag) Parse() []string {
+	return []string{"-c", string(f)}
+}
+
+// Validate validates the codec flag
+func (f CodecFlag) Validate() error {
+	if f == "" {
+		return fmt.Errorf("codec cannot be empty")
+	}
+	return nil
+}
+
+// AvoidNegativeTSFlag represents the muxer -avoid_negative_ts option
+type AvoidNegativeTSFlag string
+
+// Parse returns the avoid negative ts flag arguments
+func (f AvoidNegativeTSFlag) Parse() []string {
+	return []string{"-avoid_negative_ts", string(f)}
+}
+
+// Validate validates the avoid negative ts flag
+func (f AvoidNegativeTSFlag) Validate() error {
+	switch f {
+	case "auto", "disabled", "make_non_negative", "make_zero":
+		return nil
+	}
+	return fmt.Errorf("avoid_negative_ts must be one of auto, disabled, make_non_negative or make_zero, got %q", string(f))
+}
+
+// DisableStreamsFlag represents the -vn, -an, -sn and -dn options
+type DisableStreamsFlag MediaType
+
+// Parse returns the disable streams flag arguments
+func (f DisableStreamsFlag) Parse() []string {
+	return []string{"-" + string(f) + "n"}
+}
+
+// Validate validates the disable streams flag
+func (f DisableStreamsFlag) Validate() error {
+	switch MediaType(f) {
+	case MediaVideo, MediaAudio, MediaSubtitle, MediaData:
+		return nil
+	}
+	return fmt.Errorf("disabled stream type must be one of v, a, s or d, got %q", string(f))
+}
@@ -0,0 +1,176 @@
+package ffmpego
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// OverlayPosition places an overlay relative to the main video
+type OverlayPosition string
+
+const (
+	OverlayTopLeft     OverlayPosition = "top-left"
+	OverlayTopRight    OverlayPosition = "top-right"
+	OverlayBottomLeft  OverlayPosition = "bottom-left"
+	OverlayBottomRight OverlayPosition = "bottom-right"
+	OverlayCenter      OverlayPosition = "center"
+)
+
+// expressions returns the overlay x and y expressions for the position, margin pixels in
+// from the edges (the margin does not apply to the center).
+func (p OverlayPosition) expressions(margin int) (string, string, error) {
+	near := fmt.Sprintf("%d", margin)
+	farX, farY := "main_w-overlay_w", "main_h-overlay_h"
+	if margin > 0 {
+		farX += fmt.Sprintf("-%d", margin)
+		farY += fmt.Sprintf("-%d", margin)
+	}
+	switch p {
+	case OverlayTopLeft:
+		return near, near, nil
+	case OverlayTopRight:
+		return farX, near, nil
+	case OverlayBottomLeft:
+		return near, farY, nil
+	case OverlayBottomRight:
+		return farX, farY, nil
+	case OverlayCenter:
+		return "(main_w-overlay_w)/2", "(main_h-overlay_h)/2", nil
+	}
+	return "", "", fmt.Errorf("overlay: unknown position %q", string(p))
+}
+
+// OverlayFilter renders a watermark or picture-in-picture overlay of Overlay on Main:
+//
+//	[1:v][0:v]scale2ref=w=oh*mdar:h=ih*0.1[out_ovl][out_main];
+//	[out_ovl]format=rgba,colorchannelmixer=aa=0.6,fade=t=in:st=2:d=1:alpha=1[out_ovlp];
+//	[out_main][out_ovlp]overlay=x=main_w-overlay_w-24:y=24:enable='between(t,2,10)':shortest=1[out]
+//
+// The scaled and faded overlay passes through Output_ovl, Output_main and Output_ovlp.
+// Scale sets the overlay height as a fraction of the main height, keeping its aspect ratio.
+// Opacity 0 leaves the overlay as is. End 0 keeps the overlay until the end; fades need an
+// End. X and Y, when set, replace the position expressions. Shortest ends the output with
+// the main video, which a looped image (WithInputLoop) needs.
+type OverlayFilter struct {
+	Main     string
+	Overlay  string
+	Output   string
+	Position OverlayPosition
+	Margin   int
+	X        string
+	Y        string
+	Scale    float64
+	Opacity  float64
+	Start    time.Duration
+	End      time.Duration
+	FadeIn   time.Duration
+	FadeOut  time.Duration
+	Shortest bool
+}
+
+func (f OverlayFilter) Validate() error {
+	if err := checkLabels("overlay", f.Main, f.Overlay, f.Output); err != nil {
+		return err
+	}
+	if f.X == "" || f.Y == "" {
+		if _, _, err := f.Position.expressions(f.Margin); err != nil {
+			return err
+		}
+	}
+	if f.Margin < 0 {
+		return fmt.Errorf("overlay: margin must be non-negative, got %d", f.Margin)
+	}
+	if math.IsNaN(f.Scale) || f.Scale < 0 || f.Scale > 1 {
+		return fmt.Errorf("overlay: scale must be between 0 and 1 of the main height, got %v", f.Scale)
+	}
+	if math.IsNaN(f.Opacity) || f.Opacity < 0 || f.Opacity > 1 {
+		return fmt.Errorf("overlay: opacity must be between 0 and 1, got %v", f.Opacity)
+	}
+	if f.Start < 0 || f.FadeIn < 0 || f.FadeOut < 0 {
+		return fmt.Errorf("overlay: start and fade durations must be non-negative")
+	}
+	if f.End != 0 && f.End <= f.Start {
+		return fmt.Errorf("overlay: end %s must be after start %s", f.End, f.Start)
+	}
+	if f.FadeOut > 0 && f.End == 0 {
+		return fmt.Errorf("overlay: fade out requires an end time")
+	}
+	if f.End != 0 && f.FadeIn+f.FadeOut > f.End-f.Start {
+		return fmt.Errorf("overlay: fades (%s + %s) are longer than the overlay window %s", f.FadeIn, f.FadeOut, f.End-f.Start)
+	}
+	return nil
+}
+
+func (f OverlayFilter) Parse() string {
+	var chains []string
+	main, overlay := f.Main, f.Overlay
+
+	if f.Scale > 0 {
+		scaled, ref := f.Output+"_ovl", f.Output+"_main"
+		chains = append(chains, fmt.Sprintf("[%s][%s]scale2ref=w=oh*mdar:h=ih*%s[%s][%s]",
+			overlay, main, formatFloat(f.Scale), scaled, ref))
+		main, overlay = ref, scaled
+	}
+
+	var prep []string
+	if f.Opacity > 0 && f.Opacity < 1 {
+		prep = append(prep, "colorchannelmixer=aa="+formatFloat(f.Opacity))
+	}
+	if f.FadeIn > 0 {
+		prep = append(prep, fmt.Sprintf("fade=t=in:st=%s:d=%s:alpha=1", formatSeconds(f.Start), formatSeconds(f.FadeIn)))
+	}
+	if f.FadeOut > 0 {
+		prep = append(prep, fmt.Sprintf("fade=t=out:st=%s:d=%s:alpha=1", formatSeconds(f.End-f.FadeOut), formatSeconds(f.FadeOut)))
+	}
+	if len(prep) > 0 {
+		prepared := f.Output + "_ovlp"
+		chains = append(chains, fmt.Sprintf("[%s]format=rgba,%s[%s]", overlay, strings.Join(prep, ","), prepared))
+		overlay = prepared
+	}
+
+	x, y, _ := f.Position.expressions(f.Margin)
+	if f.X != "" {
+		x = f.X
+	}
+	if f.Y != "" {
+		y = f.Y
+	}
+	opts := []string{"x=" + x, "y=" + y}
+	if enable := f.enable(); enable != "" {
+		opts = append(opts, "enable='"+enable+"'")
+	}
+	if f.Shortest {
+		opts = append(opts, "shortest=1")
+	}
+	chains = append(chains, fmt.Sprintf("[%s][%s]overlay=%s[%s]", main, overlay, strings.Join(opts, ":"), f.Output))
+	return strings.Join(chains, ";")
+}
+
+// enable returns the timeline expression limiting the overlay to Start..End.
+func (f OverlayFilter) enable() string {
+	switch {
+	case f.End > 0:
+		return fmt.Sprintf("between(t,%s,%s)", formatSeconds(f.Start), formatSeconds(f.End))
+	case f.Start > 0:
+		return fmt.Sprintf("gte(t,%s)", formatSeconds(f.Start))
+	}
+	return ""
+}
+
+// Labeled returns a FilterFn adding the overlay with the given labels, e.g.
+// OverlayFilter{Position: OverlayTopRight, Margin: 24, Opacity: 0.6}.Labeled("0:v", "1:v", "out").
+func (f OverlayFilter) Labeled(main, overlay, output string) FilterFn {
+	f.Main = strings.TrimSpace(main)
+	f.Overlay = strings.TrimSpace(overlay)
+	f.Output = strings.TrimSpace(output)
+	return withFilter(f)
+}
+
+// WithOverlay adds an overlay of overlay on main at a preset position, margin pixels in from
+// the edges. The output ends with the main video.
+// Renders: "[main][overlay]overlay=x=main_w-overlay_w-24:y=24:shortest=1[output]"
+func WithOverlay(main string, overlay string, output string, position OverlayPosition, margin int) FilterFn {
+	return OverlayFilter{Position: position, Margin: margin, Shortest: true}.Labeled(main, overlay, output)
+}
@@ -0,0 +1,118 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOverlayFilter_ValidateParse(t *testing.T) {
+	f := OverlayFilter{
+		Main:     "0:v",
+		Overlay:  "1:v",
+		Output:   "wm",
+		Position: OverlayBottomLeft,
+		Scale:    0.1,
+		Opacity:  0.6,
+		Start:    2 * time.Second,
+		End:      10 * time.Second,
+		FadeIn:   time.Second,
+		FadeOut:  500 * time.Millisecond,
+	}
+	if err := f.Validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	got := f.Parse()
+	want := "[1:v][0:v]scale2ref=w=oh*mdar:h=ih*0.1[wm_ovl][wm_main];" +
+		"[wm_ovl]format=rgba,colorchannelmixer=aa=0.6,fade=t=in:st=2:d=1:alpha=1,fade=t=out:st=9.5:d=0.5:alpha=1[wm_ovlp];" +
+		"[wm_main][wm_ovlp]overlay=x=0:y=main_h-overlay_h:enable='between(t,2,10)'[wm]"
+	if got != want {
+		t.Fatalf("parse mismatch: got %q want %q", got, want)
+	}
+}
+
+func TestWithOverlay_Positions(t *testing.T) {
+	graph, err := NewComplexFilterBuilder().
+		Add(WithOverlay("0:v", "1:v", "corner", OverlayTopRight, 24)).
+		Add(WithOverlay("corner", "2:v", "out", OverlayCenter, 24)).
+		Build().
+		BuildAndValidate()
+	if err != nil {
+		t.Fatalf("BuildAndValidate() error: %v", err)
+	}
+	want := "[0:v][1:v]overlay=x=main_w-overlay_w-24:y=24:shortest=1[corner];" +
+		"[corner][2:v]overlay=x=(main_w-overlay_w)/2:y=(main_h-overlay_h)/2:shortest=1[out]"
+	if graph != want {
+		t.Fatalf("graph mismatch:\n got: %s\nwant: %s", graph, want)
+	}
+}
+
+func TestOverlayFilter_PictureInPicture(t *testing.T) {
+	f := OverlayFilter{Main: "0:v", Overlay: "1:v", Output: "pip", X: "W-w-40", Y: "40", Scale: 0.25, Start: 5 * time.Second}
+	if err := f.Validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	got := f.Parse()
+	want := "[1:v][0:v]scale2ref=w=oh*mdar:h=ih*0.25[pip_ovl][pip_main];" +
+		"[pip_main][pip_ovl]overlay=x=W-w-40:y=40:enable='gte(t,5)'[pip]"
+	if got != want {
+		t.Fatalf("parse mismatch: got %q want %q", got, want)
+	}
+}
+
+func TestOverlayFilter_InvalidPosition(t *testing.T) {
+	cases := []OverlayFilter{
+		{Main: "0:v", Overlay: "1:v", Output: "out", Position: "middle"},
+		{Main: "0:v", Overlay: "1:v", Output: "out", Position: OverlayTopLeft, Margin: -1},
+	}
+	for i, f := range cases {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("case %d: expected error for position, got nil", i)
+		}
+	}
+}
+
+func TestOverlayFilter_InvalidScaleOpacity(t *testing.T) {
+	cases := []OverlayFilter{
+		{Main: "0:v", Overlay: "1:v", Output: "out", Position: OverlayTopLeft, Scale: 1.5},
+		{Main: "0:v", Overlay: "1:v", Output: "out", Position: OverlayTopLeft, Opacity: 2},
+	}
+	for i, f := range cases {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("case %d: expected error for scale or opacity, got nil", i)
+		}
+	}
+}
+
+func TestOverlayFilter_InvalidTiming(t *testing.T) {
+	cases := []OverlayFilter{
+		{Main: "0:v", Overlay: "1:v", Output: "out", Position: OverlayTopLeft, Start: 5 * time.Second, End: 2 * time.Second},
+		{Main: "0:v", Overlay: "1:v", Output: "out", Position: OverlayTopLeft, FadeOut: time.Second},
+		{Main: "0:v", Overlay: "1:v", Output: "out", Position: OverlayTopLeft, End: 2 * time.Second, FadeIn: time.Second, FadeOut: 2 * time.Second},
+	}
+	for i, f := range cases {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("case %d: expected error for timing, got nil", i)
+		}
+	}
+}
+
+func TestOverlayFilter_InvalidLabels(t *testing.T) {
+	f := OverlayFilter{Main: "0:v", Overlay: " ", Output: "out", Position: OverlayTopLeft}
+	if err := f.Validate(); err == nil {
+		t.Fatalf("expected error for blank overlay label, got nil")
+	}
+}
+
+func TestWithInputLoop(t *testing.T) {
+	args, err := New("").
+		WithOptions(NewFfmpegOptions(WithInput("in.mp4"), WithInputFile("logo.png", WithInputLoop()))).
+		Output(NewOutputBuilder().File("out.mp4").Build()).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if got := strings.Join(args, " "); !strings.Contains(got, "-loop 1 -i logo.png") {
+		t.Fatalf("args %q missing looped image input", got)
+	}
+}
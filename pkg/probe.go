@@ -0,0 +1,325 @@
+package ffmpego
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProbeFormat holds the container information reported by ffprobe -show_format.
+type ProbeFormat struct {
+	Filename   string            `json:"filename"`
+	NbStreams  int               `json:"nb_streams"`
+	FormatName string            `json:"format_name"`
+	StartTime  string            `json:"start_time,omitempty"`
+	Duration   string            `json:"duration,omitempty"`
+	Size       string            `json:"size,omitempty"`
+	BitRate    string            `json:"bit_rate,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+}
+
+// ProbeSideData holds a side data entry of a stream (e.g. display matrix rotation, or
+// HDR10 mastering display and content light level metadata).
+type ProbeSideData struct {
+	SideDataType string `json:"side_data_type"`
+	Rotation     int    `json:"rotation,omitempty"`
+
+	// "Mastering display metadata", as rationals such as "34000/50000"
+	RedX         string `json:"red_x,omitempty"`
+	RedY         string `json:"red_y,omitempty"`
+	GreenX       string `json:"green_x,omitempty"`
+	GreenY       string `json:"green_y,omitempty"`
+	BlueX        string `json:"blue_x,omitempty"`
+	BlueY        string `json:"blue_y,omitempty"`
+	WhitePointX  string `json:"white_point_x,omitempty"`
+	WhitePointY  string `json:"white_point_y,omitempty"`
+	MinLuminance string `json:"min_luminance,omitempty"`
+	MaxLuminance string `json:"max_luminance,omitempty"`
+
+	// "Content light level metadata", in cd/m²
+	MaxContent int `json:"max_content,omitempty"`
+	MaxAverage int `json:"max_average,omitempty"`
+}
+
+// ProbeStream holds the stream information reported by ffprobe -show_streams.
+type ProbeStream struct {
+	Index             int               `json:"index"`
+	CodecName         string            `json:"codec_name"`
+	CodecType         string            `json:"codec_type"`
+	Profile           string            `json:"profile,omitempty"`
+	Width             int               `json:"width,omitempty"`
+	Height            int               `json:"height,omitempty"`
+	SampleAspectRatio string            `json:"sample_aspect_ratio,omitempty"`
+	PixFmt            string            `json:"pix_fmt,omitempty"`
+	ColorRange        string            `json:"color_range,omitempty"`
+	ColorSpace        string            `json:"color_space,omitempty"`
+	ColorTransfer     string            `json:"color_transfer,omitempty"`
+	ColorPrimaries    string            `json:"color_primaries,omitempty"`
+	RFrameRate        string            `json:"r_frame_rate,omitempty"`
+	AvgFrameRate      string            `json:"avg_frame_rate,omitempty"`
+	SampleRate        string            `json:"sample_rate,omitempty"`
+	Channels          int               `json:"channels,omitempty"`
+	ChannelLayout     string            `json:"channel_layout,omitempty"`
+	StartTime         string            `json:"start_time,omitempty"`
+	Duration          string            `json:"duration,omitempty"`
+	BitRate           string            `json:"bit_rate,omitempty"`
+	Tags              map[string]string `json:"tags,omitempty"`
+	Disposition       map[string]int    `json:"disposition,omitempty"`
+	SideDataList      []ProbeSideData   `json:"side_data_list,omitempty"`
+}
+
+// ProbeResult is the decoded output of ffprobe -show_format -show_streams.
+type ProbeResult struct {
+	Format  ProbeFormat   `json:"format"`
+	Streams []ProbeStream `json:"streams"`
+}
+
+// FfprobeRunner executes ffprobe and decodes its JSON output.
+type FfprobeRunner struct {
+	binary        string
+	commandRunner CommandRunner
+}
+
+// NewProber creates a new ffprobe runner. Pass "" to use "ffprobe" from PATH.
+func NewProber(binary string) *FfprobeRunner {
+	if binary == "" {
+		binary = "ffprobe"
+	}
+
+	return &FfprobeRunner{
+		binary:        binary,
+		commandRunner: &NativeCommandHandler{},
+	}
+}
+
+// Probe inspects input with the default ffprobe binary.
+func Probe(ctx context.Context, input string) (*ProbeResult, error) {
+	return NewProber("").Probe(ctx, input)
+}
+
+// Probe runs ffprobe against input and returns its format and streams.
+func (p *FfprobeRunner) Probe(ctx context.Context, input string) (*ProbeResult, error) {
+	output, err := p.run(ctx,
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+		input)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseProbe(output)
+}
+
+// Keyframes returns the presentation times of the video keyframes of input between
+// from and to. ffprobe seeks to the keyframe at or before from, so the first entry
+// is where a fast input seek to from would start. A zero to reads until the end.
+func (p *FfprobeRunner) Keyframes(ctx context.Context, input string, from, to time.Duration) ([]time.Duration, error) {
+	interval := formatSeconds(from) + "%"
+	if to > 0 {
+		interval += formatSeconds(to)
+	}
+
+	output, err := p.run(ctx,
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-read_intervals", interval,
+		"-show_entries", "packet=pts_time,flags",
+		"-print_format", "json",
+		input)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseKeyframes(output)
+}
+
+// run executes ffprobe and returns its stdout.
+func (p *FfprobeRunner) run(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := p.commandRunner.CommandContext(ctx, p.binary, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w\nOutput: %s", err, stderr.String())
+	}
+
+	return output, nil
+}
+
+// ParseProbe decodes ffprobe JSON output.
+func ParseProbe(data []byte) (*ProbeResult, error) {
+	var result ProbeResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("probe: failed to decode ffprobe output: %w", err)
+	}
+
+	return &result, nil
+}
+
+// Duration returns the container duration, or 0 when unknown.
+func (r *ProbeResult) Duration() time.Duration {
+	return parseSecondsDuration(r.Format.Duration)
+}
+
+// VideoStream returns the first video stream that is not an attached picture (cover art).
+func (r *ProbeResult) VideoStream() (ProbeStream, bool) {
+	for _, s := range r.Streams {
+		if s.CodecType == "video" && s.Disposition["attached_pic"] == 0 {
+			return s, true
+		}
+	}
+	return ProbeStream{}, false
+}
+
+// AudioStreams returns all audio streams in index order.
+func (r *ProbeResult) AudioStreams() []ProbeStream {
+	var streams []ProbeStream
+	for _, s := range r.Streams {
+		if s.CodecType == "audio" {
+			streams = append(streams, s)
+		}
+	}
+	return streams
+}
+
+// FrameRate returns the average frame rate, falling back to r_frame_rate.
+func (s ProbeStream) FrameRate() float64 {
+	if fps, err := ParseFrameRate(s.AvgFrameRate); err == nil && fps > 0 {
+		return fps
+	}
+	if fps, err := ParseFrameRate(s.RFrameRate); err == nil && fps > 0 {
+		return fps
+	}
+	return 0
+}
+
+// Rotation returns the display rotation in degrees, normalized to 0, 90, 180 or 270.
+func (s ProbeStream) Rotation() int {
+	rotation := 0
+	if v, ok := s.Tags["rotate"]; ok {
+		rotation, _ = strconv.Atoi(v)
+	}
+	for _, sd := range s.SideDataList {
+		if sd.Rotation != 0 {
+			rotation = sd.Rotation
+		}
+	}
+	return ((rotation % 360) + 360) % 360
+}
+
+// DisplaySize returns width and height after applying the display rotation,
+// which is what filters see since ffmpeg autorotates by default.
+func (s ProbeStream) DisplaySize() (int, int) {
+	if r := s.Rotation(); r == 90 || r == 270 {
+		return s.Height, s.Width
+	}
+	return s.Width, s.Height
+}
+
+// SAR returns the sample (pixel) aspect ratio, e.g. 64:45 for anamorphic PAL widescreen,
+// or 1 when it is unset, unknown ("0:1") or invalid.
+func (s ProbeStream) SAR() float64 {
+	num, den, err := parseRational(strings.Replace(s.SampleAspectRatio, ":", "/", 1))
+	if err != nil || num <= 0 {
+		return 1
+	}
+	return float64(num) / float64(den)
+}
+
+// DisplayAspectRatio returns the width to height ratio the stream is meant to be shown at,
+// applying the sample aspect ratio and the display rotation.
+func (s ProbeStream) DisplayAspectRatio() float64 {
+	if s.Width <= 0 || s.Height <= 0 {
+		return 0
+	}
+	ratio := float64(s.Width) * s.SAR() / float64(s.Height)
+	if r := s.Rotation(); r == 90 || r == 270 {
+		return 1 / ratio
+	}
+	return ratio
+}
+
+// ParseFrameRate parses a frame rate written as a rational ("30000/1001") or decimal ("29.97").
+func ParseFrameRate(value string) (float64, error) {
+	num, den, err := parseRational(value)
+	if err != nil {
+		return 0, err
+	}
+	return float64(num) / float64(den), nil
+}
+
+// parseRational parses "num/den" or a decimal value into a reduced fraction.
+func parseRational(value string) (int64, int64, error) {
+	value = strings.TrimSpace(value)
+	if n, d, ok := strings.Cut(value, "/"); ok {
+		num, err := strconv.ParseInt(n, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid rational %q: %w", value, err)
+		}
+		den, err := strconv.ParseInt(d, 10, 64)
+		if err != nil || den <= 0 {
+			return 0, 0, fmt.Errorf("invalid rational %q: denominator must be positive", value)
+		}
+		g := gcd(num, den)
+		return num / g, den / g, nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0, 0, fmt.Errorf("invalid rational %q", value)
+	}
+	num, den := int64(math.Round(f*1000)), int64(1000)
+	g := gcd(num, den)
+	return num / g, den / g, nil
+}
+
+func gcd(a, b int64) int64 {
+	if a < 0 {
+		a = -a
+	}
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a == 0 {
+		return 1
+	}
+	return a
+}
+
+// ParseKeyframes decodes ffprobe -show_entries packet=pts_time,flags JSON output and
+// returns the times of packets flagged as keyframes ("K_"), in order.
+func ParseKeyframes(data []byte) ([]time.Duration, error) {
+	var result struct {
+		Packets []struct {
+			PTSTime string `json:"pts_time"`
+			Flags   string `json:"flags"`
+		} `json:"packets"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("probe: failed to decode ffprobe packets: %w", err)
+	}
+
+	var keyframes []time.Duration
+	for _, packet := range result.Packets {
+		if strings.HasPrefix(packet.Flags, "K") && packet.PTSTime != "" {
+			keyframes = append(keyframes, parseSecondsDuration(packet.PTSTime))
+		}
+	}
+	return keyframes, nil
+}
+
+// parseSecondsDuration parses ffprobe decimal seconds ("12.345000") into a duration.
+func parseSecondsDuration(value string) time.Duration {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
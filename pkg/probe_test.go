@@ -0,0 +1,59 @@
+package ffmpego
+
+import (
+	"testing"
+	"time"
+)
+
+const sampleProbe = `{
+	"streams": [
+		{"index": 0, "codec_name": "h264", "codec_type": "video", "width": 1920, "height": 1080,
+		 "pix_fmt": "yuv420p", "r_frame_rate": "60000/1001", "avg_frame_rate": "60000/1001"},
+		{"index": 1, "codec_name": "aac", "codec_type": "audio", "sample_rate": "48000", "channels": 2,
+		 "channel_layout": "stereo"},
+		{"index": 2, "codec_name": "mjpeg", "codec_type": "video", "width": 600, "height": 600,
+		 "disposition": {"attached_pic": 1}}
+	],
+	"format": {"filename": "in.mp4", "nb_streams": 3, "format_name": "mov,mp4,m4a,3gp,3g2,mj2",
+	           "duration": "12.500000", "bit_rate": "5000000"}
+}`
+
+func TestParseProbe(t *testing.T) {
+	probe, err := ParseProbe([]byte(sampleProbe))
+	if err != nil {
+		t.Fatalf("ParseProbe() error: %v", err)
+	}
+
+	if probe.Duration() != 12500*time.Millisecond {
+		t.Fatalf("duration mismatch: got %v", probe.Duration())
+	}
+
+	video, ok := probe.VideoStream()
+	if !ok || video.Index != 0 {
+		t.Fatalf("expected video stream 0, got %+v (ok=%v)", video, ok)
+	}
+	if fps := video.FrameRate(); fps < 59.93 || fps > 59.95 {
+		t.Fatalf("frame rate mismatch: got %v", fps)
+	}
+	if len(probe.AudioStreams()) != 1 {
+		t.Fatalf("expected 1 audio stream, got %d", len(probe.AudioStreams()))
+	}
+}
+
+func TestProbeStream_DisplayAspectRatio(t *testing.T) {
+	s := ProbeStream{Width: 720, Height: 576, SampleAspectRatio: "64:45"}
+	if got := s.DisplayAspectRatio(); got < 1.777 || got > 1.778 {
+		t.Fatalf("DisplayAspectRatio() = %v, want 16/9", got)
+	}
+	if got := (ProbeStream{SampleAspectRatio: "0:1"}).SAR(); got != 1 {
+		t.Fatalf("SAR() for unknown ratio = %v, want 1", got)
+	}
+}
+
+func TestProbeStream_DisplaySizeRotated(t *testing.T) {
+	s := ProbeStream{Width: 1920, Height: 1080, SideDataList: []ProbeSideData{{Rotation: -90}}}
+	w, h := s.DisplaySize()
+	if w != 1080 || h != 1920 {
+		t.Fatalf("display size mismatch: got %dx%d", w, h)
+	}
+}
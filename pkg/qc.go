@@ -0,0 +1,363 @@
+package ffmpego
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interval is a detected time range, e.g. a black or silent section.
+type Interval struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Duration returns the interval length.
+func (i Interval) Duration() time.Duration {
+	return i.End - i.Start
+}
+
+// BlackDetectFilter renders: "[input]blackdetect=d=2:pix_th=0.1[output]"
+type BlackDetectFilter struct {
+	Input          string
+	Output         string
+	MinDuration    time.Duration
+	PixelThreshold float64 // luminance threshold under which a pixel is black (0..1)
+}
+
+func (f BlackDetectFilter) Validate() error {
+	if err := validateDetectLabels("blackdetect", f.Input, f.Output, f.MinDuration); err != nil {
+		return err
+	}
+	if f.PixelThreshold < 0 || f.PixelThreshold > 1 {
+		return fmt.Errorf("blackdetect: pixel threshold must be between 0 and 1, got %v", f.PixelThreshold)
+	}
+	return nil
+}
+
+func (f BlackDetectFilter) Parse() string {
+	return fmt.Sprintf("[%s]blackdetect=d=%s:pix_th=%s[%s]",
+		f.Input, formatSeconds(f.MinDuration), formatFloat(f.PixelThreshold), f.Output)
+}
+
+// SilenceDetectFilter renders: "[input]silencedetect=n=-60dB:d=2[output]"
+type SilenceDetectFilter struct {
+	Input       string
+	Output      string
+	MinDuration time.Duration
+	NoiseDB     float64 // level under which audio counts as silence
+}
+
+func (f SilenceDetectFilter) Validate() error {
+	if err := validateDetectLabels("silencedetect", f.Input, f.Output, f.MinDuration); err != nil {
+		return err
+	}
+	if f.NoiseDB >= 0 {
+		return fmt.Errorf("silencedetect: noise level must be negative dB, got %v", f.NoiseDB)
+	}
+	return nil
+}
+
+func (f SilenceDetectFilter) Parse() string {
+	return fmt.Sprintf("[%s]silencedetect=n=%sdB:d=%s[%s]",
+		f.Input, formatFloat(f.NoiseDB), formatSeconds(f.MinDuration), f.Output)
+}
+
+// FreezeDetectFilter renders: "[input]freezedetect=n=-60dB:d=2[output]"
+type FreezeDetectFilter struct {
+	Input       string
+	Output      string
+	MinDuration time.Duration
+	NoiseDB     float64 // frame difference under which frames count as frozen
+}
+
+func (f FreezeDetectFilter) Validate() error {
+	if err := validateDetectLabels("freezedetect", f.Input, f.Output, f.MinDuration); err != nil {
+		return err
+	}
+	if f.NoiseDB >= 0 {
+		return fmt.Errorf("freezedetect: noise level must be negative dB, got %v", f.NoiseDB)
+	}
+	return nil
+}
+
+func (f FreezeDetectFilter) Parse() string {
+	return fmt.Sprintf("[%s]freezedetect=n=%sdB:d=%s[%s]",
+		f.Input, formatFloat(f.NoiseDB), formatSeconds(f.MinDuration), f.Output)
+}
+
+// WithBlackDetect adds a labeled blackdetect chain.
+func WithBlackDetect(input string, output string, minDuration time.Duration, pixelThreshold float64) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(BlackDetectFilter{
+			Input:          strings.TrimSpace(input),
+			Output:         strings.TrimSpace(output),
+			MinDuration:    minDuration,
+			PixelThreshold: pixelThreshold,
+		})
+	}
+}
+
+// WithSilenceDetect adds a labeled silencedetect chain.
+func WithSilenceDetect(input string, output string, minDuration time.Duration, noiseDB float64) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(SilenceDetectFilter{
+			Input:       strings.TrimSpace(input),
+			Output:      strings.TrimSpace(output),
+			MinDuration: minDuration,
+			NoiseDB:     noiseDB,
+		})
+	}
+}
+
+// WithFreezeDetect adds a labeled freezedetect chain.
+func WithFreezeDetect(input string, output string, minDuration time.Duration, noiseDB float64) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(FreezeDetectFilter{
+			Input:       strings.TrimSpace(input),
+			Output:      strings.TrimSpace(output),
+			MinDuration: minDuration,
+			NoiseDB:     noiseDB,
+		})
+	}
+}
+
+func validateDetectLabels(name, input, output string, minDuration time.Duration) error {
+	if strings.TrimSpace(input) == "" {
+		return fmt.Errorf("%s: input label cannot be empty", name)
+	}
+	if strings.TrimSpace(output) == "" {
+		return fmt.Errorf("%s: output label cannot be empty", name)
+	}
+	if minDuration <= 0 {
+		return fmt.Errorf("%s: minimum duration must be positive, got %s", name, minDuration)
+	}
+	return nil
+}
+
+// BlackDetect configures the blackdetect pass of AnalyzeQC.
+type BlackDetect struct {
+	MinDuration    time.Duration
+	PixelThreshold float64
+}
+
+// SilenceDetect configures the silencedetect pass of AnalyzeQC.
+type SilenceDetect struct {
+	MinDuration time.Duration
+	NoiseDB     float64
+}
+
+// FreezeDetect configures the freezedetect pass of AnalyzeQC.
+type FreezeDetect struct {
+	MinDuration time.Duration
+	NoiseDB     float64
+}
+
+// QCOptions selects the detectors run by AnalyzeQC; nil detectors are skipped.
+type QCOptions struct {
+	Black       *BlackDetect
+	Silence     *SilenceDetect
+	Freeze      *FreezeDetect
+	VideoStream string // default "0:v:0"
+	AudioStream string // default "0:a:0"
+}
+
+// DefaultQCOptions enables all detectors with 2s minimum durations.
+func DefaultQCOptions() QCOptions {
+	return QCOptions{
+		Black:   &BlackDetect{MinDuration: 2 * time.Second, PixelThreshold: 0.1},
+		Silence: &SilenceDetect{MinDuration: 2 * time.Second, NoiseDB: -60},
+		Freeze:  &FreezeDetect{MinDuration: 2 * time.Second, NoiseDB: -60},
+	}
+}
+
+// QCEvent is a detected interval tagged with its detector ("black", "silence" or "freeze").
+type QCEvent struct {
+	Kind string
+	Interval
+}
+
+// QCReport holds the intervals found by each detector.
+// Intervals still open at the end of the input are closed at Duration.
+type QCReport struct {
+	Duration time.Duration
+	Black    []Interval
+	Silence  []Interval
+	Freeze   []Interval
+}
+
+// Events merges every detector's intervals ordered by start time.
+func (r *QCReport) Events() []QCEvent {
+	var events []QCEvent
+	for _, group := range []struct {
+		kind      string
+		intervals []Interval
+	}{{"black", r.Black}, {"silence", r.Silence}, {"freeze", r.Freeze}} {
+		for _, interval := range group.intervals {
+			events = append(events, QCEvent{Kind: group.kind, Interval: interval})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Start < events[j].Start })
+	return events
+}
+
+// Longest returns the longest event of kind, if any.
+func (r *QCReport) Longest(kind string) (QCEvent, bool) {
+	var longest QCEvent
+	found := false
+	for _, event := range r.Events() {
+		if event.Kind == kind && (!found || event.Duration() > longest.Duration()) {
+			longest, found = event, true
+		}
+	}
+	return longest, found
+}
+
+// QCCommand builds a single pass running every enabled detector into the null muxer.
+func QCCommand(input string, opts QCOptions) (*Ffmpego, error) {
+	if opts.Black == nil && opts.Silence == nil && opts.Freeze == nil {
+		return nil, fmt.Errorf("qc: at least one detector must be enabled")
+	}
+	video, audio := opts.VideoStream, opts.AudioStream
+	if video == "" {
+		video = "0:v:0"
+	}
+	if audio == "" {
+		audio = "0:a:0"
+	}
+
+	graph := NewComplexFilterBuilder()
+	var maps []string
+	if opts.Black != nil || opts.Freeze != nil {
+		last := video
+		if opts.Black != nil {
+			graph.Add(WithBlackDetect(last, "qc_black", opts.Black.MinDuration, opts.Black.PixelThreshold))
+			last = "qc_black"
+		}
+		if opts.Freeze != nil {
+			graph.Add(WithFreezeDetect(last, "qc_freeze", opts.Freeze.MinDuration, opts.Freeze.NoiseDB))
+			last = "qc_freeze"
+		}
+		maps = append(maps, "["+last+"]")
+	}
+	if opts.Silence != nil {
+		graph.Add(WithSilenceDetect(audio, "qc_silence", opts.Silence.MinDuration, opts.Silence.NoiseDB))
+		maps = append(maps, "[qc_silence]")
+	}
+
+	return New("").
+		WithOptions(NewFfmpegOptions(WithInput(input))).
+		WithFilterGraph(graph.Build()).
+		Output(NewNullOutput(maps...)), nil
+}
+
+// forProbe drops the detectors whose default stream the probed input lacks, e.g. silence
+// detection on a video-only file. Explicit VideoStream/AudioStream selections are kept.
+func (o QCOptions) forProbe(probe *ProbeResult) QCOptions {
+	if _, ok := probe.VideoStream(); !ok && o.VideoStream == "" {
+		o.Black, o.Freeze = nil, nil
+	}
+	if len(probe.AudioStreams()) == 0 && o.AudioStream == "" {
+		o.Silence = nil
+	}
+	return o
+}
+
+// AnalyzeQC runs the enabled detectors in a single ffmpeg pass and returns the report.
+// The input is probed first, so detectors for a stream type it lacks are skipped.
+func AnalyzeQC(ctx context.Context, input string, opts QCOptions) (*QCReport, error) {
+	probe, err := NewProber("").Probe(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	applicable := opts.forProbe(probe)
+	if applicable.Black == nil && applicable.Silence == nil && applicable.Freeze == nil {
+		return nil, fmt.Errorf("qc: %q has no stream for the enabled detectors", input)
+	}
+
+	cmd, err := QCCommand(input, applicable)
+	if err != nil {
+		return nil, err
+	}
+
+	stderr, err := NewRunner(cmd).Capture(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseQCReport(stderr), nil
+}
+
+var (
+	inputDurationRe = regexp.MustCompile(`Duration: (\d+:\d+:\d+(?:\.\d+)?)`)
+	blackRe         = regexp.MustCompile(`black_start:\s*(-?\d+(?:\.\d+)?)\s+black_end:\s*(\d+(?:\.\d+)?)`)
+	silenceStartRe  = regexp.MustCompile(`silence_start:\s*(-?\d+(?:\.\d+)?)`)
+	silenceEndRe    = regexp.MustCompile(`silence_end:\s*(\d+(?:\.\d+)?)`)
+	freezeStartRe   = regexp.MustCompile(`freeze_start:\s*(\d+(?:\.\d+)?)`)
+	freezeEndRe     = regexp.MustCompile(`freeze_end:\s*(\d+(?:\.\d+)?)`)
+)
+
+// ParseQCReport parses blackdetect, silencedetect and freezedetect log lines.
+func ParseQCReport(stderr []byte) *QCReport {
+	report := &QCReport{}
+	if m := inputDurationRe.FindSubmatch(stderr); m != nil {
+		report.Duration, _ = ParseTimestamp(string(m[1]))
+	}
+
+	var silenceStart, freezeStart *time.Duration
+	scanner := bufio.NewScanner(bytes.NewReader(stderr))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.Contains(line, "black_start"):
+			if m := blackRe.FindStringSubmatch(line); m != nil {
+				report.Black = append(report.Black, Interval{Start: parseLogSeconds(m[1]), End: parseLogSeconds(m[2])})
+			}
+		case strings.Contains(line, "silence_start"):
+			if m := silenceStartRe.FindStringSubmatch(line); m != nil {
+				start := parseLogSeconds(m[1])
+				silenceStart = &start
+			}
+		case strings.Contains(line, "silence_end"):
+			if m := silenceEndRe.FindStringSubmatch(line); m != nil && silenceStart != nil {
+				report.Silence = append(report.Silence, Interval{Start: *silenceStart, End: parseLogSeconds(m[1])})
+				silenceStart = nil
+			}
+		case strings.Contains(line, "freeze_start"):
+			if m := freezeStartRe.FindStringSubmatch(line); m != nil {
+				start := parseLogSeconds(m[1])
+				freezeStart = &start
+			}
+		case strings.Contains(line, "freeze_end"):
+			if m := freezeEndRe.FindStringSubmatch(line); m != nil && freezeStart != nil {
+				report.Freeze = append(report.Freeze, Interval{Start: *freezeStart, End: parseLogSeconds(m[1])})
+				freezeStart = nil
+			}
+		}
+	}
+
+	// silence and freeze that last until EOF are never closed in the log
+	if silenceStart != nil && report.Duration > *silenceStart {
+		report.Silence = append(report.Silence, Interval{Start: *silenceStart, End: report.Duration})
+	}
+	if freezeStart != nil && report.Duration > *freezeStart {
+		report.Freeze = append(report.Freeze, Interval{Start: *freezeStart, End: report.Duration})
+	}
+
+	return report
+}
+
+// parseLogSeconds parses decimal seconds from a filter log line, clamping negatives to 0.
+func parseLogSeconds(value string) time.Duration {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second)).Round(time.Microsecond)
+}
@@ -0,0 +1,89 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleQCStderr = `Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'in.mp4':
+  Duration: 00:00:30.00, start: 0.000000, bitrate: 1205 kb/s
+[blackdetect @ 0x5581d1f0] black_start:0 black_end:2.5 black_duration:2.5
+[silencedetect @ 0x5581d2a0] silence_start: -0.0213
+[silencedetect @ 0x5581d2a0] silence_end: 3.2 | silence_duration: 3.2213
+[freezedetect @ 0x5581d350] lavfi.freezedetect.freeze_start: 10.01
+[freezedetect @ 0x5581d350] lavfi.freezedetect.freeze_duration: 4.004
+[freezedetect @ 0x5581d350] lavfi.freezedetect.freeze_end: 14.014
+[silencedetect @ 0x5581d2a0] silence_start: 25
+`
+
+func TestParseQCReport(t *testing.T) {
+	report := ParseQCReport([]byte(sampleQCStderr))
+
+	if report.Duration != 30*time.Second {
+		t.Fatalf("duration mismatch: got %v", report.Duration)
+	}
+	if len(report.Black) != 1 || report.Black[0] != (Interval{Start: 0, End: 2500 * time.Millisecond}) {
+		t.Fatalf("black mismatch: %+v", report.Black)
+	}
+	wantSilence := []Interval{{Start: 0, End: 3200 * time.Millisecond}, {Start: 25 * time.Second, End: 30 * time.Second}}
+	if len(report.Silence) != 2 || report.Silence[0] != wantSilence[0] || report.Silence[1] != wantSilence[1] {
+		t.Fatalf("silence mismatch: %+v", report.Silence)
+	}
+	if len(report.Freeze) != 1 || report.Freeze[0].Duration() != 4004*time.Millisecond {
+		t.Fatalf("freeze mismatch: %+v", report.Freeze)
+	}
+
+	events := report.Events()
+	if len(events) != 4 || events[3].Kind != "silence" {
+		t.Fatalf("events should be ordered by start: %+v", events)
+	}
+	if longest, ok := report.Longest("silence"); !ok || longest.Start != 25*time.Second {
+		t.Fatalf("longest silence mismatch: %+v", longest)
+	}
+}
+
+func TestQCCommand(t *testing.T) {
+	cmd, err := QCCommand("in.mp4", DefaultQCOptions())
+	if err != nil {
+		t.Fatalf("QCCommand() error: %v", err)
+	}
+
+	args, err := cmd.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	got := strings.Join(args, " ")
+	want := "-i in.mp4 -filter_complex [0:v:0]blackdetect=d=2:pix_th=0.1[qc_black];" +
+		"[qc_black]freezedetect=n=-60dB:d=2[qc_freeze];[0:a:0]silencedetect=n=-60dB:d=2[qc_silence] " +
+		"-map [qc_freeze] -map [qc_silence] -f null -"
+	if got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+
+	if _, err := QCCommand("in.mp4", QCOptions{}); err == nil {
+		t.Fatalf("expected error without detectors, got nil")
+	}
+}
+
+func TestQCOptions_ForProbe(t *testing.T) {
+	videoOnly := &ProbeResult{Streams: []ProbeStream{{Index: 0, CodecType: "video"}}}
+	opts := DefaultQCOptions().forProbe(videoOnly)
+	if opts.Silence != nil || opts.Black == nil || opts.Freeze == nil {
+		t.Fatalf("video-only input should only drop silencedetect, got %+v", opts)
+	}
+
+	audioOnly := &ProbeResult{Streams: []ProbeStream{{Index: 0, CodecType: "audio"}}}
+	opts = DefaultQCOptions().forProbe(audioOnly)
+	if opts.Silence == nil || opts.Black != nil || opts.Freeze != nil {
+		t.Fatalf("audio-only input should only keep silencedetect, got %+v", opts)
+	}
+
+	// an explicit stream selection is the caller's choice
+	explicit := DefaultQCOptions()
+	explicit.AudioStream = "1:a:0"
+	if opts := explicit.forProbe(videoOnly); opts.Silence == nil {
+		t.Fatalf("explicit audio stream should keep silencedetect")
+	}
+}
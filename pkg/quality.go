@@ -0,0 +1,460 @@
+package ffmpego
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// QualityMetric identifies an objective quality metric.
+type QualityMetric string
+
+const (
+	MetricPSNR QualityMetric = "psnr"
+	MetricSSIM QualityMetric = "ssim"
+	// MetricVMAF requires an ffmpeg built with libvmaf.
+	MetricVMAF QualityMetric = "vmaf"
+)
+
+// filter returns the ffmpeg filter computing the metric.
+func (m QualityMetric) filter() string {
+	if m == MetricVMAF {
+		return "libvmaf"
+	}
+	return string(m)
+}
+
+// statsFile returns the per-frame stats file name used for the metric.
+func (m QualityMetric) statsFile() string {
+	if m == MetricVMAF {
+		return "vmaf.json"
+	}
+	return string(m) + ".log"
+}
+
+var (
+	psnrSummaryRe = regexp.MustCompile(`\[Parsed_psnr_\d+ @ [^\]]*\] PSNR .*average:(\S+) min:(\S+) max:(\S+)`)
+	ssimSummaryRe = regexp.MustCompile(`\[Parsed_ssim_\d+ @ [^\]]*\] SSIM .*All:(\S+)`)
+	vmafSummaryRe = regexp.MustCompile(`VMAF score: (\S+)`)
+	statsFieldRe  = regexp.MustCompile(`(\w+):(\S+)`)
+)
+
+// QualityMetricFilter renders: "[main][reference]psnr=stats_file=path[output]"
+// (ssim alike, libvmaf with "log_fmt=json:log_path=path"). Main is the distorted video.
+type QualityMetricFilter struct {
+	Main      string
+	Reference string
+	Output    string
+	Metric    QualityMetric
+	StatsFile string // optional per-frame stats file
+	Model     string // optional libvmaf model option, e.g. "version=vmaf_4k_v0.6.1"
+}
+
+func (f QualityMetricFilter) Validate() error {
+	switch f.Metric {
+	case MetricPSNR, MetricSSIM, MetricVMAF:
+	default:
+		return fmt.Errorf("quality: unknown metric %q", f.Metric)
+	}
+	if strings.TrimSpace(f.Main) == "" || strings.TrimSpace(f.Reference) == "" {
+		return fmt.Errorf("%s: main and reference labels cannot be empty", f.Metric)
+	}
+	if strings.TrimSpace(f.Output) == "" {
+		return fmt.Errorf("%s: output label cannot be empty", f.Metric)
+	}
+	if f.Model != "" && f.Metric != MetricVMAF {
+		return fmt.Errorf("%s: model only applies to vmaf", f.Metric)
+	}
+	return nil
+}
+
+func (f QualityMetricFilter) Parse() string {
+	var opts []string
+	if f.Metric == MetricVMAF {
+		if f.Model != "" {
+			opts = append(opts, "model="+f.Model)
+		}
+		if f.StatsFile != "" {
+			opts = append(opts, "log_fmt=json", "log_path="+escapeFilterValue(f.StatsFile))
+		}
+	} else if f.StatsFile != "" {
+		opts = append(opts, "stats_file="+escapeFilterValue(f.StatsFile))
+	}
+
+	expr := f.Metric.filter()
+	if len(opts) > 0 {
+		expr += "=" + strings.Join(opts, ":")
+	}
+	return fmt.Sprintf("[%s][%s]%s[%s]", f.Main, f.Reference, expr, f.Output)
+}
+
+// WithQualityMetric adds a labeled psnr, ssim or libvmaf chain comparing main against reference.
+func WithQualityMetric(main string, reference string, output string, metric QualityMetric, statsFile string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(QualityMetricFilter{
+			Main:      strings.TrimSpace(main),
+			Reference: strings.TrimSpace(reference),
+			Output:    strings.TrimSpace(output),
+			Metric:    metric,
+			StatsFile: statsFile,
+		})
+	}
+}
+
+// QualityScore holds the pooled values of a metric and, when stats files were
+// written, the per-frame series. PSNR is in dB, SSIM in 0..1, VMAF in 0..100.
+type QualityScore struct {
+	Metric       QualityMetric
+	Mean         float64
+	Min          float64
+	Max          float64
+	HarmonicMean float64
+	Frames       []float64
+}
+
+// QualityReport holds the scores of a comparison. Skipped lists requested metrics the
+// ffmpeg binary does not support.
+type QualityReport struct {
+	Scores  map[QualityMetric]*QualityScore
+	Skipped []QualityMetric
+}
+
+// Score returns the score of metric, if it was computed.
+func (r *QualityReport) Score(metric QualityMetric) (*QualityScore, bool) {
+	score, ok := r.Scores[metric]
+	return score, ok
+}
+
+// QualityComparison compares a distorted encode against its reference. The distorted
+// video is scaled to the reference resolution and both are rebased to start at zero:
+//
+//	[0:v:0]scale=1920:1080:flags=bicubic[q_scaled];[q_scaled]setpts=PTS-STARTPTS[q_dist];
+//	[1:v:0]setpts=PTS-STARTPTS[q_ref];[q_dist][q_ref]psnr=stats_file=psnr.log[q_psnr]
+type QualityComparison struct {
+	reference string
+	distorted string
+	metrics   []QualityMetric
+	width     int
+	height    int
+	statsDir  string
+	vmafModel string
+}
+
+// NewQualityComparison creates a comparison computing PSNR and SSIM, plus VMAF when supported.
+func NewQualityComparison(reference, distorted string) *QualityComparison {
+	return &QualityComparison{reference: reference, distorted: distorted}
+}
+
+// Metrics selects the metrics to compute.
+func (q *QualityComparison) Metrics(metrics ...QualityMetric) *QualityComparison {
+	q.metrics = metrics
+	return q
+}
+
+// Resolution sets the comparison resolution both inputs are scaled to; Run defaults it to
+// the reference resolution.
+func (q *QualityComparison) Resolution(width, height int) *QualityComparison {
+	q.width, q.height = width, height
+	return q
+}
+
+// StatsDir writes and keeps the per-frame stats files in dir. Without it Run uses a
+// temporary directory removed once the series are parsed.
+func (q *QualityComparison) StatsDir(dir string) *QualityComparison {
+	q.statsDir = dir
+	return q
+}
+
+// VMAFModel sets the libvmaf model option, e.g. "version=vmaf_4k_v0.6.1".
+func (q *QualityComparison) VMAFModel(model string) *QualityComparison {
+	q.vmafModel = model
+	return q
+}
+
+// Command builds the comparison pass into the null muxer. Stats files are written
+// to statsDir when it is not empty.
+func (q *QualityComparison) Command(statsDir string) (*Ffmpego, error) {
+	if strings.TrimSpace(q.reference) == "" || strings.TrimSpace(q.distorted) == "" {
+		return nil, fmt.Errorf("quality: reference and distorted inputs cannot be empty")
+	}
+	if q.width <= 0 || q.height <= 0 {
+		return nil, fmt.Errorf("quality: resolution must be positive, got %dx%d", q.width, q.height)
+	}
+	metrics := q.metrics
+	if len(metrics) == 0 {
+		metrics = []QualityMetric{MetricPSNR, MetricSSIM}
+	}
+
+	graph := NewComplexFilterBuilder().
+		Add(WithScaleFlags("0:v:0", "q_scaled", q.width, q.height, "bicubic")).
+		Chain("q_scaled", "setpts=PTS-STARTPTS", "q_dist").
+		Add(WithScaleFlags("1:v:0", "q_ref_scaled", q.width, q.height, "bicubic")).
+		Chain("q_ref_scaled", "setpts=PTS-STARTPTS", "q_ref")
+
+	dist := []string{"q_dist"}
+	ref := []string{"q_ref"}
+	if len(metrics) > 1 {
+		dist, ref = make([]string, len(metrics)), make([]string, len(metrics))
+		for i := range metrics {
+			dist[i], ref[i] = fmt.Sprintf("q_dist%d", i), fmt.Sprintf("q_ref%d", i)
+		}
+		graph.Add(WithSplit("q_dist", len(metrics), dist...)).
+			Add(WithSplit("q_ref", len(metrics), ref...))
+	}
+
+	maps := make([]string, len(metrics))
+	for i, metric := range metrics {
+		filter := QualityMetricFilter{Main: dist[i], Reference: ref[i], Output: "q_" + string(metric), Metric: metric}
+		if statsDir != "" {
+			filter.StatsFile = filepath.Join(statsDir, metric.statsFile())
+		}
+		if metric == MetricVMAF {
+			filter.Model = q.vmafModel
+		}
+		graph.Add(withFilter(filter))
+		maps[i] = "[" + filter.Output + "]"
+	}
+
+	return New("").
+		WithOptions(NewFfmpegOptions(WithInput(q.distorted), WithInput(q.reference))).
+		WithFilterGraph(graph.Build()).
+		Output(NewNullOutput(maps...)), nil
+}
+
+// Run computes the metrics. VMAF is skipped (and listed in the report) when the
+// binary lacks libvmaf; an explicitly requested VMAF is reported the same way.
+func (q *QualityComparison) Run(ctx context.Context) (*QualityReport, error) {
+	report := &QualityReport{Scores: make(map[QualityMetric]*QualityScore)}
+
+	caps, err := DetectCapabilities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	run := *q
+	requested := q.metrics
+	if len(requested) == 0 {
+		requested = []QualityMetric{MetricPSNR, MetricSSIM, MetricVMAF}
+	}
+	run.metrics = nil
+	for _, metric := range requested {
+		if metric == MetricVMAF && !caps.HasFilter("libvmaf") {
+			report.Skipped = append(report.Skipped, metric)
+			continue
+		}
+		run.metrics = append(run.metrics, metric)
+	}
+	if len(run.metrics) == 0 {
+		return report, nil
+	}
+
+	if run.width == 0 || run.height == 0 {
+		probe, err := Probe(ctx, q.reference)
+		if err != nil {
+			return nil, err
+		}
+		video, ok := probe.VideoStream()
+		if !ok {
+			return nil, fmt.Errorf("quality: reference %q has no video stream", q.reference)
+		}
+		run.width, run.height = video.DisplaySize()
+	}
+
+	dir := q.statsDir
+	if dir == "" {
+		dir, err = os.MkdirTemp("", "ffmpego-quality-")
+		if err != nil {
+			return nil, fmt.Errorf("quality: failed to create stats directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+	}
+
+	cmd, err := run.Command(dir)
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := NewRunner(cmd).Capture(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for metric, score := range ParseQualitySummary(stderr) {
+		report.Scores[metric] = score
+	}
+	for _, metric := range run.metrics {
+		if err := readQualityStats(report, metric, filepath.Join(dir, metric.statsFile())); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// CompareQuality compares distorted against reference at the reference resolution.
+// Without metrics it computes PSNR, SSIM and, when the binary supports it, VMAF.
+func CompareQuality(ctx context.Context, reference, distorted string, metrics ...QualityMetric) (*QualityReport, error) {
+	return NewQualityComparison(reference, distorted).Metrics(metrics...).Run(ctx)
+}
+
+// ParseQualitySummary extracts the pooled scores psnr, ssim and libvmaf log at the end of a run.
+func ParseQualitySummary(stderr []byte) map[QualityMetric]*QualityScore {
+	scores := make(map[QualityMetric]*QualityScore)
+	scanner := bufio.NewScanner(bytes.NewReader(stderr))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := psnrSummaryRe.FindStringSubmatch(line); m != nil {
+			scores[MetricPSNR] = &QualityScore{
+				Metric: MetricPSNR,
+				Mean:   parseMetricValue(m[1]),
+				Min:    parseMetricValue(m[2]),
+				Max:    parseMetricValue(m[3]),
+			}
+		} else if m := ssimSummaryRe.FindStringSubmatch(line); m != nil {
+			scores[MetricSSIM] = &QualityScore{Metric: MetricSSIM, Mean: parseMetricValue(m[1])}
+		} else if m := vmafSummaryRe.FindStringSubmatch(line); m != nil {
+			scores[MetricVMAF] = &QualityScore{Metric: MetricVMAF, Mean: parseMetricValue(m[1])}
+		}
+	}
+	return scores
+}
+
+// ParsePSNRStats reads the per-frame "psnr_avg" series from a psnr stats_file.
+func ParsePSNRStats(r io.Reader) ([]float64, error) {
+	return parseStatsSeries(r, "psnr_avg")
+}
+
+// ParseSSIMStats reads the per-frame "All" series from an ssim stats_file.
+func ParseSSIMStats(r io.Reader) ([]float64, error) {
+	return parseStatsSeries(r, "All")
+}
+
+// ParseVMAFLog decodes a libvmaf JSON log into its pooled score and per-frame series.
+func ParseVMAFLog(data []byte) (*QualityScore, error) {
+	var log struct {
+		Frames []struct {
+			Metrics map[string]float64 `json:"metrics"`
+		} `json:"frames"`
+		Pooled map[string]struct {
+			Min          float64 `json:"min"`
+			Max          float64 `json:"max"`
+			Mean         float64 `json:"mean"`
+			HarmonicMean float64 `json:"harmonic_mean"`
+		} `json:"pooled_metrics"`
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, fmt.Errorf("vmaf: failed to decode log: %w", err)
+	}
+
+	pooled, ok := log.Pooled["vmaf"]
+	if !ok {
+		return nil, fmt.Errorf("vmaf: log has no pooled vmaf score")
+	}
+	score := &QualityScore{
+		Metric:       MetricVMAF,
+		Mean:         pooled.Mean,
+		Min:          pooled.Min,
+		Max:          pooled.Max,
+		HarmonicMean: pooled.HarmonicMean,
+		Frames:       make([]float64, 0, len(log.Frames)),
+	}
+	for _, frame := range log.Frames {
+		score.Frames = append(score.Frames, frame.Metrics["vmaf"])
+	}
+	return score, nil
+}
+
+// readQualityStats merges the per-frame series of metric into the report, filling the
+// pooled values the summary line does not provide.
+func readQualityStats(report *QualityReport, metric QualityMetric, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%s: failed to read stats file: %w", metric, err)
+	}
+
+	if metric == MetricVMAF {
+		score, err := ParseVMAFLog(data)
+		if err != nil {
+			return err
+		}
+		report.Scores[metric] = score
+		return nil
+	}
+
+	var frames []float64
+	if metric == MetricPSNR {
+		frames, err = ParsePSNRStats(bytes.NewReader(data))
+	} else {
+		frames, err = ParseSSIMStats(bytes.NewReader(data))
+	}
+	if err != nil {
+		return err
+	}
+
+	score, ok := report.Scores[metric]
+	if !ok {
+		score = &QualityScore{Metric: metric}
+		report.Scores[metric] = score
+	}
+	score.Frames = frames
+	score.fillFromFrames()
+	return nil
+}
+
+// fillFromFrames computes the min, max and harmonic mean of the per-frame series,
+// and the mean when the summary line was missing.
+func (s *QualityScore) fillFromFrames() {
+	if len(s.Frames) == 0 {
+		return
+	}
+
+	sum, inverse := 0.0, 0.0
+	s.Min, s.Max = s.Frames[0], s.Frames[0]
+	for _, v := range s.Frames {
+		sum += v
+		inverse += 1 / v
+		s.Min = math.Min(s.Min, v)
+		s.Max = math.Max(s.Max, v)
+	}
+	if s.Mean == 0 {
+		s.Mean = sum / float64(len(s.Frames))
+	}
+	if inverse > 0 {
+		s.HarmonicMean = float64(len(s.Frames)) / inverse
+	}
+}
+
+func parseStatsSeries(r io.Reader, key string) ([]float64, error) {
+	var series []float64
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		found := false
+		for _, m := range statsFieldRe.FindAllStringSubmatch(scanner.Text(), -1) {
+			if m[1] == key {
+				series = append(series, parseMetricValue(m[2]))
+				found = true
+				break
+			}
+		}
+		if !found && strings.TrimSpace(scanner.Text()) != "" {
+			return nil, fmt.Errorf("quality: stats line without %s: %q", key, scanner.Text())
+		}
+	}
+	return series, scanner.Err()
+}
+
+// parseMetricValue parses a logged score; identical frames log PSNR as "inf".
+func parseMetricValue(value string) float64 {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return v
+}
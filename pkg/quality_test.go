@@ -0,0 +1,101 @@
+package ffmpego
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+const sampleQualityStderr = `[Parsed_psnr_5 @ 0x55e0] PSNR y:38.212 u:43.120 v:43.904 average:39.381 min:31.027 max:47.811
+[Parsed_ssim_6 @ 0x55e1] SSIM Y:0.965123 (14.571) U:0.982211 (17.502) V:0.984012 (17.969) All:0.972012 (15.529)
+[libvmaf @ 0x55e2] VMAF score: 91.402113
+`
+
+func TestParseQualitySummary(t *testing.T) {
+	scores := ParseQualitySummary([]byte(sampleQualityStderr))
+
+	psnr := scores[MetricPSNR]
+	if psnr == nil || psnr.Mean != 39.381 || psnr.Min != 31.027 || psnr.Max != 47.811 {
+		t.Fatalf("psnr mismatch: %+v", psnr)
+	}
+	if ssim := scores[MetricSSIM]; ssim == nil || ssim.Mean != 0.972012 {
+		t.Fatalf("ssim mismatch: %+v", ssim)
+	}
+	if vmaf := scores[MetricVMAF]; vmaf == nil || vmaf.Mean != 91.402113 {
+		t.Fatalf("vmaf mismatch: %+v", vmaf)
+	}
+}
+
+func TestParseQualityStats(t *testing.T) {
+	psnr, err := ParsePSNRStats(strings.NewReader(
+		"n:1 mse_avg:0.52 mse_y:0.61 mse_u:0.30 mse_v:0.33 psnr_avg:50.97 psnr_y:50.27 psnr_u:53.36 psnr_v:52.94\n" +
+			"n:2 mse_avg:0.00 mse_y:0.00 mse_u:0.00 mse_v:0.00 psnr_avg:inf psnr_y:inf psnr_u:inf psnr_v:inf\n"))
+	if err != nil {
+		t.Fatalf("ParsePSNRStats() error: %v", err)
+	}
+	if len(psnr) != 2 || psnr[0] != 50.97 || !math.IsInf(psnr[1], 1) {
+		t.Fatalf("psnr series mismatch: %v", psnr)
+	}
+
+	ssim, err := ParseSSIMStats(strings.NewReader("n:1 Y:0.99 U:0.98 V:0.98 All:0.8 (12.3)\nn:2 Y:0.5 U:0.5 V:0.5 All:0.4 (2.1)\n"))
+	if err != nil {
+		t.Fatalf("ParseSSIMStats() error: %v", err)
+	}
+	score := &QualityScore{Metric: MetricSSIM, Frames: ssim}
+	score.fillFromFrames()
+	if math.Abs(score.Mean-0.6) > 1e-9 || score.Min != 0.4 || score.Max != 0.8 || math.Abs(score.HarmonicMean-0.8/1.5) > 1e-9 {
+		t.Fatalf("ssim pooling mismatch: %+v", score)
+	}
+}
+
+func TestParseVMAFLog(t *testing.T) {
+	score, err := ParseVMAFLog([]byte(`{
+		"frames": [{"frameNum": 0, "metrics": {"vmaf": 90.5}}, {"frameNum": 1, "metrics": {"vmaf": 95.5}}],
+		"pooled_metrics": {"vmaf": {"min": 90.5, "max": 95.5, "mean": 93.0, "harmonic_mean": 92.9}}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseVMAFLog() error: %v", err)
+	}
+	if score.Mean != 93 || score.HarmonicMean != 92.9 || len(score.Frames) != 2 || score.Frames[1] != 95.5 {
+		t.Fatalf("vmaf mismatch: %+v", score)
+	}
+}
+
+func TestQualityComparisonCommand(t *testing.T) {
+	cmd, err := NewQualityComparison("ref.mp4", "enc.mp4").
+		Metrics(MetricPSNR, MetricVMAF).
+		Resolution(1920, 1080).
+		Command("/tmp/stats")
+	if err != nil {
+		t.Fatalf("Command() error: %v", err)
+	}
+	args, err := cmd.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	got := strings.Join(args, " ")
+	want := "-i enc.mp4 -i ref.mp4 -filter_complex [0:v:0]scale=1920:1080:flags=bicubic[q_scaled];" +
+		"[q_scaled]setpts=PTS-STARTPTS[q_dist];[1:v:0]scale=1920:1080:flags=bicubic[q_ref_scaled];" +
+		"[q_ref_scaled]setpts=PTS-STARTPTS[q_ref];" +
+		"[q_dist]split=2[q_dist0][q_dist1];[q_ref]split=2[q_ref0][q_ref1];" +
+		"[q_dist0][q_ref0]psnr=stats_file=/tmp/stats/psnr.log[q_psnr];" +
+		"[q_dist1][q_ref1]libvmaf=log_fmt=json:log_path=/tmp/stats/vmaf.json[q_vmaf] " +
+		"-map [q_psnr] -map [q_vmaf] -f null -"
+	if got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+
+	// Windows temp dirs need escaping rather than rejection
+	filter := QualityMetricFilter{Main: "a", Reference: "b", Output: "c", Metric: MetricPSNR, StatsFile: `C:\Temp\psnr.log`}
+	if err := filter.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	if got, want := filter.Parse(), `[a][b]psnr=stats_file=C\\:\\\\Temp\\\\psnr.log[c]`; got != want {
+		t.Fatalf("Parse() = %s, want %s", got, want)
+	}
+
+	if _, err := NewQualityComparison("ref.mp4", "enc.mp4").Command(""); err == nil {
+		t.Fatalf("expected error without resolution, got nil")
+	}
+}
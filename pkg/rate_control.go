@@ -0,0 +1,408 @@
+package ffmpego
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// RateControlMode selects how the video encoder spends bits.
+type RateControlMode int
+
+const (
+	// RateCRF is constant quality (-crf), with no bitrate limit.
+	RateCRF RateControlMode = iota
+	// RateConstrainedCRF is constant quality capped by a VBV maxrate/bufsize.
+	RateConstrainedCRF
+	// RateABR targets an average bitrate, optionally capped by maxrate/bufsize.
+	RateABR
+	// RateCBR is constant bitrate with HRD signalling where the encoder supports it.
+	RateCBR
+	// RateCQP is a constant quantizer (-qp), mostly for testing and intermediates.
+	RateCQP
+)
+
+func (m RateControlMode) String() string {
+	switch m {
+	case RateCRF:
+		return "crf"
+	case RateConstrainedCRF:
+		return "constrained crf"
+	case RateABR:
+		return "abr"
+	case RateCBR:
+		return "cbr"
+	case RateCQP:
+		return "cqp"
+	default:
+		return fmt.Sprintf("RateControlMode(%d)", int(m))
+	}
+}
+
+// encoderFamily groups video encoders sharing rate control and option syntax.
+type encoderFamily int
+
+const (
+	familyGeneric encoderFamily = iota
+	familyX264
+	familyX265
+	familyVPX
+	familyAOM
+	familySVTAV1
+)
+
+func videoEncoderFamily(encoder string) encoderFamily {
+	switch encoder {
+	case "libx264", "libx264rgb":
+		return familyX264
+	case "libx265":
+		return familyX265
+	case "libvpx", "libvpx-vp9":
+		return familyVPX
+	case "libaom-av1":
+		return familyAOM
+	case "libsvtav1":
+		return familySVTAV1
+	default:
+		return familyGeneric
+	}
+}
+
+// encoderOption is implemented by output flags whose rendering depends on the video
+// encoder. OutputDescriptor.Build hands them the encoder of the output's VideoCodec flag.
+type encoderOption interface {
+	OutputFlagParser
+	forEncoder(encoder string) (OutputFlagParser, error)
+}
+
+// RateControl renders the video rate control flags for an encoder. Bitrates use ffmpeg
+// syntax ("2.5M", "800k", "3000000"). Build one with ConstantQuality, ConstrainedQuality,
+// AverageBitrate, ConstantBitrate or ConstantQP; Encoder is filled from the output's
+// VideoCodec when left empty.
+//
+//	libx264 CBR:  -b:v 3M -minrate 3M -maxrate 3M -bufsize 6M -x264-params nal-hrd=cbr
+//	libx265 CRF+VBV: -crf 24 -x265-params vbv-maxrate=4000:vbv-bufsize=8000
+//	libvpx-vp9 CRF: -crf 31 -b:v 0
+type RateControl struct {
+	Encoder string
+	Mode    RateControlMode
+	CRF     int
+	QP      int
+	Bitrate string // ABR/CBR target
+	MaxRate string // VBV peak; requires BufSize
+	MinRate string
+	BufSize string
+	CPUUsed int // libvpx/libaom speed (-cpu-used); 0 keeps the encoder default
+}
+
+// ConstantQuality returns a CRF rate control.
+func ConstantQuality(crf int) RateControl {
+	return RateControl{Mode: RateCRF, CRF: crf}
+}
+
+// ConstrainedQuality returns a CRF rate control capped by a VBV maxrate and bufsize.
+func ConstrainedQuality(crf int, maxRate, bufSize string) RateControl {
+	return RateControl{Mode: RateConstrainedCRF, CRF: crf, MaxRate: maxRate, BufSize: bufSize}
+}
+
+// AverageBitrate returns an ABR rate control; set MaxRate and BufSize to cap it.
+func AverageBitrate(bitrate string) RateControl {
+	return RateControl{Mode: RateABR, Bitrate: bitrate}
+}
+
+// ConstantBitrate returns a CBR rate control with the given VBV buffer size.
+func ConstantBitrate(bitrate, bufSize string) RateControl {
+	return RateControl{Mode: RateCBR, Bitrate: bitrate, BufSize: bufSize}
+}
+
+// ConstantQP returns a constant quantizer rate control.
+func ConstantQP(qp int) RateControl {
+	return RateControl{Mode: RateCQP, QP: qp}
+}
+
+// For returns a copy of the rate control rendering for encoder, e.g. "libx265".
+func (rc RateControl) For(encoder string) RateControl {
+	rc.Encoder = encoder
+	return rc
+}
+
+// Capped returns a copy of an ABR rate control limited by maxRate and bufSize.
+func (rc RateControl) Capped(maxRate, bufSize string) RateControl {
+	rc.MaxRate = maxRate
+	rc.BufSize = bufSize
+	return rc
+}
+
+func (rc RateControl) forEncoder(encoder string) (OutputFlagParser, error) {
+	if rc.Encoder != "" && encoder != "" && rc.Encoder != encoder {
+		return nil, fmt.Errorf("rate control is set up for %s but the video codec is %s", rc.Encoder, encoder)
+	}
+	if rc.Encoder == "" {
+		rc.Encoder = encoder
+	}
+	return rc, nil
+}
+
+// Validate validates the mode parameters and their consistency for the encoder
+func (rc RateControl) Validate() error {
+	family := videoEncoderFamily(rc.Encoder)
+
+	rates := map[string]int64{}
+	for _, rate := range [][2]string{{"bitrate", rc.Bitrate}, {"maxrate", rc.MaxRate}, {"minrate", rc.MinRate}, {"bufsize", rc.BufSize}} {
+		name, value := rate[0], rate[1]
+		if value == "" {
+			continue
+		}
+		bits, err := ParseBitrate(value)
+		if err != nil {
+			return fmt.Errorf("rate control: %s: %w", name, err)
+		}
+		if bits <= 0 {
+			return fmt.Errorf("rate control: %s must be positive, got %q", name, value)
+		}
+		rates[name] = bits
+	}
+	if rates["maxrate"] > 0 && rates["bufsize"] == 0 {
+		return fmt.Errorf("rate control: maxrate requires bufsize")
+	}
+
+	maxCRF := 63
+	if family == familyX264 || family == familyX265 {
+		maxCRF = 51
+	}
+
+	switch rc.Mode {
+	case RateCRF, RateConstrainedCRF:
+		if rc.CRF < 0 || rc.CRF > maxCRF {
+			return fmt.Errorf("rate control: CRF must be between 0 and %d for %s, got %d", maxCRF, rc.encoderName(), rc.CRF)
+		}
+		if rc.Bitrate != "" || rc.MinRate != "" {
+			return fmt.Errorf("rate control: %s does not take a target bitrate or minrate", rc.Mode)
+		}
+		if rc.Mode == RateCRF && (rc.MaxRate != "" || rc.BufSize != "") {
+			return fmt.Errorf("rate control: use constrained crf to cap a crf encode")
+		}
+		if rc.Mode == RateConstrainedCRF && rc.MaxRate == "" {
+			return fmt.Errorf("rate control: constrained crf requires maxrate and bufsize")
+		}
+	case RateABR:
+		if rc.Bitrate == "" {
+			return fmt.Errorf("rate control: abr requires a bitrate")
+		}
+		if rates["maxrate"] > 0 && rates["maxrate"] < rates["bitrate"] {
+			return fmt.Errorf("rate control: maxrate %s is below the bitrate %s", rc.MaxRate, rc.Bitrate)
+		}
+		if rates["minrate"] > rates["bitrate"] {
+			return fmt.Errorf("rate control: minrate %s is above the bitrate %s", rc.MinRate, rc.Bitrate)
+		}
+	case RateCBR:
+		if rc.Bitrate == "" || rc.BufSize == "" {
+			return fmt.Errorf("rate control: cbr requires a bitrate and bufsize")
+		}
+		if (rc.MaxRate != "" && rates["maxrate"] != rates["bitrate"]) || (rc.MinRate != "" && rates["minrate"] != rates["bitrate"]) {
+			return fmt.Errorf("rate control: cbr minrate and maxrate must equal the bitrate")
+		}
+		if family == familySVTAV1 {
+			return fmt.Errorf("rate control: cbr is not supported by libsvtav1 outside low delay mode")
+		}
+	case RateCQP:
+		if family == familyVPX || family == familyAOM {
+			return fmt.Errorf("rate control: cqp is not supported by %s, use crf", rc.Encoder)
+		}
+		maxQP := 51
+		if family == familySVTAV1 {
+			maxQP = 63
+		}
+		if rc.QP < 0 || rc.QP > maxQP {
+			return fmt.Errorf("rate control: QP must be between 0 and %d for %s, got %d", maxQP, rc.encoderName(), rc.QP)
+		}
+		if len(rates) > 0 {
+			return fmt.Errorf("rate control: cqp does not take bitrates")
+		}
+	default:
+		return fmt.Errorf("rate control: unknown mode %d", int(rc.Mode))
+	}
+
+	if rc.CPUUsed != 0 {
+		switch {
+		case family == familyVPX && rc.CPUUsed >= -8 && rc.CPUUsed <= 8:
+		case family == familyAOM && rc.CPUUsed >= 0 && rc.CPUUsed <= 9:
+		default:
+			return fmt.Errorf("rate control: cpu-used %d is not valid for %s", rc.CPUUsed, rc.encoderName())
+		}
+	}
+	return nil
+}
+
+// Parse returns the rate control flag arguments for the encoder
+func (rc RateControl) Parse() []string {
+	family := videoEncoderFamily(rc.Encoder)
+	var args []string
+	add := func(flag, value string) {
+		if value != "" {
+			args = append(args, flag, value)
+		}
+	}
+	vbv := func() {
+		add("-minrate", rc.MinRate)
+		add("-maxrate", rc.MaxRate)
+		add("-bufsize", rc.BufSize)
+	}
+
+	switch rc.Mode {
+	case RateCRF, RateConstrainedCRF:
+		add("-crf", strconv.Itoa(rc.CRF))
+		switch family {
+		case familyVPX, familyAOM:
+			// constant quality needs -b:v 0; a non-zero -b:v caps a constrained quality encode
+			if rc.Mode == RateCRF {
+				add("-b:v", "0")
+			} else {
+				add("-b:v", rc.MaxRate)
+				vbv()
+			}
+		case familyX265:
+			if rc.Mode == RateConstrainedCRF {
+				add("-x265-params", rc.x265VBV(false))
+			}
+		default:
+			vbv()
+		}
+	case RateABR:
+		add("-b:v", rc.Bitrate)
+		if family == familyX265 {
+			if rc.MaxRate != "" {
+				add("-x265-params", rc.x265VBV(false))
+			}
+		} else {
+			vbv()
+		}
+	case RateCBR:
+		add("-b:v", rc.Bitrate)
+		switch family {
+		case familyX265:
+			add("-x265-params", rc.x265VBV(true))
+		default:
+			add("-minrate", rc.Bitrate)
+			add("-maxrate", rc.Bitrate)
+			add("-bufsize", rc.BufSize)
+			if family == familyX264 {
+				add("-x264-params", "nal-hrd=cbr")
+			}
+		}
+	case RateCQP:
+		add("-qp", strconv.Itoa(rc.QP))
+	}
+
+	if rc.CPUUsed != 0 {
+		add("-cpu-used", strconv.Itoa(rc.CPUUsed))
+	}
+	return args
+}
+
+// x265VBV renders the x265 VBV parameters in kbps; cbr adds strict CBR and HRD signalling.
+func (rc RateControl) x265VBV(cbr bool) string {
+	kbps := func(value string) string {
+		bits, _ := ParseBitrate(value)
+		return strconv.FormatInt(bits/1000, 10)
+	}
+	maxRate := rc.MaxRate
+	if cbr {
+		maxRate = rc.Bitrate
+	}
+	params := fmt.Sprintf("vbv-maxrate=%s:vbv-bufsize=%s", kbps(maxRate), kbps(rc.BufSize))
+	if cbr {
+		params += ":strict-cbr=1:hrd=1"
+	}
+	return params
+}
+
+func (rc RateControl) encoderName() string {
+	if rc.Encoder == "" {
+		return "the encoder"
+	}
+	return rc.Encoder
+}
+
+// WithRateControl creates a new rate control flag; it replaces WithCRF and WithBitrate for the video stream.
+func WithRateControl(rc RateControl) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(rc)
+	}
+}
+
+// ParseBitrate parses an ffmpeg bitrate such as "2.5M", "800k" or "128000" into bits per second.
+func ParseBitrate(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("bitrate cannot be empty")
+	}
+
+	multiplier := 1.0
+	switch value[len(value)-1] {
+	case 'k', 'K':
+		multiplier = 1e3
+	case 'M':
+		multiplier = 1e6
+	case 'G':
+		multiplier = 1e9
+	}
+	number := value
+	if multiplier != 1 {
+		number = value[:len(value)-1]
+	}
+
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, fmt.Errorf("invalid bitrate %q", value)
+	}
+	return int64(math.Round(n * multiplier)), nil
+}
+
+// resolveEncoderOptions binds encoder dependent flags to the output's video codec and
+// rejects raw CRF/bitrate/qscale flags mixed with a RateControl. The encoder is the
+// last -c:v, or -c:v:0 when set through WithStreamCodec.
+func resolveEncoderOptions(options []OutputFlagParser) ([]OutputFlagParser, error) {
+	encoder := ""
+	hasRateControl, hasRawRate := false, false
+	rcSpeed, optionsSpeed := false, false
+	for _, option := range options {
+		switch f := option.(type) {
+		case VideoCodec:
+			encoder = string(f)
+		case StreamCodecFlag:
+			if (f.Stream.Type == MediaVideo || f.Stream.Type == MediaVideoOnly) && f.Stream.Index <= 0 {
+				encoder = f.Codec
+			}
+		case RateControl:
+			hasRateControl = true
+			rcSpeed = f.CPUUsed != 0
+		case CRFFlag, BitrateFlag, QScaleFlag:
+			hasRawRate = true
+		case VP9Options:
+			optionsSpeed = f.CPUUsed != 0
+		case AV1Options:
+			optionsSpeed = f.CPUUsed != 0
+		}
+	}
+	if hasRateControl && hasRawRate {
+		return nil, fmt.Errorf("rate control cannot be combined with -crf, -b:v or -q:v flags")
+	}
+	if rcSpeed && optionsSpeed {
+		return nil, fmt.Errorf("cpu-used is set both in the rate control and the encoder options")
+	}
+
+	resolved := make([]OutputFlagParser, len(options))
+	for i, option := range options {
+		resolved[i] = option
+		if eo, ok := option.(encoderOption); ok {
+			bound, err := eo.forEncoder(encoder)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = bound
+		}
+	}
+	return resolved, nil
+}
@@ -0,0 +1,104 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBitrate(t *testing.T) {
+	tests := map[string]int64{"2.5M": 2500000, "800k": 800000, "800K": 800000, "128000": 128000, "1G": 1000000000}
+	for value, want := range tests {
+		got, err := ParseBitrate(value)
+		if err != nil || got != want {
+			t.Errorf("ParseBitrate(%q) = %d, %v, want %d", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "k", "fast", "-1M", "2.5Mb"} {
+		if _, err := ParseBitrate(value); err == nil {
+			t.Errorf("expected error for %q, got nil", value)
+		}
+	}
+}
+
+func TestRateControl_Parse(t *testing.T) {
+	tests := []struct {
+		name string
+		rc   RateControl
+		want string
+	}{
+		{"x264 crf", ConstantQuality(23).For("libx264"), "-crf 23"},
+		{"x264 constrained", ConstrainedQuality(23, "4M", "8M").For("libx264"), "-crf 23 -maxrate 4M -bufsize 8M"},
+		{"x264 capped abr", AverageBitrate("3M").Capped("4.5M", "6M").For("libx264"), "-b:v 3M -maxrate 4.5M -bufsize 6M"},
+		{"x264 cbr", ConstantBitrate("3M", "6M").For("libx264"), "-b:v 3M -minrate 3M -maxrate 3M -bufsize 6M -x264-params nal-hrd=cbr"},
+		{"x264 cqp", ConstantQP(20).For("libx264"), "-qp 20"},
+		{"x265 constrained", ConstrainedQuality(24, "4M", "8M").For("libx265"), "-crf 24 -x265-params vbv-maxrate=4000:vbv-bufsize=8000"},
+		{"x265 cbr", ConstantBitrate("2.5M", "5M").For("libx265"), "-b:v 2.5M -x265-params vbv-maxrate=2500:vbv-bufsize=5000:strict-cbr=1:hrd=1"},
+		{"vp9 crf", ConstantQuality(31).For("libvpx-vp9"), "-crf 31 -b:v 0"},
+		{"vp9 constrained", ConstrainedQuality(31, "3M", "6M").For("libvpx-vp9"), "-crf 31 -b:v 3M -maxrate 3M -bufsize 6M"},
+		{"av1 crf speed", RateControl{Encoder: "libaom-av1", Mode: RateCRF, CRF: 30, CPUUsed: 6}, "-crf 30 -b:v 0 -cpu-used 6"},
+	}
+	for _, tt := range tests {
+		if err := tt.rc.Validate(); err != nil {
+			t.Errorf("%s: Validate() error: %v", tt.name, err)
+			continue
+		}
+		if got := strings.Join(tt.rc.Parse(), " "); got != tt.want {
+			t.Errorf("%s: Parse() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRateControl_Validate(t *testing.T) {
+	invalid := map[string]RateControl{
+		"crf range":        ConstantQuality(55).For("libx264"),
+		"maxrate no buf":   AverageBitrate("3M").Capped("4M", ""),
+		"constrained":      ConstrainedQuality(23, "", ""),
+		"crf with cap":     ConstantQuality(23).Capped("4M", "8M"),
+		"abr no bitrate":   AverageBitrate(""),
+		"abr maxrate low":  AverageBitrate("3M").Capped("2M", "4M"),
+		"cbr no bufsize":   ConstantBitrate("3M", ""),
+		"cbr maxrate":      ConstantBitrate("3M", "6M").Capped("4M", "6M"),
+		"cqp vp9":          ConstantQP(20).For("libvpx-vp9"),
+		"bad bitrate":      AverageBitrate("fast"),
+		"cpu-used on x264": {Encoder: "libx264", Mode: RateCRF, CRF: 23, CPUUsed: 4},
+		"unknown mode":     {Mode: RateControlMode(9)},
+	}
+	for name, rc := range invalid {
+		if err := rc.Validate(); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
+
+func TestRateControl_OutputBinding(t *testing.T) {
+	args, err := NewOutputBuilder().
+		WithFlag(WithRateControl(ConstantQuality(32))).
+		WithFlag(VideoCodecVP9).
+		File("out.webm").
+		Build().
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if got, want := strings.Join(args, " "), "-crf 32 -b:v 0 -c:v libvpx-vp9 out.webm"; got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+
+	_, err = NewOutputBuilder().
+		WithFlag(VideoCodecH264).
+		WithFlag(WithRateControl(ConstantQuality(23).For("libx265"))).
+		Build().
+		Build()
+	if err == nil {
+		t.Fatalf("expected encoder mismatch error, got nil")
+	}
+
+	_, err = NewOutputBuilder().
+		WithFlag(WithRateControl(ConstantQuality(23))).
+		WithFlag(WithBitrate("3M")).
+		Build().
+		Build()
+	if err == nil {
+		t.Fatalf("expected error mixing rate control and -b:v, got nil")
+	}
+}
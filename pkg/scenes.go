@@ -0,0 +1,101 @@
+package ffmpego
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SceneCut is a detected scene change.
+type SceneCut struct {
+	Time  time.Duration
+	Score float64
+	// Frame is the source frame number estimated from Time and the stream frame rate,
+	// or -1 when ffmpeg did not report a frame rate.
+	Frame int
+}
+
+var (
+	ptsTimeRe    = regexp.MustCompile(`pts_time:(-?\d+(?:\.\d+)?)`)
+	sceneScoreRe = regexp.MustCompile(`lavfi\.scene_score=(\d+(?:\.\d+)?)`)
+	streamFPSRe  = regexp.MustCompile(`Stream #\d+:\d+.*Video:.*?(\d+(?:\.\d+)?) fps`)
+)
+
+// SceneDetectionCommand builds the analysis pass:
+// "[0:v:0]select='gt(scene,T)'[scenes];[scenes]metadata=print:key=lavfi.scene_score[scored]" into the null muxer.
+func SceneDetectionCommand(input string, threshold float64) *Ffmpego {
+	return New("").
+		WithOptions(NewFfmpegOptions(WithInput(input))).
+		WithFilterGraph(NewComplexFilterBuilder().
+			Add(WithSelect("0:v:0", "scenes", fmt.Sprintf("gt(scene,%s)", formatFloat(threshold)))).
+			Add(WithMetadataPrint("scenes", "scored", "lavfi.scene_score")).
+			Build()).
+		Output(NewNullOutput("[scored]"))
+}
+
+// DetectScenes returns the scene changes of input whose score exceeds threshold (0..1).
+// An optional callback receives progress updates while the input is decoded.
+func DetectScenes(ctx context.Context, input string, threshold float64, progress ...ProgressCallback) ([]SceneCut, error) {
+	if threshold <= 0 || threshold >= 1 {
+		return nil, fmt.Errorf("scenes: threshold must be between 0 and 1, got %v", threshold)
+	}
+
+	cmd := SceneDetectionCommand(input, threshold)
+	if len(progress) != 0 && progress[0] != nil {
+		PipeProgress(cmd.flags)
+		cmd.WithProgressCallback(progress[0])
+	}
+
+	stderr, err := NewRunner(cmd).Capture(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseSceneCuts(stderr), nil
+}
+
+// ParseSceneCuts extracts scene cuts from metadata=print output. Each cut is logged as a
+// "pts_time:" line followed by its "lavfi.scene_score=" line.
+func ParseSceneCuts(stderr []byte) []SceneCut {
+	fps := 0.0
+	if m := streamFPSRe.FindSubmatch(stderr); m != nil {
+		fps, _ = strconv.ParseFloat(string(m[1]), 64)
+	}
+
+	var cuts []SceneCut
+	var pending *time.Duration
+	scanner := bufio.NewScanner(bytes.NewReader(stderr))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.Contains(line, "Parsed_metadata") {
+			continue
+		}
+
+		if m := ptsTimeRe.FindStringSubmatch(line); m != nil {
+			seconds, err := strconv.ParseFloat(m[1], 64)
+			if err == nil {
+				t := time.Duration(seconds * float64(time.Second))
+				pending = &t
+			}
+			continue
+		}
+
+		if m := sceneScoreRe.FindStringSubmatch(line); m != nil && pending != nil {
+			score, _ := strconv.ParseFloat(m[1], 64)
+			frame := -1
+			if fps > 0 {
+				frame = int(math.Round(pending.Seconds() * fps))
+			}
+			cuts = append(cuts, SceneCut{Time: *pending, Score: score, Frame: frame})
+			pending = nil
+		}
+	}
+
+	return cuts
+}
@@ -0,0 +1,47 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleSceneStderr = `Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'in.mp4':
+  Stream #0:0[0x1](und): Video: h264 (High) (avc1 / 0x31637661), yuv420p(progressive), 1920x1080 [SAR 1:1 DAR 16:9], 4951 kb/s, 25 fps, 25 tbr, 12800 tbn (default)
+[Parsed_metadata_1 @ 0x6000012e4000] frame:0    pts:38400   pts_time:3
+[Parsed_metadata_1 @ 0x6000012e4000] lavfi.scene_score=0.623110
+[Parsed_metadata_1 @ 0x6000012e4000] frame:1    pts:162816  pts_time:12.72
+[Parsed_metadata_1 @ 0x6000012e4000] lavfi.scene_score=0.451983
+[out#0/null @ 0x600001fe0000] video:1kB audio:0kB
+`
+
+func TestParseSceneCuts(t *testing.T) {
+	cuts := ParseSceneCuts([]byte(sampleSceneStderr))
+	if len(cuts) != 2 {
+		t.Fatalf("expected 2 cuts, got %d: %+v", len(cuts), cuts)
+	}
+
+	want := []SceneCut{
+		{Time: 3 * time.Second, Score: 0.62311, Frame: 75},
+		{Time: 12720 * time.Millisecond, Score: 0.451983, Frame: 318},
+	}
+	for i := range want {
+		if cuts[i] != want[i] {
+			t.Fatalf("cut %d mismatch: got %+v want %+v", i, cuts[i], want[i])
+		}
+	}
+}
+
+func TestSceneDetectionCommand(t *testing.T) {
+	args, err := SceneDetectionCommand("in.mp4", 0.4).Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	got := strings.Join(args, " ")
+	want := "-i in.mp4 -filter_complex [0:v:0]select='gt(scene,0.4)'[scenes];" +
+		"[scenes]metadata=print:key=lavfi.scene_score[scored] -map [scored] -f null -"
+	if got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,146 @@
+package ffmpego
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// atempo accepts 0.5 to 2.0 on older builds (4.2 and before), so larger changes are chained.
+const (
+	atempoMin = 0.5
+	atempoMax = 2.0
+)
+
+// AtempoChain splits a tempo factor into atempo steps within 0.5-2.0 whose product is factor,
+// e.g. 4 -> [2 2], 0.3 -> [0.5 0.6], 1 -> [1]. Non-positive or infinite factors return nil.
+func AtempoChain(factor float64) []float64 {
+	if !(factor > 0) || math.IsInf(factor, 1) {
+		return nil
+	}
+	var steps []float64
+	for factor > atempoMax {
+		steps = append(steps, atempoMax)
+		factor /= atempoMax
+	}
+	for factor < atempoMin {
+		steps = append(steps, atempoMin)
+		factor /= atempoMin
+	}
+	// drop a remaining unity step, unless it is the only one
+	factor = math.Round(factor*1e6) / 1e6
+	if factor != 1 || len(steps) == 0 {
+		steps = append(steps, factor)
+	}
+	return steps
+}
+
+// SpeedFilter changes playback speed by Factor (2 = twice as fast) for a video and/or an
+// audio stream:
+//
+//	[0:v]setpts=PTS/2,fps=30[v];[0:a]atempo=2[a]
+//
+// Video timestamps are divided by Factor; FrameRate, when set, resamples the result to a
+// constant rate (otherwise a 2x speed-up of 30 fps footage plays at 60 fps). Audio keeps its
+// pitch through chained atempo filters, or rubberband when Rubberband is set (needs an ffmpeg
+// built with --enable-librubberband).
+type SpeedFilter struct {
+	VideoInput  string
+	AudioInput  string
+	VideoOutput string
+	AudioOutput string
+	Factor      float64
+	FrameRate   string
+	Rubberband  bool
+}
+
+func (f SpeedFilter) Validate() error {
+	if math.IsNaN(f.Factor) || math.IsInf(f.Factor, 0) || f.Factor <= 0 {
+		return fmt.Errorf("speed: factor must be positive, got %v", f.Factor)
+	}
+	if f.Rubberband && (f.Factor < 0.01 || f.Factor > 100) {
+		return fmt.Errorf("speed: rubberband tempo must be between 0.01 and 100, got %v", f.Factor)
+	}
+	video := strings.TrimSpace(f.VideoInput) != "" || strings.TrimSpace(f.VideoOutput) != ""
+	audio := strings.TrimSpace(f.AudioInput) != "" || strings.TrimSpace(f.AudioOutput) != ""
+	if !video && !audio {
+		return fmt.Errorf("speed: a video or audio stream is required")
+	}
+	if video {
+		if err := checkLabels("speed", f.VideoInput, f.VideoOutput); err != nil {
+			return err
+		}
+	}
+	if audio {
+		if err := checkLabels("speed", f.AudioInput, f.AudioOutput); err != nil {
+			return err
+		}
+	}
+	if f.FrameRate != "" {
+		if !video {
+			return fmt.Errorf("speed: frame rate requires a video stream")
+		}
+		if fps, err := ParseFrameRate(f.FrameRate); err != nil || fps <= 0 {
+			return fmt.Errorf("speed: invalid frame rate %q", f.FrameRate)
+		}
+	}
+	return nil
+}
+
+func (f SpeedFilter) Parse() string {
+	var chains []string
+	if f.VideoInput != "" {
+		video := "setpts=PTS/" + formatFloat(f.Factor)
+		if f.FrameRate != "" {
+			video += ",fps=" + f.FrameRate
+		}
+		chains = append(chains, fmt.Sprintf("[%s]%s[%s]", f.VideoInput, video, f.VideoOutput))
+	}
+	if f.AudioInput != "" {
+		chains = append(chains, fmt.Sprintf("[%s]%s[%s]", f.AudioInput, f.audioChain(), f.AudioOutput))
+	}
+	return strings.Join(chains, ";")
+}
+
+func (f SpeedFilter) audioChain() string {
+	if f.Rubberband {
+		return "rubberband=tempo=" + formatFloat(f.Factor)
+	}
+	steps := AtempoChain(f.Factor)
+	filters := make([]string, len(steps))
+	for i, step := range steps {
+		filters[i] = "atempo=" + formatFloat(step)
+	}
+	return strings.Join(filters, ",")
+}
+
+// WithSpeed adds a speed change for a video and an audio stream; pass empty labels to skip
+// either one. Renders: "[videoIn]setpts=PTS/factor[outV];[audioIn]atempo=...[outA]"
+func WithSpeed(videoIn string, audioIn string, factor float64, outV string, outA string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(SpeedFilter{
+			VideoInput:  strings.TrimSpace(videoIn),
+			AudioInput:  strings.TrimSpace(audioIn),
+			VideoOutput: strings.TrimSpace(outV),
+			AudioOutput: strings.TrimSpace(outA),
+			Factor:      factor,
+		})
+	}
+}
+
+// WithSpeedFor is WithSpeed resampling video to frameRate (empty keeps the sped up rate) and
+// using rubberband for audio when caps reports it and factor is within its range; nil caps
+// always uses atempo.
+func WithSpeedFor(caps *Capabilities, videoIn string, audioIn string, factor float64, outV string, outA string, frameRate string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(SpeedFilter{
+			VideoInput:  strings.TrimSpace(videoIn),
+			AudioInput:  strings.TrimSpace(audioIn),
+			VideoOutput: strings.TrimSpace(outV),
+			AudioOutput: strings.TrimSpace(outA),
+			Factor:      factor,
+			FrameRate:   strings.TrimSpace(frameRate),
+			Rubberband:  caps.HasFilter("rubberband") && factor >= 0.01 && factor <= 100,
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package ffmpego
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAtempoChain(t *testing.T) {
+	tests := map[float64][]float64{
+		1:    {1},
+		1.5:  {1.5},
+		4:    {2, 2},
+		3:    {2, 1.5},
+		0.25: {0.5, 0.5},
+		0.3:  {0.5, 0.6},
+		10:   {2, 2, 2, 1.25},
+		0:    nil,
+	}
+	for factor, want := range tests {
+		if got := AtempoChain(factor); !reflect.DeepEqual(got, want) {
+			t.Errorf("AtempoChain(%v) = %v, want %v", factor, got, want)
+		}
+	}
+}
+
+func TestSpeedFilter_ValidateParse(t *testing.T) {
+	f := SpeedFilter{VideoInput: "0:v", VideoOutput: "v", AudioInput: "0:a", AudioOutput: "a", Factor: 4}
+	if err := f.Validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	got := f.Parse()
+	want := "[0:v]setpts=PTS/4[v];[0:a]atempo=2,atempo=2[a]"
+	if got != want {
+		t.Fatalf("parse mismatch: got %q want %q", got, want)
+	}
+}
+
+func TestWithSpeed_AudioOnly(t *testing.T) {
+	graph, err := NewComplexFilterBuilder().Add(WithSpeed("", "1:a", 0.3, "", "slow")).Build().BuildAndValidate()
+	if err != nil {
+		t.Fatalf("BuildAndValidate() error: %v", err)
+	}
+	want := "[1:a]atempo=0.5,atempo=0.6[slow]"
+	if graph != want {
+		t.Fatalf("graph mismatch:\n got: %s\nwant: %s", graph, want)
+	}
+}
+
+func TestWithSpeedFor_Rubberband(t *testing.T) {
+	caps := &Capabilities{Filters: map[string]bool{"rubberband": true}}
+	graph, err := NewComplexFilterBuilder().
+		Add(WithSpeedFor(caps, "0:v", "0:a", 2, "v", "a", "30")).
+		Build().
+		BuildAndValidate()
+	if err != nil {
+		t.Fatalf("BuildAndValidate() error: %v", err)
+	}
+	want := "[0:v]setpts=PTS/2,fps=30[v];[0:a]rubberband=tempo=2[a]"
+	if graph != want {
+		t.Fatalf("graph mismatch:\n got: %s\nwant: %s", graph, want)
+	}
+}
+
+func TestSpeedFilter_InvalidFactor(t *testing.T) {
+	cases := []SpeedFilter{
+		{VideoInput: "v", VideoOutput: "o", Factor: 0},
+		{AudioInput: "a", AudioOutput: "o", Factor: 200, Rubberband: true},
+	}
+	for i, f := range cases {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("case %d: expected error for factor, got nil", i)
+		}
+	}
+}
+
+func TestSpeedFilter_InvalidStreams(t *testing.T) {
+	cases := []SpeedFilter{
+		{Factor: 2},
+		{AudioInput: "a", Factor: 2},
+	}
+	for i, f := range cases {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("case %d: expected error for streams, got nil", i)
+		}
+	}
+}
+
+func TestSpeedFilter_InvalidFrameRate(t *testing.T) {
+	cases := []SpeedFilter{
+		{AudioInput: "a", AudioOutput: "o", Factor: 2, FrameRate: "30"},
+		{VideoInput: "v", VideoOutput: "o", Factor: 2, FrameRate: "fast"},
+	}
+	for i, f := range cases {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("case %d: expected error for frame rate, got nil", i)
+		}
+	}
+}
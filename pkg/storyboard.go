@@ -0,0 +1,245 @@
+package ffmpego
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// StoryboardOptions configures a Storyboard job. Zero values fall back to the defaults below.
+type StoryboardOptions struct {
+	// Interval between thumbnails. When zero it is derived from MaxThumbnails.
+	Interval time.Duration
+	// MaxThumbnails bounds the thumbnail count when Interval is derived (default 100).
+	MaxThumbnails int
+	// Width of each thumbnail; height follows the source display aspect ratio (default 160).
+	Width int
+	// Columns and Rows of each sprite grid (default 10x10).
+	Columns int
+	Rows    int
+	// SpritePattern is the image2 output pattern for sprite files (default "sprite_%03d.jpg").
+	SpritePattern string
+	// VTTPath is where Run writes the WebVTT thumbnail track (default "storyboard.vtt").
+	VTTPath string
+	// BaseURL is prepended to sprite file names (without directory) inside the WebVTT file.
+	BaseURL string
+	// Quality is the JPEG -q:v value, 1 (best) to 31 (default 4).
+	Quality   int
+	Overwrite bool
+	Progress  ProgressCallback
+}
+
+func (o StoryboardOptions) withDefaults() StoryboardOptions {
+	if o.MaxThumbnails == 0 {
+		o.MaxThumbnails = 100
+	}
+	if o.Width == 0 {
+		o.Width = 160
+	}
+	if o.Columns == 0 {
+		o.Columns = 10
+	}
+	if o.Rows == 0 {
+		o.Rows = 10
+	}
+	if o.SpritePattern == "" {
+		o.SpritePattern = "sprite_%03d.jpg"
+	}
+	if o.VTTPath == "" {
+		o.VTTPath = "storyboard.vtt"
+	}
+	if o.Quality == 0 {
+		o.Quality = 4
+	}
+	return o
+}
+
+// Validate validates the storyboard options
+func (o StoryboardOptions) Validate() error {
+	if o.Interval < 0 {
+		return fmt.Errorf("storyboard: interval must be non-negative, got %s", o.Interval)
+	}
+	if o.MaxThumbnails <= 0 {
+		return fmt.Errorf("storyboard: max thumbnails must be positive, got %d", o.MaxThumbnails)
+	}
+	if o.Width <= 0 {
+		return fmt.Errorf("storyboard: thumbnail width must be positive, got %d", o.Width)
+	}
+	if o.Columns <= 0 || o.Rows <= 0 {
+		return fmt.Errorf("storyboard: grid must be positive, got %dx%d", o.Columns, o.Rows)
+	}
+	if !strings.Contains(o.SpritePattern, "%") {
+		return fmt.Errorf("storyboard: sprite pattern %q must contain a numeric placeholder", o.SpritePattern)
+	}
+	return nil
+}
+
+// StoryboardCue maps a time range to a thumbnail region inside a sprite.
+type StoryboardCue struct {
+	Start  time.Duration
+	End    time.Duration
+	Sprite string
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// Storyboard produces scrubbing preview sprites and a matching WebVTT thumbnail track
+// using a single "fps=1/N,scale=W:H,tile=CxR" chain.
+type Storyboard struct {
+	input       string
+	streamIndex int
+	duration    time.Duration
+	opts        StoryboardOptions
+	interval    time.Duration
+	thumbWidth  int
+	thumbHeight int
+	count       int
+}
+
+// NewStoryboard computes the sampling interval and thumbnail geometry from a probed input.
+func NewStoryboard(probe *ProbeResult, opts StoryboardOptions) (*Storyboard, error) {
+	opts = opts.withDefaults()
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if probe == nil || probe.Format.Filename == "" {
+		return nil, fmt.Errorf("storyboard: probe result must include the source filename")
+	}
+
+	video, ok := probe.VideoStream()
+	if !ok {
+		return nil, fmt.Errorf("storyboard: source %q has no video stream", probe.Format.Filename)
+	}
+	srcW, srcH := video.DisplaySize()
+	if srcW <= 0 || srcH <= 0 {
+		return nil, fmt.Errorf("storyboard: source video has invalid dimensions %dx%d", srcW, srcH)
+	}
+	duration := probe.Duration()
+	if duration <= 0 {
+		return nil, fmt.Errorf("storyboard: source %q has unknown duration", probe.Format.Filename)
+	}
+
+	interval := opts.Interval
+	if interval == 0 {
+		// round up to whole seconds so the thumbnail count stays within MaxThumbnails
+		perThumb := (duration + time.Duration(opts.MaxThumbnails) - 1) / time.Duration(opts.MaxThumbnails)
+		interval = (perThumb + time.Second - 1).Truncate(time.Second)
+	}
+
+	return &Storyboard{
+		input:       probe.Format.Filename,
+		streamIndex: video.Index,
+		duration:    duration,
+		opts:        opts,
+		interval:    interval,
+		thumbWidth:  evenDimension(float64(opts.Width)),
+		thumbHeight: evenDimension(float64(opts.Width) / video.DisplayAspectRatio()),
+		count:       int(math.Ceil(float64(duration) / float64(interval))),
+	}, nil
+}
+
+// Interval returns the time between thumbnails.
+func (s *Storyboard) Interval() time.Duration {
+	return s.interval
+}
+
+// Command builds the ffmpeg command that writes the sprite images.
+func (s *Storyboard) Command() *Ffmpego {
+	flags := []FfmpegFlagFn{WithInput(s.input)}
+	if s.opts.Overwrite {
+		flags = append(flags, WithOverwrite())
+	}
+
+	graph := NewComplexFilterBuilder().
+		Add(WithFPS(fmt.Sprintf("0:%d", s.streamIndex), "sb_fps", "1/"+formatSeconds(s.interval))).
+		// square pixels, so thumbnails of anamorphic sources keep their display aspect ratio
+		Add(WithFilterChain("sb_fps", fmt.Sprintf("scale=%d:%d,setsar=1", s.thumbWidth, s.thumbHeight), "sb_scaled")).
+		Add(WithTile("sb_scaled", "sb_sprite", s.opts.Columns, s.opts.Rows)).
+		Build()
+
+	output := NewOutputBuilder().
+		WithFlag(WithMap("[sb_sprite]")).
+		WithFlag(WithQScale(s.opts.Quality)).
+		File(s.opts.SpritePattern).
+		Build()
+
+	return New("").
+		WithOptions(NewFfmpegOptions(flags...)).
+		WithFilterGraph(graph).
+		Output(output).
+		WithProgressCallback(s.opts.Progress)
+}
+
+// Sprites returns the sprite file names ffmpeg writes, in order.
+func (s *Storyboard) Sprites() []string {
+	perSprite := s.opts.Columns * s.opts.Rows
+	sprites := make([]string, (s.count+perSprite-1)/perSprite)
+	for i := range sprites {
+		// image2 numbers files starting at 1
+		sprites[i] = fmt.Sprintf(s.opts.SpritePattern, i+1)
+	}
+	return sprites
+}
+
+// Cues returns one cue per thumbnail with its sprite coordinates.
+func (s *Storyboard) Cues() []StoryboardCue {
+	sprites := s.Sprites()
+	perSprite := s.opts.Columns * s.opts.Rows
+	cues := make([]StoryboardCue, s.count)
+	for i := range cues {
+		cell := i % perSprite
+		end := time.Duration(i+1) * s.interval
+		if end > s.duration {
+			end = s.duration
+		}
+		cues[i] = StoryboardCue{
+			Start:  time.Duration(i) * s.interval,
+			End:    end,
+			Sprite: sprites[i/perSprite],
+			X:      (cell % s.opts.Columns) * s.thumbWidth,
+			Y:      (cell / s.opts.Columns) * s.thumbHeight,
+			Width:  s.thumbWidth,
+			Height: s.thumbHeight,
+		}
+	}
+	return cues
+}
+
+// WriteVTT writes the WebVTT thumbnail track mapping time ranges to "sprite.jpg#xywh=x,y,w,h".
+func (s *Storyboard) WriteVTT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprint(bw, "WEBVTT\n")
+	for _, cue := range s.Cues() {
+		fmt.Fprintf(bw, "\n%s --> %s\n%s#xywh=%d,%d,%d,%d\n",
+			FormatTimestamp(cue.Start), FormatTimestamp(cue.End),
+			s.opts.BaseURL+filepath.Base(cue.Sprite), cue.X, cue.Y, cue.Width, cue.Height)
+	}
+	return bw.Flush()
+}
+
+// Run renders the sprites through FfmpegoRunner and then writes the WebVTT file.
+func (s *Storyboard) Run(ctx context.Context) error {
+	if err := NewRunner(s.Command()).Run(ctx); err != nil {
+		return err
+	}
+
+	f, err := os.Create(s.opts.VTTPath)
+	if err != nil {
+		return fmt.Errorf("storyboard: failed to create vtt file: %w", err)
+	}
+	defer f.Close()
+
+	if err := s.WriteVTT(f); err != nil {
+		return fmt.Errorf("storyboard: failed to write vtt file: %w", err)
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,113 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoryboard_CommandAndVTT(t *testing.T) {
+	probe := &ProbeResult{
+		Format:  ProbeFormat{Filename: "in.mp4", Duration: "25.000000"},
+		Streams: []ProbeStream{{Index: 0, CodecType: "video", Width: 1920, Height: 1080}},
+	}
+
+	sb, err := NewStoryboard(probe, StoryboardOptions{Interval: 5 * time.Second, Columns: 2, Rows: 2})
+	if err != nil {
+		t.Fatalf("NewStoryboard() error: %v", err)
+	}
+
+	args, err := sb.Command().Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	idx := indexOf(args, "-filter_complex")
+	if idx < 0 {
+		t.Fatalf("missing -filter_complex in args: %v", args)
+	}
+	wantGraph := "[0:0]fps=1/5[sb_fps];[sb_fps]scale=160:90,setsar=1[sb_scaled];[sb_scaled]tile=2x2[sb_sprite]"
+	if args[idx+1] != wantGraph {
+		t.Fatalf("graph mismatch:\n got: %s\nwant: %s", args[idx+1], wantGraph)
+	}
+
+	if got := sb.Sprites(); len(got) != 2 || got[1] != "sprite_002.jpg" {
+		t.Fatalf("sprites mismatch: %v", got)
+	}
+
+	var vtt strings.Builder
+	if err := sb.WriteVTT(&vtt); err != nil {
+		t.Fatalf("WriteVTT() error: %v", err)
+	}
+	for _, part := range []string{
+		"WEBVTT\n",
+		"00:00:00.000 --> 00:00:05.000\nsprite_001.jpg#xywh=0,0,160,90\n",
+		"00:00:15.000 --> 00:00:20.000\nsprite_001.jpg#xywh=160,90,160,90\n",
+		"00:00:20.000 --> 00:00:25.000\nsprite_002.jpg#xywh=0,0,160,90\n",
+	} {
+		if !strings.Contains(vtt.String(), part) {
+			t.Fatalf("vtt missing %q:\n%s", part, vtt.String())
+		}
+	}
+}
+
+func TestStoryboard_DerivedInterval(t *testing.T) {
+	probe := &ProbeResult{
+		Format:  ProbeFormat{Filename: "in.mp4", Duration: "3600"},
+		Streams: []ProbeStream{{Index: 0, CodecType: "video", Width: 1280, Height: 720}},
+	}
+
+	sb, err := NewStoryboard(probe, StoryboardOptions{MaxThumbnails: 120})
+	if err != nil {
+		t.Fatalf("NewStoryboard() error: %v", err)
+	}
+	if sb.Interval() != 30*time.Second {
+		t.Fatalf("interval mismatch: got %v", sb.Interval())
+	}
+}
+
+func TestStoryboard_DerivedIntervalWithinMax(t *testing.T) {
+	for _, tt := range []struct {
+		duration string
+		interval time.Duration
+	}{
+		{"249", 3 * time.Second},
+		{"3640", 37 * time.Second},
+		{"0.5", time.Second},
+	} {
+		probe := &ProbeResult{
+			Format:  ProbeFormat{Filename: "in.mp4", Duration: tt.duration},
+			Streams: []ProbeStream{{Index: 0, CodecType: "video", Width: 1280, Height: 720}},
+		}
+		sb, err := NewStoryboard(probe, StoryboardOptions{})
+		if err != nil {
+			t.Fatalf("NewStoryboard() error: %v", err)
+		}
+		if sb.Interval() != tt.interval {
+			t.Errorf("%ss: interval = %v, want %v", tt.duration, sb.Interval(), tt.interval)
+		}
+		if count := len(sb.Cues()); count > sb.opts.MaxThumbnails {
+			t.Errorf("%ss: %d thumbnails, want at most %d", tt.duration, count, sb.opts.MaxThumbnails)
+		}
+	}
+}
+
+func TestStoryboard_AnamorphicSource(t *testing.T) {
+	probe := &ProbeResult{
+		Format:  ProbeFormat{Filename: "in.mpg", Duration: "60"},
+		Streams: []ProbeStream{{Index: 0, CodecType: "video", Width: 720, Height: 576, SampleAspectRatio: "64:45"}},
+	}
+	sb, err := NewStoryboard(probe, StoryboardOptions{Width: 160})
+	if err != nil {
+		t.Fatalf("NewStoryboard() error: %v", err)
+	}
+	if sb.thumbWidth != 160 || sb.thumbHeight != 90 {
+		t.Fatalf("thumbnail size = %dx%d, want 160x90", sb.thumbWidth, sb.thumbHeight)
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	got := FormatTimestamp(time.Hour + 2*time.Minute + 3*time.Second + 45*time.Millisecond)
+	if got != "01:02:03.045" {
+		t.Fatalf("timestamp mismatch: got %q", got)
+	}
+}
@@ -0,0 +1,359 @@
+package ffmpego
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MediaType is the stream type part of a stream specifier.
+type MediaType string
+
+const (
+	MediaVideo      MediaType = "v"
+	MediaVideoOnly  MediaType = "V" // video streams that are not attached pictures or thumbnails
+	MediaAudio      MediaType = "a"
+	MediaSubtitle   MediaType = "s"
+	MediaData       MediaType = "d"
+	MediaAttachment MediaType = "t"
+)
+
+// StreamSpec is a typed ffmpeg stream specifier. The same type describes -map
+// arguments ("-0:a:1?", with an input index) and the suffix of per-stream options
+// ("a:1" in "-c:a:1"). Input, Program and Index use -1 for "not set", so build specs
+// with InputStreams or StreamsOf (or the VideoStream/AudioStream/SubtitleStream
+// shortcuts) rather than a zero StreamSpec, which means input 0, stream 0.
+//
+//	InputStreams(0).OfType(MediaAudio).Nth(1).IfPresent()  -> "0:a:1?"
+//	InputStreams(1).OfType(MediaAudio).WithTag("language", "eng") -> "1:a:m:language:eng"
+//	AudioStream(1)                                          -> "a:1"
+type StreamSpec struct {
+	Input         int    // input file index, -map only
+	Group         string // g:<index> or g:#<id>, stream groups, ffmpeg 7.0+
+	Program       int    // p:<id>
+	Type          MediaType
+	Disposition   string // disp:default+forced, ffmpeg 6.1+
+	Usable        bool   // u: streams with a usable configuration (codec, dimensions)
+	Index         int    // n-th stream among the ones matched so far
+	StreamID      string // #<id> (or i:<id>), e.g. the MPEG-TS PID "0x101"
+	MetadataKey   string // m:<key>[:<value>]
+	MetadataValue string
+	Optional      bool // trailing '?': ignore the map when nothing matches
+	Negative      bool // leading '-': remove matching streams from earlier maps
+}
+
+// InputStreams returns a map spec selecting every stream of the given input.
+func InputStreams(input int) StreamSpec {
+	return StreamSpec{Input: input, Program: -1, Index: -1}
+}
+
+// StreamsOf returns an option specifier selecting every output stream of type t, e.g. "a".
+func StreamsOf(t MediaType) StreamSpec {
+	return StreamSpec{Input: -1, Program: -1, Type: t, Index: -1}
+}
+
+// VideoStream returns the option specifier of the index-th output video stream, "v:<index>".
+func VideoStream(index int) StreamSpec {
+	return StreamsOf(MediaVideo).Nth(index)
+}
+
+// AudioStream returns the option specifier of the index-th output audio stream, "a:<index>".
+func AudioStream(index int) StreamSpec {
+	return StreamsOf(MediaAudio).Nth(index)
+}
+
+// SubtitleStream returns the option specifier of the index-th output subtitle stream, "s:<index>".
+func SubtitleStream(index int) StreamSpec {
+	return StreamsOf(MediaSubtitle).Nth(index)
+}
+
+// OfType restricts the spec to streams of type t.
+func (s StreamSpec) OfType(t MediaType) StreamSpec {
+	s.Type = t
+	return s
+}
+
+// Nth selects the index-th matching stream.
+func (s StreamSpec) Nth(index int) StreamSpec {
+	s.Index = index
+	return s
+}
+
+// WithID selects the stream with the container specific id, e.g. the PID "0x101".
+func (s StreamSpec) WithID(id string) StreamSpec {
+	s.StreamID = id
+	return s
+}
+
+// InGroup restricts the spec to a stream group, by index ("1") or id ("#5").
+func (s StreamSpec) InGroup(group string) StreamSpec {
+	s.Group = group
+	return s
+}
+
+// UsableOnly restricts the spec to streams with a usable configuration.
+func (s StreamSpec) UsableOnly() StreamSpec {
+	s.Usable = true
+	return s
+}
+
+// InProgram restricts the spec to the streams of a program.
+func (s StreamSpec) InProgram(program int) StreamSpec {
+	s.Program = program
+	return s
+}
+
+// WithTag matches streams carrying the metadata key, with value when it is not empty.
+func (s StreamSpec) WithTag(key, value string) StreamSpec {
+	s.MetadataKey = key
+	s.MetadataValue = value
+	return s
+}
+
+// WithDispositions matches streams with all the given dispositions set.
+func (s StreamSpec) WithDispositions(dispositions ...string) StreamSpec {
+	s.Disposition = strings.Join(dispositions, "+")
+	return s
+}
+
+// IfPresent makes a map optional, so inputs without matching streams do not fail.
+func (s StreamSpec) IfPresent() StreamSpec {
+	s.Optional = true
+	return s
+}
+
+// Exclude turns a map into a negative map removing the matching streams.
+func (s StreamSpec) Exclude() StreamSpec {
+	s.Negative = true
+	return s
+}
+
+// String renders the specifier, e.g. "0:p:1:a:1" or "-0:s?".
+func (s StreamSpec) String() string {
+	var parts []string
+	if s.Input >= 0 {
+		parts = append(parts, strconv.Itoa(s.Input))
+	}
+	if s.Group != "" {
+		parts = append(parts, "g", s.Group)
+	}
+	if s.Program >= 0 {
+		parts = append(parts, "p", strconv.Itoa(s.Program))
+	}
+	if s.Type != "" {
+		parts = append(parts, string(s.Type))
+	}
+	if s.Disposition != "" {
+		parts = append(parts, "disp", s.Disposition)
+	}
+	if s.Usable {
+		parts = append(parts, "u")
+	}
+	if s.Index >= 0 {
+		parts = append(parts, strconv.Itoa(s.Index))
+	}
+	if s.StreamID != "" {
+		parts = append(parts, "#"+s.StreamID)
+	}
+	if s.MetadataKey != "" {
+		parts = append(parts, "m", s.MetadataKey)
+		if s.MetadataValue != "" {
+			parts = append(parts, s.MetadataValue)
+		}
+	}
+
+	spec := strings.Join(parts, ":")
+	if s.Negative {
+		spec = "-" + spec
+	}
+	if s.Optional {
+		spec += "?"
+	}
+	return spec
+}
+
+// IsMap reports whether the spec carries an input index, i.e. is a -map argument.
+func (s StreamSpec) IsMap() bool {
+	return s.Input >= 0
+}
+
+// Validate validates the specifier parts and their combination
+func (s StreamSpec) Validate() error {
+	if s.Input < -1 || s.Program < -1 || s.Index < -1 {
+		return fmt.Errorf("stream specifier: input, program and index must be non-negative or -1 when unset")
+	}
+	switch s.Type {
+	case "", MediaVideo, MediaVideoOnly, MediaAudio, MediaSubtitle, MediaData, MediaAttachment:
+	default:
+		return fmt.Errorf("stream specifier: unknown media type %q", s.Type)
+	}
+	if s.Disposition != "" {
+		for _, d := range strings.Split(s.Disposition, "+") {
+			if !isDisposition(d) {
+				return fmt.Errorf("stream specifier: unknown disposition %q", d)
+			}
+		}
+	}
+	if s.MetadataKey == "" && s.MetadataValue != "" {
+		return fmt.Errorf("stream specifier: metadata value %q needs a key", s.MetadataValue)
+	}
+	if strings.Contains(s.MetadataKey, ":") {
+		return fmt.Errorf("stream specifier: metadata key %q cannot contain ':'", s.MetadataKey)
+	}
+	if s.MetadataKey != "" && s.Index >= 0 {
+		// both end the specifier in ffmpeg's grammar
+		return fmt.Errorf("stream specifier: a stream index cannot be combined with a metadata match")
+	}
+	if s.StreamID != "" {
+		if _, err := strconv.ParseInt(s.StreamID, 0, 64); err != nil {
+			return fmt.Errorf("stream specifier: invalid stream id %q", s.StreamID)
+		}
+		if s.Index >= 0 || s.MetadataKey != "" {
+			return fmt.Errorf("stream specifier: a stream id cannot be combined with an index or metadata match")
+		}
+	}
+	if s.Group != "" {
+		group := strings.TrimPrefix(s.Group, "#")
+		if _, err := strconv.ParseInt(group, 0, 64); err != nil {
+			return fmt.Errorf("stream specifier: invalid stream group %q", s.Group)
+		}
+	}
+	if !s.IsMap() {
+		if s.Optional || s.Negative {
+			return fmt.Errorf("stream specifier: '?' and '-' require an input index (-map only)")
+		}
+		if s.String() == "" {
+			return fmt.Errorf("stream specifier cannot be empty")
+		}
+	}
+	return nil
+}
+
+// ParseStreamSpec parses an option specifier without input index, e.g. "a:1" or "p:1:v".
+func ParseStreamSpec(value string) (StreamSpec, error) {
+	spec := StreamSpec{Input: -1, Program: -1, Index: -1}
+	if value == "" {
+		return spec, fmt.Errorf("stream specifier cannot be empty")
+	}
+	if err := parseStreamSpecParts(&spec, strings.Split(value, ":")); err != nil {
+		return spec, fmt.Errorf("stream specifier %q: %w", value, err)
+	}
+	return spec, spec.Validate()
+}
+
+// ParseMapSpec parses a -map argument, e.g. "0", "0:a:1?", "-0:s" or "1:m:language:eng".
+// Filtergraph labels such as "[out]" are not stream specifiers.
+func ParseMapSpec(value string) (StreamSpec, error) {
+	spec := StreamSpec{Input: -1, Program: -1, Index: -1}
+	rest := value
+	if strings.HasPrefix(rest, "-") {
+		spec.Negative = true
+		rest = rest[1:]
+	}
+	if strings.HasSuffix(rest, "?") {
+		spec.Optional = true
+		rest = strings.TrimSuffix(rest, "?")
+	}
+
+	parts := strings.Split(rest, ":")
+	input, err := strconv.Atoi(parts[0])
+	if err != nil || input < 0 {
+		return spec, fmt.Errorf("map %q must start with an input index", value)
+	}
+	spec.Input = input
+	if err := parseStreamSpecParts(&spec, parts[1:]); err != nil {
+		return spec, fmt.Errorf("map %q: %w", value, err)
+	}
+	return spec, spec.Validate()
+}
+
+// parseStreamSpecParts fills spec from the colon separated parts following the input index.
+func parseStreamSpecParts(spec *StreamSpec, parts []string) error {
+	next := func(i int, what string) (string, error) {
+		if i+1 >= len(parts) || parts[i+1] == "" {
+			return "", fmt.Errorf("%s is missing a value", what)
+		}
+		return parts[i+1], nil
+	}
+
+	for i := 0; i < len(parts); i++ {
+		part := parts[i]
+		switch part {
+		case "v", "V", "a", "s", "d", "t":
+			if spec.Type != "" || spec.Index >= 0 {
+				return fmt.Errorf("unexpected media type %q", part)
+			}
+			spec.Type = MediaType(part)
+		case "u":
+			spec.Usable = true
+		case "g":
+			value, err := next(i, "stream group")
+			if err != nil {
+				return err
+			}
+			i++
+			if value == "i" {
+				if value, err = next(i, "stream group id"); err != nil {
+					return err
+				}
+				value = "#" + value
+				i++
+			}
+			spec.Group = value
+		case "i":
+			value, err := next(i, "stream id")
+			if err != nil {
+				return err
+			}
+			if i+1 != len(parts)-1 {
+				return fmt.Errorf("stream id %s must end the specifier", value)
+			}
+			spec.StreamID = value
+			return nil
+		case "p":
+			value, err := next(i, "program")
+			if err != nil {
+				return err
+			}
+			program, err := strconv.Atoi(value)
+			if err != nil || program < 0 {
+				return fmt.Errorf("invalid program id %q", value)
+			}
+			spec.Program = program
+			i++
+		case "disp":
+			value, err := next(i, "disposition")
+			if err != nil {
+				return err
+			}
+			spec.Disposition = value
+			i++
+		case "m":
+			key, err := next(i, "metadata match")
+			if err != nil {
+				return err
+			}
+			spec.MetadataKey = key
+			// the value may itself contain colons and ends the specifier
+			spec.MetadataValue = strings.Join(parts[i+2:], ":")
+			return nil
+		default:
+			if id, ok := strings.CutPrefix(part, "#"); ok {
+				if id == "" || i != len(parts)-1 {
+					return fmt.Errorf("stream id %q must be set and end the specifier", part)
+				}
+				spec.StreamID = id
+				return nil
+			}
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 {
+				return fmt.Errorf("unsupported specifier part %q", part)
+			}
+			if i != len(parts)-1 {
+				return fmt.Errorf("stream index %d must end the specifier", index)
+			}
+			spec.Index = index
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStreamSpec_String(t *testing.T) {
+	tests := []struct {
+		spec StreamSpec
+		want string
+	}{
+		{InputStreams(0), "0"},
+		{InputStreams(0).OfType(MediaAudio).Nth(1).IfPresent(), "0:a:1?"},
+		{InputStreams(1).OfType(MediaAudio).WithTag("language", "eng"), "1:a:m:language:eng"},
+		{InputStreams(0).OfType(MediaSubtitle).Exclude(), "-0:s"},
+		{InputStreams(0).InProgram(2).OfType(MediaVideo), "0:p:2:v"},
+		{StreamsOf(MediaAudio).WithDispositions("default"), "a:disp:default"},
+		{AudioStream(1), "a:1"},
+		{VideoStream(0), "v:0"},
+		{InputStreams(0).WithID("0x101"), "0:#0x101"},
+		{InputStreams(0).InGroup("1").OfType(MediaAudio).UsableOnly(), "0:g:1:a:u"},
+	}
+	for _, tt := range tests {
+		if got := tt.spec.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+		if err := tt.spec.Validate(); err != nil {
+			t.Errorf("Validate(%q) error: %v", tt.want, err)
+		}
+	}
+}
+
+func TestStreamSpec_Validate(t *testing.T) {
+	invalid := []StreamSpec{
+		StreamsOf("x"),
+		AudioStream(0).IfPresent(),
+		StreamsOf(MediaAudio).Exclude(),
+		StreamsOf(""),
+		InputStreams(0).OfType(MediaAudio).Nth(0).WithTag("language", "eng"),
+		InputStreams(0).WithDispositions("loud"),
+		InputStreams(0).WithTag("", "eng"),
+		InputStreams(-2),
+	}
+	for _, spec := range invalid {
+		if err := spec.Validate(); err == nil {
+			t.Errorf("expected error for %+v, got nil", spec)
+		}
+	}
+}
+
+func TestParseMapSpec(t *testing.T) {
+	for _, value := range []string{"0", "0:v?", "1:a:2", "-0:s", "0:p:1:a", "0:a:m:title:Director: commentary", "0:disp:default:0",
+		"0:#0x101", "0:u", "0:g:1", "0:g:#5:a", "0:v:u:0"} {
+		spec, err := ParseMapSpec(value)
+		if err != nil {
+			t.Errorf("ParseMapSpec(%q) error: %v", value, err)
+			continue
+		}
+		if got := spec.String(); got != value {
+			t.Errorf("ParseMapSpec(%q) round trip = %q", value, got)
+		}
+	}
+
+	for _, value := range []string{"", "a:1", "0:a:1:v", "0:x", "0:p", "0:a:1:2", "0:#", "0:#zz", "0:#0x101:a", "0:g"} {
+		if _, err := ParseMapSpec(value); err == nil {
+			t.Errorf("expected error for %q, got nil", value)
+		}
+	}
+
+	// the i: and g:i: forms render in their # shorthand
+	for value, want := range map[string]string{"0:i:257": "0:#257", "0:g:i:5": "0:g:#5"} {
+		if spec, err := ParseMapSpec(value); err != nil || spec.String() != want {
+			t.Errorf("ParseMapSpec(%q) = %q, %v, want %q", value, spec.String(), err, want)
+		}
+		if err := MapFlag(value).Validate(); err != nil {
+			t.Errorf("MapFlag(%q).Validate() error: %v", value, err)
+		}
+	}
+
+	spec, err := ParseStreamSpec("a:1")
+	if err != nil || spec.Type != MediaAudio || spec.Index != 1 || spec.IsMap() {
+		t.Fatalf("ParseStreamSpec(a:1) = %+v, %v", spec, err)
+	}
+}
+
+func TestPerStreamFlags(t *testing.T) {
+	args, err := NewOutputBuilder().
+		WithFlag(WithMapSpec(InputStreams(0).OfType(MediaVideo).Nth(0))).
+		WithFlag(WithMapSpec(InputStreams(0).OfType(MediaAudio).WithTag("language", "eng"))).
+		WithFlag(WithMap("0:a:m:language:fra?")).
+		WithFlag(WithMap("[cv]")).
+		WithFlag(WithStreamCodec(VideoStream(0), "libx264")).
+		WithFlag(WithStreamBitrate(VideoStream(0), "3M")).
+		WithFlag(WithStreamCodec(AudioStream(0), "aac")).
+		WithFlag(WithStreamBitrate(AudioStream(0), "128k")).
+		WithFlag(WithStreamCodec(AudioStream(1), "libopus")).
+		WithFlag(WithStreamBitrate(AudioStream(1), "96k")).
+		WithFlag(WithStreamMetadata(AudioStream(1), "language", "fra")).
+		File("out.mkv").
+		Build().
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	got := strings.Join(args, " ")
+	want := "-map 0:v:0 -map 0:a:m:language:eng -map 0:a:m:language:fra? -map [cv] " +
+		"-c:v:0 libx264 -b:v:0 3M -c:a:0 aac -b:a:0 128k -c:a:1 libopus -b:a:1 96k " +
+		"-metadata:s:a:1 language=fra out.mkv"
+	if got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+
+	invalid := []OutputFlagParser{
+		MapFlag("0:x"),
+		MapFlag("[]"),
+		MapFlag("v720"),
+		StreamCodecFlag{Stream: InputStreams(0).OfType(MediaAudio), Codec: "aac"},
+		StreamBitrateFlag{Stream: AudioStream(0)},
+		MetadataFlag{Specifier: "s:q:0", Key: "language", Value: "eng"},
+	}
+	for _, flag := range invalid {
+		if err := flag.Validate(); err == nil {
+			t.Errorf("expected error for %+v, got nil", flag)
+		}
+	}
+}
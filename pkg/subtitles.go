@@ -0,0 +1,295 @@
+package ffmpego
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// SubtitleStyle overrides ASS style fields when burning subtitles in (force_style).
+// Zero values are left to the subtitle file. Colours use the ASS "&HAABBGGRR" syntax.
+type SubtitleStyle struct {
+	FontName      string
+	FontSize      int
+	PrimaryColour string
+	OutlineColour string
+	BackColour    string
+	Bold          bool
+	Italic        bool
+	BorderStyle   int // 1 = outline and shadow, 3 = opaque box
+	Outline       int
+	Shadow        int
+	Alignment     int // numpad layout, 2 = bottom center
+	MarginV       int
+}
+
+// String renders the style as a force_style value: "FontName=Arial,FontSize=24".
+func (s SubtitleStyle) String() string {
+	var fields []string
+	add := func(key string, value interface{}) {
+		fields = append(fields, fmt.Sprintf("%s=%v", key, value))
+	}
+	if s.FontName != "" {
+		add("FontName", s.FontName)
+	}
+	if s.FontSize > 0 {
+		add("FontSize", s.FontSize)
+	}
+	if s.PrimaryColour != "" {
+		add("PrimaryColour", s.PrimaryColour)
+	}
+	if s.OutlineColour != "" {
+		add("OutlineColour", s.OutlineColour)
+	}
+	if s.BackColour != "" {
+		add("BackColour", s.BackColour)
+	}
+	if s.Bold {
+		add("Bold", 1)
+	}
+	if s.Italic {
+		add("Italic", 1)
+	}
+	if s.BorderStyle > 0 {
+		add("BorderStyle", s.BorderStyle)
+	}
+	if s.Outline > 0 {
+		add("Outline", s.Outline)
+	}
+	if s.Shadow > 0 {
+		add("Shadow", s.Shadow)
+	}
+	if s.Alignment > 0 {
+		add("Alignment", s.Alignment)
+	}
+	if s.MarginV > 0 {
+		add("MarginV", s.MarginV)
+	}
+	return strings.Join(fields, ",")
+}
+
+// SubtitleFilter renders: "[input]subtitles=filename=path:si=0:force_style=...[output]"
+// or, with ASS set, "[input]ass=filename=path[output]". The path is escaped for the
+// filtergraph, so Windows drive letters and quotes are safe.
+type SubtitleFilter struct {
+	Input       string
+	Output      string
+	Path        string
+	ASS         bool           // use the ass filter, which keeps the file's own styling
+	StreamIndex int            // subtitle stream of Path when it is a container, e.g. an mkv
+	Style       *SubtitleStyle // subtitles filter only
+}
+
+func (f SubtitleFilter) Validate() error {
+	if strings.TrimSpace(f.Input) == "" {
+		return fmt.Errorf("subtitles: input label cannot be empty")
+	}
+	if strings.TrimSpace(f.Output) == "" {
+		return fmt.Errorf("subtitles: output label cannot be empty")
+	}
+	if strings.TrimSpace(f.Path) == "" {
+		return fmt.Errorf("subtitles: path cannot be empty")
+	}
+	if f.StreamIndex < 0 {
+		return fmt.Errorf("subtitles: stream index must be non-negative, got %d", f.StreamIndex)
+	}
+	if f.ASS && (f.Style != nil || f.StreamIndex != 0) {
+		return fmt.Errorf("subtitles: style overrides and stream index require the subtitles filter, not ass")
+	}
+	return nil
+}
+
+func (f SubtitleFilter) Parse() string {
+	if f.ASS {
+		return fmt.Sprintf("[%s]ass=filename=%s[%s]", f.Input, escapeFilterValue(f.Path), f.Output)
+	}
+
+	opts := "filename=" + escapeFilterValue(f.Path)
+	if f.StreamIndex > 0 {
+		opts += fmt.Sprintf(":si=%d", f.StreamIndex)
+	}
+	if f.Style != nil {
+		if style := f.Style.String(); style != "" {
+			opts += ":force_style=" + escapeFilterValue(style)
+		}
+	}
+	return fmt.Sprintf("[%s]subtitles=%s[%s]", f.Input, opts, f.Output)
+}
+
+// WithSubtitles adds a labeled subtitles chain burning path into the video, with optional style overrides.
+func WithSubtitles(input string, output string, path string, style *SubtitleStyle) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(SubtitleFilter{
+			Input:  strings.TrimSpace(input),
+			Output: strings.TrimSpace(output),
+			Path:   path,
+			Style:  style,
+		})
+	}
+}
+
+// WithASS adds a labeled ass chain burning an ASS/SSA file with its own styling.
+func WithASS(input string, output string, path string) FilterFn {
+	return func(fg *FilterGraph) {
+		fg.Add(SubtitleFilter{
+			Input:  strings.TrimSpace(input),
+			Output: strings.TrimSpace(output),
+			Path:   path,
+			ASS:    true,
+		})
+	}
+}
+
+// SubtitleTrack is a subtitle file soft-muxed by MuxSubtitles.
+type SubtitleTrack struct {
+	Path     string
+	Language string // ISO 639-2 code, e.g. "eng"; MP4 only stores three letter codes
+	Title    string
+	Default  bool
+	Forced   bool
+}
+
+// Validate validates the track path and language
+func (t SubtitleTrack) Validate() error {
+	if strings.TrimSpace(t.Path) == "" {
+		return fmt.Errorf("subtitles: track path cannot be empty")
+	}
+	if t.Language != "" {
+		if len(t.Language) != 3 || strings.ToLower(t.Language) != t.Language || strings.Trim(t.Language, "abcdefghijklmnopqrstuvwxyz") != "" {
+			return fmt.Errorf("subtitles: language must be a lowercase ISO 639-2 code, got %q", t.Language)
+		}
+	}
+	return nil
+}
+
+// SubtitleCodecFor returns the subtitle codec storing source (a subtitle file path or
+// extension) in the container of output: mov_text for MP4/MOV, webvtt for WebM and
+// HLS, and the matching text codec for Matroska and subtitle files.
+func SubtitleCodecFor(output, source string) (string, error) {
+	sourceExt := strings.ToLower(filepath.Ext(source))
+	if sourceExt == "" {
+		sourceExt = "." + strings.ToLower(source)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(output)); ext {
+	case ".mp4", ".m4v", ".mov":
+		return "mov_text", nil
+	case ".webm", ".m3u8", ".vtt":
+		return "webvtt", nil
+	case ".srt":
+		return "srt", nil
+	case ".ass", ".ssa":
+		return "ass", nil
+	case ".sup":
+		// bitmap subtitles cannot be converted to text, only copied
+		return "copy", nil
+	case ".mkv", ".mka":
+		switch sourceExt {
+		case ".ass", ".ssa":
+			return "ass", nil
+		case ".vtt":
+			return "webvtt", nil
+		case ".srt":
+			return "srt", nil
+		}
+		// subtitle streams from containers keep their codec
+		return "copy", nil
+	default:
+		return "", fmt.Errorf("subtitles: no known subtitle codec for %q outputs", ext)
+	}
+}
+
+// MuxSubtitles copies the video and audio of input and adds each track as a subtitle
+// stream, with the codec suited to the output container, language/title metadata and
+// default/forced dispositions.
+func MuxSubtitles(input, output string, tracks ...SubtitleTrack) (*Ffmpego, error) {
+	if len(tracks) == 0 {
+		return nil, fmt.Errorf("subtitles: at least one track is required")
+	}
+
+	inputs := []string{input}
+	out := NewOutputBuilder().
+		WithFlag(WithMap("0:v?")).
+		WithFlag(WithMap("0:a?")).
+		WithFlag(WithVideoCodec("copy")).
+		WithFlag(WithAudioCodec("copy"))
+
+	anyDefault := false
+	for _, track := range tracks {
+		anyDefault = anyDefault || track.Default
+	}
+	for i, track := range tracks {
+		if err := track.Validate(); err != nil {
+			return nil, err
+		}
+		codec, err := SubtitleCodecFor(output, track.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		inputs = append(inputs, track.Path)
+		stream := SubtitleStream(i)
+		out.WithFlag(WithMapSpec(InputStreams(i + 1).OfType(MediaSubtitle).Nth(0))).
+			WithFlag(WithStreamCodec(stream, codec))
+		if track.Language != "" {
+			out.WithFlag(WithStreamMetadata(stream, "language", track.Language))
+		}
+		if track.Title != "" {
+			out.WithFlag(WithStreamMetadata(stream, "title", track.Title))
+		}
+
+		var dispositions []string
+		if track.Default {
+			dispositions = append(dispositions, "default")
+		}
+		if track.Forced {
+			dispositions = append(dispositions, "forced")
+		}
+		// muxers may flag the first track as default unless told otherwise
+		if len(dispositions) > 0 || anyDefault {
+			out.WithFlag(WithDisposition(stream, dispositions...))
+		}
+	}
+
+	return New("").
+		WithOptions(NewFfmpegOptions(WithInput(inputs...))).
+		Output(out.File(output).Build()), nil
+}
+
+// ConvertSubtitles converts a subtitle file between SRT, WebVTT and ASS, picking the
+// codec from the output extension.
+func ConvertSubtitles(input, output string) (*Ffmpego, error) {
+	codec, err := SubtitleCodecFor(output, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return New("").
+		WithOptions(NewFfmpegOptions(WithInput(input))).
+		Output(NewOutputBuilder().
+			WithFlag(WithMap("0:s:0")).
+			WithFlag(WithSubtitleCodec(codec)).
+			File(output).
+			Build()), nil
+}
+
+// ExtractSubtitles writes the stream-th subtitle stream of input to output, converting
+// text subtitles to the output format. Bitmap subtitles (PGS, DVD) need a ".sup" or
+// Matroska output, which copies them.
+func ExtractSubtitles(input string, stream int, output string) (*Ffmpego, error) {
+	if stream < 0 {
+		return nil, fmt.Errorf("subtitles: stream index must be non-negative, got %d", stream)
+	}
+	codec, err := SubtitleCodecFor(output, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return New("").
+		WithOptions(NewFfmpegOptions(WithInput(input))).
+		Output(NewOutputBuilder().
+			WithFlag(WithMapSpec(InputStreams(0).OfType(MediaSubtitle).Nth(stream))).
+			WithFlag(WithSubtitleCodec(codec)).
+			File(output).
+			Build()), nil
+}
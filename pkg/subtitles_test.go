@@ -0,0 +1,92 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSubtitleFilter_EscapesPathAndStyle(t *testing.T) {
+	f := SubtitleFilter{
+		Input:  "0:v",
+		Output: "subbed",
+		Path:   `C:\subs\it's [final].srt`,
+		Style:  &SubtitleStyle{FontName: "Arial", FontSize: 24, Outline: 2},
+	}
+	if err := f.Validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+
+	want := `[0:v]subtitles=filename=C\\:\\\\subs\\\\it\\\'s \[final\].srt:force_style=FontName=Arial\,FontSize=24\,Outline=2[subbed]`
+	if got := f.Parse(); got != want {
+		t.Fatalf("parse mismatch:\n got: %s\nwant: %s", got, want)
+	}
+
+	ass := SubtitleFilter{Input: "0:v", Output: "subbed", Path: "karaoke.ass", ASS: true}
+	if got := ass.Parse(); got != "[0:v]ass=filename=karaoke.ass[subbed]" {
+		t.Fatalf("ass parse mismatch: %s", got)
+	}
+	ass.Style = &SubtitleStyle{FontSize: 20}
+	if err := ass.Validate(); err == nil {
+		t.Fatalf("expected error for style overrides with the ass filter, got nil")
+	}
+}
+
+func TestMuxSubtitles(t *testing.T) {
+	cmd, err := MuxSubtitles("movie.mp4", "out.mp4",
+		SubtitleTrack{Path: "en.srt", Language: "eng", Default: true},
+		SubtitleTrack{Path: "fr.vtt", Language: "fra", Title: "Forced", Forced: true})
+	if err != nil {
+		t.Fatalf("MuxSubtitles() error: %v", err)
+	}
+	args, err := cmd.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	got := strings.Join(args, " ")
+	want := "-i movie.mp4 -i en.srt -i fr.vtt -map 0:v? -map 0:a? -c:v copy -c:a copy " +
+		"-map 1:s:0 -c:s:0 mov_text -metadata:s:s:0 language=eng -disposition:s:0 default " +
+		"-map 2:s:0 -c:s:1 mov_text -metadata:s:s:1 language=fra -metadata:s:s:1 title=Forced -disposition:s:1 forced out.mp4"
+	if got != want {
+		t.Fatalf("args mismatch:\n got: %s\nwant: %s", got, want)
+	}
+
+	if _, err := MuxSubtitles("movie.mp4", "out.mp4", SubtitleTrack{Path: "en.srt", Language: "en"}); err == nil {
+		t.Fatalf("expected error for two letter language code, got nil")
+	}
+}
+
+func TestSubtitleCodecFor(t *testing.T) {
+	cases := []struct{ output, source, want string }{
+		{"out.mp4", "in.ass", "mov_text"},
+		{"out.mkv", "in.ass", "ass"},
+		{"out.mkv", "in.srt", "srt"},
+		{"out.mkv", "in.mkv", "copy"},
+		{"index.m3u8", "in.srt", "webvtt"},
+		{"out.vtt", "in.srt", "webvtt"},
+		{"out.srt", "in.mkv", "srt"},
+	}
+	for _, c := range cases {
+		got, err := SubtitleCodecFor(c.output, c.source)
+		if err != nil || got != c.want {
+			t.Fatalf("SubtitleCodecFor(%q, %q) = %q, %v; want %q", c.output, c.source, got, err, c.want)
+		}
+	}
+	if _, err := SubtitleCodecFor("out.avi", "in.srt"); err == nil {
+		t.Fatalf("expected error for unsupported container, got nil")
+	}
+}
+
+func TestExtractSubtitles(t *testing.T) {
+	cmd, err := ExtractSubtitles("movie.mkv", 1, "track.srt")
+	if err != nil {
+		t.Fatalf("ExtractSubtitles() error: %v", err)
+	}
+	args, err := cmd.Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if got := strings.Join(args, " "); got != "-i movie.mkv -map 0:s:1 -c:s srt track.srt" {
+		t.Fatalf("args mismatch: %s", got)
+	}
+}
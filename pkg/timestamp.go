@@ -0,0 +1,63 @@
+package ffmpego
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatTimestamp renders a duration in ffmpeg / WebVTT time syntax: "HH:MM:SS.mmm".
+// Negative durations are prefixed with "-".
+func FormatTimestamp(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	d = d.Round(time.Millisecond)
+
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	d -= minutes * time.Minute
+	seconds := d / time.Second
+	d -= seconds * time.Second
+
+	return fmt.Sprintf("%s%02d:%02d:%02d.%03d", sign, hours, minutes, seconds, d/time.Millisecond)
+}
+
+// ParseTimestamp parses ffmpeg time syntax: "[-][HH:]MM:SS[.m...]" or plain seconds ("12.5").
+func ParseTimestamp(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	sign := time.Duration(1)
+	if strings.HasPrefix(value, "-") {
+		sign, value = -1, value[1:]
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 || value == "" {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+
+	var total float64
+	for _, part := range parts {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q", value)
+		}
+		total = total*60 + v
+	}
+
+	return sign * time.Duration(total*float64(time.Second)).Round(time.Microsecond), nil
+}
+
+// formatSeconds renders a duration as decimal seconds, e.g. 4s -> "4", 1.5s -> "1.5".
+func formatSeconds(d time.Duration) string {
+	return formatFloat(d.Seconds())
+}
+
+// formatFloat renders a float without trailing zeros, e.g. 0.4 -> "0.4", 2 -> "2".
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,107 @@
+package ffmpego
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// StandardFrameRates are the rates ConstantFrameRate snaps to, in ffmpeg rational syntax.
+var StandardFrameRates = []string{"24000/1001", "24", "25", "30000/1001", "30", "48", "50", "60000/1001", "60", "100", "120000/1001", "120"}
+
+// VsyncFlag represents the legacy -vsync option, replaced by -fps_mode in ffmpeg 5.1.
+// It is a global option, so it applies to every output of the command.
+type VsyncFlag string
+
+// Parse returns the vsync flag arguments
+func (f VsyncFlag) Parse() []string {
+	return []string{"-vsync", string(f)}
+}
+
+// Validate validates the vsync flag
+func (f VsyncFlag) Validate() error {
+	switch f {
+	case "passthrough", "cfr", "vfr", "drop", "auto":
+		return nil
+	}
+	return fmt.Errorf("vsync must be one of passthrough, cfr, vfr, drop or auto, got %q", string(f))
+}
+
+// WithVideoSync creates a video sync method flag for the ffmpeg version: -fps_mode from
+// 5.1 on, -vsync before. Use DetectVersion (or DetectCapabilities) to find it.
+func WithVideoSync(mode string, version FFmpegVersion) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		if version.AtLeast(5, 1) {
+			options.Add(FPSModeFlag(mode))
+		} else {
+			options.Add(VsyncFlag(mode))
+		}
+	}
+}
+
+// MuxDelayFlag represents the -muxdelay option, the maximum demux-decode delay
+type MuxDelayFlag time.Duration
+
+// Parse returns the mux delay flag arguments in seconds
+func (f MuxDelayFlag) Parse() []string {
+	return []string{"-muxdelay", formatSeconds(time.Duration(f))}
+}
+
+// Validate validates the mux delay flag
+func (f MuxDelayFlag) Validate() error {
+	if f < 0 {
+		return fmt.Errorf("muxdelay must be non-negative, got %s", time.Duration(f))
+	}
+	return nil
+}
+
+// WithMuxDelay creates a new -muxdelay flag, e.g. 0 to stop MPEG-TS outputs starting at 1.4s
+func WithMuxDelay(delay time.Duration) OutputFlagFn {
+	return func(options *OutputDescriptor) {
+		options.Add(MuxDelayFlag(delay))
+	}
+}
+
+// IsVariableFrameRate reports whether a probed video stream is VFR: its average frame
+// rate differs from the container's base rate (r_frame_rate) by more than 1%.
+func IsVariableFrameRate(stream ProbeStream) bool {
+	avg, err := ParseFrameRate(stream.AvgFrameRate)
+	if err != nil || avg <= 0 {
+		return false
+	}
+	base, err := ParseFrameRate(stream.RFrameRate)
+	if err != nil || base <= 0 {
+		return false
+	}
+	return math.Abs(avg-base)/base > 0.01
+}
+
+// NearestStandardFrameRate returns the entry of StandardFrameRates closest to fps.
+func NearestStandardFrameRate(fps float64) string {
+	best, bestDiff := StandardFrameRates[0], math.Inf(1)
+	for _, rate := range StandardFrameRates {
+		value, _ := ParseFrameRate(rate)
+		if diff := math.Abs(value - fps); diff < bestDiff {
+			best, bestDiff = rate, diff
+		}
+	}
+	return best
+}
+
+// ConstantFrameRate converts a (typically VFR phone) video stream to CFR at the standard
+// rate nearest its average frame rate: "-r rate" with -fps_mode cfr (-vsync cfr before
+// ffmpeg 5.1), so frames are duplicated or dropped instead of drifting against audio.
+// It returns the chosen rate alongside the flags.
+func ConstantFrameRate(stream ProbeStream, version FFmpegVersion) (string, OutputFlagFn, error) {
+	fps := stream.FrameRate()
+	if fps <= 0 {
+		return "", nil, fmt.Errorf("stream %d has no usable avg_frame_rate or r_frame_rate", stream.Index)
+	}
+
+	rate := NearestStandardFrameRate(fps)
+	sync := WithVideoSync("cfr", version)
+	return rate, func(options *OutputDescriptor) {
+		options.Add(FrameRateFlag(rate))
+		sync(options)
+	}, nil
+}
@@ -0,0 +1,97 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConstantFrameRate(t *testing.T) {
+	phone := ProbeStream{Index: 0, CodecType: "video", RFrameRate: "90000/1", AvgFrameRate: "2987000/100000"}
+	if !IsVariableFrameRate(phone) {
+		t.Fatalf("expected phone recording to be VFR")
+	}
+	if IsVariableFrameRate(ProbeStream{RFrameRate: "30000/1001", AvgFrameRate: "30000/1001"}) {
+		t.Fatalf("expected CFR stream not to be VFR")
+	}
+
+	tests := []struct {
+		version FFmpegVersion
+		want    string
+	}{
+		{FFmpegVersion{Major: 6, Minor: 1, Raw: "6.1"}, "-r 30000/1001 -fps_mode cfr out.mp4"},
+		{FFmpegVersion{Major: 4, Minor: 4, Raw: "4.4"}, "-r 30000/1001 -vsync cfr out.mp4"},
+	}
+	for _, tt := range tests {
+		rate, flag, err := ConstantFrameRate(phone, tt.version)
+		if err != nil {
+			t.Fatalf("ConstantFrameRate() error: %v", err)
+		}
+		if rate != "30000/1001" {
+			t.Fatalf("rate = %q, want 30000/1001", rate)
+		}
+		args, err := NewOutputBuilder().WithFlag(flag).File("out.mp4").Build().Build()
+		if err != nil {
+			t.Fatalf("Build() error: %v", err)
+		}
+		if got := strings.Join(args, " "); got != tt.want {
+			t.Errorf("version %s: got %q, want %q", tt.version, got, tt.want)
+		}
+	}
+
+	if _, _, err := ConstantFrameRate(ProbeStream{AvgFrameRate: "0/0"}, FFmpegVersion{}); err == nil {
+		t.Fatalf("expected error without frame rate, got nil")
+	}
+
+	for fps, want := range map[float64]string{23.9: "24000/1001", 25.2: "25", 59.6: "60000/1001", 118: "120000/1001"} {
+		if got := NearestStandardFrameRate(fps); got != want {
+			t.Errorf("NearestStandardFrameRate(%v) = %q, want %q", fps, got, want)
+		}
+	}
+}
+
+func TestTimestampFlags(t *testing.T) {
+	global, err := NewFfmpegOptions(WithCopyTS(), WithStartAtZero()).BuildAndValidate()
+	if err != nil {
+		t.Fatalf("BuildAndValidate() error: %v", err)
+	}
+	if got := strings.Join(global, " "); got != "-copyts -start_at_zero" {
+		t.Fatalf("global flags = %q", got)
+	}
+
+	args, err := NewOutputBuilder().
+		WithFlag(WithMuxDelay(0)).
+		WithFlag(WithAvoidNegativeTS("make_zero")).
+		File("out.ts").
+		Build().
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if got := strings.Join(args, " "); got != "-muxdelay 0 -avoid_negative_ts make_zero out.ts" {
+		t.Fatalf("output flags = %q", got)
+	}
+
+	if err := MuxDelayFlag(-time.Second).Validate(); err == nil {
+		t.Errorf("expected error for negative muxdelay, got nil")
+	}
+	if err := VsyncFlag("1").Validate(); err == nil {
+		t.Errorf("expected error for numeric vsync, got nil")
+	}
+}
+
+func TestFPSFilter_Round(t *testing.T) {
+	graph, err := NewComplexFilterBuilder().
+		Add(WithFPSRound("0:v", "cfr", "30000/1001", "near")).
+		Build().
+		BuildAndValidate()
+	if err != nil {
+		t.Fatalf("BuildAndValidate() error: %v", err)
+	}
+	if !strings.Contains(graph, "[0:v]fps=fps=30000/1001:round=near[cfr]") {
+		t.Fatalf("unexpected graph %q", graph)
+	}
+	if err := (FPSFilter{Input: "a", Output: "b", Rate: "30", Round: "nearest"}).Validate(); err == nil {
+		t.Fatalf("expected error for unknown rounding, got nil")
+	}
+}
@@ -0,0 +1,198 @@
+package ffmpego
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TrimMode selects the cutting technique used by Trim.
+type TrimMode int
+
+const (
+	// FastCopy seeks the input and stream copies. No re-encode, but the clip starts on
+	// the keyframe at or before start.
+	FastCopy TrimMode = iota
+	// Accurate seeks the input and re-encodes; ffmpeg decodes from the previous keyframe
+	// and drops frames until start.
+	Accurate
+	// FrameExact decodes from the beginning and cuts with the trim/atrim filters.
+	// Slowest, but exact even when input seeking is unreliable.
+	FrameExact
+)
+
+func (m TrimMode) String() string {
+	switch m {
+	case FastCopy:
+		return "fast copy"
+	case Accurate:
+		return "accurate"
+	case FrameExact:
+		return "frame exact"
+	default:
+		return fmt.Sprintf("TrimMode(%d)", int(m))
+	}
+}
+
+// TrimWarning reports a FastCopy cut that starts earlier than requested.
+type TrimWarning struct {
+	Requested time.Duration
+	Keyframe  time.Duration
+}
+
+func (w *TrimWarning) String() string {
+	return fmt.Sprintf("fast copy starts on the keyframe at %s, %s before the requested %s",
+		FormatTimestamp(w.Keyframe), w.Requested-w.Keyframe, FormatTimestamp(w.Requested))
+}
+
+// TrimJob cuts [start, end) out of an input. A zero end keeps everything after start.
+type TrimJob struct {
+	input     string
+	start     time.Duration
+	end       time.Duration
+	mode      TrimMode
+	videoOnly bool
+	opts      []OutputFlagFn
+	overwrite bool
+}
+
+// Trim creates a cut of input from start to end using mode.
+func Trim(input string, start, end time.Duration, mode TrimMode) *TrimJob {
+	return &TrimJob{input: input, start: start, end: end, mode: mode, opts: make([]OutputFlagFn, 0)}
+}
+
+// VideoOnly drops audio; FrameExact requires it for inputs without audio.
+func (t *TrimJob) VideoOnly() *TrimJob {
+	t.videoOnly = true
+	return t
+}
+
+// WithFlag appends an output option, e.g. the codecs used by Accurate and FrameExact.
+func (t *TrimJob) WithFlag(opt OutputFlagFn) *TrimJob {
+	t.opts = append(t.opts, opt)
+	return t
+}
+
+// Overwrite adds '-y' to the command.
+func (t *TrimJob) Overwrite() *TrimJob {
+	t.overwrite = true
+	return t
+}
+
+// Validate validates the cut range and mode
+func (t *TrimJob) Validate() error {
+	if strings.TrimSpace(t.input) == "" {
+		return fmt.Errorf("trim: input cannot be empty")
+	}
+	if t.start < 0 {
+		return fmt.Errorf("trim: start must be non-negative, got %s", t.start)
+	}
+	if t.end != 0 && t.end <= t.start {
+		return fmt.Errorf("trim: end %s must be after start %s", t.end, t.start)
+	}
+	if t.mode < FastCopy || t.mode > FrameExact {
+		return fmt.Errorf("trim: unknown mode %d", int(t.mode))
+	}
+	return nil
+}
+
+// Command builds the cut writing to output.
+//
+//	FastCopy:   -ss S -t D -i in -map 0 -c copy -avoid_negative_ts make_zero out
+//	Accurate:   -ss S -t D -i in -map 0:v? -map 0:a? out
+//	FrameExact: -i in -filter_complex [0:v:0]trim=...,setpts=PTS-STARTPTS[tv];[0:a:0]atrim=...[ta] out
+func (t *TrimJob) Command(output string) (*Ffmpego, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	var flags []FfmpegFlagFn
+	if t.overwrite {
+		flags = append(flags, WithOverwrite())
+	}
+	out := NewOutputBuilder()
+	cmd := New("")
+
+	if t.mode == FrameExact {
+		flags = append(flags, WithInput(t.input))
+		graph := NewComplexFilterBuilder().Add(WithTrim("0:v:0", "tv", t.start, t.end, false))
+		out.WithFlag(WithMap("[tv]"))
+		if !t.videoOnly {
+			graph.Add(WithTrim("0:a:0", "ta", t.start, t.end, true))
+			out.WithFlag(WithMap("[ta]"))
+		}
+		cmd.WithFilterGraph(graph.Build())
+	} else {
+		var inputOpts []InputFlagFn
+		if t.start > 0 {
+			inputOpts = append(inputOpts, WithSeek(t.start))
+		}
+		if t.end > 0 {
+			inputOpts = append(inputOpts, WithInputDuration(t.end-t.start))
+		}
+		flags = append(flags, WithInputFile(t.input, inputOpts...))
+
+		if t.mode == FastCopy {
+			if t.videoOnly {
+				out.WithFlag(WithMap("0:v"))
+			} else {
+				out.WithFlag(WithMap("0"))
+			}
+			out.WithFlag(WithCodec("copy")).
+				WithFlag(WithAvoidNegativeTS("make_zero"))
+		} else {
+			out.WithFlag(WithMap("0:v?"))
+			if !t.videoOnly {
+				out.WithFlag(WithMap("0:a?"))
+			}
+		}
+	}
+
+	for _, opt := range t.opts {
+		out.WithFlag(opt)
+	}
+
+	return cmd.
+		WithOptions(NewFfmpegOptions(flags...)).
+		Output(out.File(output).Build()), nil
+}
+
+// Check probes the keyframe a FastCopy cut starts on and returns a warning when it
+// is earlier than start. Other modes never warn.
+func (t *TrimJob) Check(ctx context.Context) (*TrimWarning, error) {
+	if t.mode != FastCopy || t.start == 0 {
+		return nil, nil
+	}
+
+	keyframes, err := NewProber("").Keyframes(ctx, t.input, t.start, t.start+time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return keyframeWarning(t.start, keyframes), nil
+}
+
+// Run checks the cut, then writes it to output. The warning is returned alongside a
+// successful cut so callers can decide whether a keyframe aligned start is acceptable.
+func (t *TrimJob) Run(ctx context.Context, output string) (*TrimWarning, error) {
+	cmd, err := t.Command(output)
+	if err != nil {
+		return nil, err
+	}
+	warning, err := t.Check(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return warning, NewRunner(cmd).Run(ctx)
+}
+
+// keyframeWarning returns a warning when the first keyframe, where a fast seek to start
+// lands, precedes start by more than a millisecond of timestamp rounding.
+func keyframeWarning(start time.Duration, keyframes []time.Duration) *TrimWarning {
+	if len(keyframes) == 0 || start-keyframes[0] <= time.Millisecond {
+		return nil
+	}
+	return &TrimWarning{Requested: start, Keyframe: keyframes[0]}
+}
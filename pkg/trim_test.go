@@ -0,0 +1,75 @@
+package ffmpego
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrimCommand_Modes(t *testing.T) {
+	start, end := 90*time.Second+500*time.Millisecond, 95*time.Second
+	cases := []struct {
+		job  *TrimJob
+		want string
+	}{
+		{
+			job:  Trim("in.mp4", start, end, FastCopy),
+			want: "-ss 00:01:30.500 -t 00:00:04.500 -i in.mp4 -map 0 -c copy -avoid_negative_ts make_zero out.mp4",
+		},
+		{
+			job:  Trim("in.mp4", start, 0, Accurate).WithFlag(VideoCodecH264),
+			want: "-ss 00:01:30.500 -i in.mp4 -map 0:v? -map 0:a? -c:v libx264 out.mp4",
+		},
+		{
+			job: Trim("in.mp4", start, end, FrameExact),
+			want: "-i in.mp4 -filter_complex [0:v:0]trim=start=90.5:end=95,setpts=PTS-STARTPTS[tv];" +
+				"[0:a:0]atrim=start=90.5:end=95,asetpts=PTS-STARTPTS[ta] -map [tv] -map [ta] out.mp4",
+		},
+		{
+			job:  Trim("in.mp4", start, end, FrameExact).VideoOnly(),
+			want: "-i in.mp4 -filter_complex [0:v:0]trim=start=90.5:end=95,setpts=PTS-STARTPTS[tv] -map [tv] out.mp4",
+		},
+	}
+
+	for _, c := range cases {
+		cmd, err := c.job.Command("out.mp4")
+		if err != nil {
+			t.Fatalf("%s: Command() error: %v", c.job.mode, err)
+		}
+		args, err := cmd.Build()
+		if err != nil {
+			t.Fatalf("%s: Build() error: %v", c.job.mode, err)
+		}
+		if got := strings.Join(args, " "); got != c.want {
+			t.Fatalf("%s: args mismatch:\n got: %s\nwant: %s", c.job.mode, got, c.want)
+		}
+	}
+}
+
+func TestTrimCommand_InvalidRange(t *testing.T) {
+	if _, err := Trim("in.mp4", 10*time.Second, 5*time.Second, Accurate).Command("out.mp4"); err == nil {
+		t.Fatalf("expected error for end before start, got nil")
+	}
+}
+
+func TestKeyframeWarning(t *testing.T) {
+	keyframes, err := ParseKeyframes([]byte(`{"packets": [
+		{"pts_time": "88.000000", "flags": "K__"},
+		{"pts_time": "88.040000", "flags": "___"},
+		{"pts_time": "92.000000", "flags": "K__"}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParseKeyframes() error: %v", err)
+	}
+	if len(keyframes) != 2 || keyframes[1] != 92*time.Second {
+		t.Fatalf("keyframes mismatch: %v", keyframes)
+	}
+
+	warning := keyframeWarning(90*time.Second, keyframes)
+	if warning == nil || warning.Keyframe != 88*time.Second {
+		t.Fatalf("expected a warning for the keyframe at 88s, got %+v", warning)
+	}
+	if keyframeWarning(88*time.Second, keyframes) != nil {
+		t.Fatalf("expected no warning when start is on a keyframe")
+	}
+}